@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+
+	"golang-clean-architecture/internal/config"
+	"golang-clean-architecture/internal/delivery/cli"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/repository"
+	"golang-clean-architecture/internal/usecase"
+
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	configFile := flag.String("config", "", "path to a config file (json, yaml or toml)")
+	jsonOutput := flag.Bool("json", false, "print results as JSON")
+	flag.Usage = func() { fmt.Fprintln(os.Stderr, cli.AdminUsage) }
+	flag.Parse()
+
+	viperConfig := config.NewViper(*configFile)
+	log := config.NewLogger(viperConfig)
+	// Keep stderr quiet unless something goes wrong; results go to stdout
+	log.SetLevel(logrus.WarnLevel)
+
+	db := config.NewDatabase(viperConfig, log)
+	validate := config.NewValidator(viperConfig)
+
+	apiClientRepository := repository.NewApiClientRepository(log)
+	accountRepository := repository.NewAccountRepository(log)
+	merchantRepository := repository.NewMerchantRepository(log)
+	transactionRepository := repository.NewTransactionRepository(log)
+	reconciliationRepository := repository.NewReconciliationRepository(log)
+	deviceRepository := repository.NewDeviceRepository(log)
+	auditEventRepository := repository.NewAuditEventRepository(log)
+
+	auditUseCase := usecase.NewAuditUseCase(db, log, validate, auditEventRepository, viperConfig.GetInt("audit.chains"))
+	adminUseCase := usecase.NewAdminUseCase(db, log, validate, apiClientRepository, accountRepository, merchantRepository, deviceRepository, auditUseCase)
+	transactionUseCase := usecase.NewTransactionUseCase(db, log, validate, transactionRepository, accountRepository, nil, auditUseCase)
+	reconciliationUseCase := usecase.NewReconciliationUseCase(
+		db,
+		log,
+		validate,
+		transactionRepository,
+		reconciliationRepository,
+		config.NewSettlementParser(viperConfig),
+		config.NewLocation(viperConfig, log),
+		viperConfig.GetInt("reconciliation.batch_size"),
+	)
+
+	command := cli.NewAdminCommand(adminUseCase, transactionUseCase, reconciliationUseCase, auditUseCase, os.Stdin, os.Stdout, *jsonOutput)
+	// Changes made from the command line are audited under the operator's login
+	ctx := model.WithRequestContext(context.Background(), &model.RequestContext{Actor: operator()})
+	err := command.Run(ctx, flag.Args())
+
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	if errors.Is(err, cli.ErrUsage) {
+		fmt.Fprintln(os.Stderr, cli.AdminUsage)
+		os.Exit(2)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// operator names the person running the command, e.g. "cli:alice"
+func operator() string {
+	if current, err := user.Current(); err == nil {
+		return "cli:" + current.Username
+	}
+	return "cli:" + os.Getenv("USER")
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"golang-clean-architecture/internal/gateway/switching"
+)
+
+// fakeswitch runs the in-memory switch used for local off-us testing, over HTTP on --addr and
+// ISO 8583 over TCP on --iso-addr. Payments ending in .91 are declined and payments ending in
+// .92 are answered after --delay, past the client timeout.
+func main() {
+	addr := flag.String("addr", ":9090", "HTTP listen address")
+	isoAddr := flag.String("iso-addr", ":9091", "ISO 8583 TCP listen address, empty to disable")
+	delay := flag.Duration("delay", 10*time.Second, "how long payments ending in .92 take to answer")
+	flag.Parse()
+
+	fakeSwitch := switching.NewFakeSwitch(*delay)
+
+	if *isoAddr != "" {
+		listener, err := net.Listen("tcp", *isoAddr)
+		if err != nil {
+			log.Fatalf("Failed to start fake ISO 8583 switch: %v", err)
+		}
+		log.Printf("Fake ISO 8583 switch listening on %s", *isoAddr)
+		go func() {
+			if err := fakeSwitch.ServeISO(listener); err != nil {
+				log.Fatalf("Fake ISO 8583 switch stopped: %v", err)
+			}
+		}()
+	}
+
+	log.Printf("Fake switch listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, fakeSwitch.Handler()); err != nil {
+		log.Fatalf("Failed to start fake switch: %v", err)
+	}
+}
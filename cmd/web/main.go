@@ -1,7 +1,14 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"golang-clean-architecture/internal/config"
 )
 
@@ -11,14 +18,28 @@ import (
 // @host localhost:3000
 // @BasePath /
 func main() {
-	viperConfig := config.NewViper()
+	configFile := flag.String("config", "", "path to a config file (json, yaml or toml)")
+	flag.Parse()
+
+	viperConfig := config.NewViper(*configFile)
 	log := config.NewLogger(viperConfig)
+
+	// migrate up | down [steps] | status | force <version>
+	if flag.Arg(0) == "migrate" {
+		if err := config.RunMigrateCommand(viperConfig, log, flag.Args()[1:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+	config.PrepareSchema(viperConfig, log)
+
+	tracerProvider := config.NewTracerProvider(viperConfig, log)
 	db := config.NewDatabase(viperConfig, log)
 	validate := config.NewValidator(viperConfig)
 	app := config.NewFiber(viperConfig)
 	redisClient := config.NewRedis(viperConfig, log)
 
-	config.Bootstrap(&config.BootstrapConfig{
+	lifecycle := config.Bootstrap(&config.BootstrapConfig{
 		DB:          db,
 		App:         app,
 		Log:         log,
@@ -27,9 +48,36 @@ func main() {
 		RedisClient: redisClient,
 	})
 
-	webPort := viperConfig.GetInt("web.port")
-	err := app.Listen(fmt.Sprintf(":%d", webPort))
-	if err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	go func() {
+		webPort := viperConfig.GetInt("web.port")
+		if err := app.Listen(fmt.Sprintf(":%d", webPort)); err != nil {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-quit
+	log.Infof("Received %s, shutting down", sig)
+
+	// Drain in-flight requests and stop the workers before closing what they use
+	delay := time.Duration(viperConfig.GetInt("web.shutdown.delay")) * time.Second
+	timeout := time.Duration(viperConfig.GetInt("web.shutdown.timeout")) * time.Second
+	if err := lifecycle.Shutdown(app, delay, timeout); err != nil {
+		log.Warnf("Server did not drain within %s: %v", timeout, err)
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			log.Warnf("Failed to close database: %v", err)
+		}
 	}
+	if err := redisClient.Close(); err != nil {
+		log.Warnf("Failed to close redis: %v", err)
+	}
+	if err := tracerProvider.Shutdown(context.Background()); err != nil {
+		log.Warnf("Failed to flush traces: %v", err)
+	}
+
+	log.Info("Server stopped")
 }
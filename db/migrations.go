@@ -0,0 +1,8 @@
+package db
+
+import "embed"
+
+// Migrations holds the SQL migrations so the binary can apply them without the source tree
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_transactions_status_created_at;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS debited_at,
+    DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+    ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    ADD COLUMN debited_at TIMESTAMP NULL;
+
+CREATE INDEX idx_transactions_status_created_at ON transactions(status, created_at);
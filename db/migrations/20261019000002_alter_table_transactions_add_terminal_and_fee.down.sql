@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_transactions_merchant_id_created_at;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS fee,
+    DROP COLUMN IF EXISTS terminal_id;
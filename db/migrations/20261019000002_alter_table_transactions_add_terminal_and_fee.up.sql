@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+    ADD COLUMN terminal_id VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN fee DECIMAL(18,2) NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_transactions_merchant_id_created_at ON transactions(merchant_id, created_at);
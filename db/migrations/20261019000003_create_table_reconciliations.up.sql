@@ -0,0 +1,29 @@
+CREATE TABLE reconciliation_runs (
+    run_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    settlement_date DATE NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    matched INTEGER NOT NULL DEFAULT 0,
+    amount_mismatch INTEGER NOT NULL DEFAULT 0,
+    missing_on_our_side INTEGER NOT NULL DEFAULT 0,
+    duplicate INTEGER NOT NULL DEFAULT 0,
+    unmatched INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_reconciliation_runs_settlement_date ON reconciliation_runs(settlement_date);
+
+CREATE TABLE reconciliation_items (
+    item_id BIGSERIAL PRIMARY KEY,
+    run_id UUID NOT NULL REFERENCES reconciliation_runs(run_id) ON DELETE CASCADE,
+    line_number INTEGER NOT NULL DEFAULT 0,
+    reference VARCHAR(100) NOT NULL,
+    transaction_id UUID NULL REFERENCES transactions(transaction_id),
+    settlement_amount DECIMAL(18,2) NULL,
+    our_amount DECIMAL(18,2) NULL,
+    our_status VARCHAR(20) NULL,
+    result VARCHAR(30) NOT NULL
+);
+
+CREATE INDEX idx_reconciliation_items_run_id_result ON reconciliation_items(run_id, result);
+CREATE INDEX idx_reconciliation_items_run_id_transaction_id ON reconciliation_items(run_id, transaction_id);
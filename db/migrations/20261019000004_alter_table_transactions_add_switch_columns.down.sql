@@ -0,0 +1,4 @@
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS switch_reference,
+    DROP COLUMN IF EXISTS acquirer_id,
+    ADD CONSTRAINT transactions_merchant_id_fkey FOREIGN KEY (merchant_id) REFERENCES merchants(merchant_id) NOT VALID;
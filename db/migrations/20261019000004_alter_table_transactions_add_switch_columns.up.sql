@@ -0,0 +1,5 @@
+-- Off-us payments reference merchants of other acquirers, which are not in our merchants table
+ALTER TABLE transactions
+    DROP CONSTRAINT IF EXISTS transactions_merchant_id_fkey,
+    ADD COLUMN acquirer_id VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN switch_reference VARCHAR(100) NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS fx_rate,
+    DROP COLUMN IF EXISTS original_currency,
+    DROP COLUMN IF EXISTS original_amount,
+    DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,6 @@
+-- amount is debited in the account currency; cross-border payments keep the merchant amount and the locked rate
+ALTER TABLE transactions
+    ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    ADD COLUMN original_amount DECIMAL(18,2) NULL,
+    ADD COLUMN original_currency VARCHAR(3) NULL,
+    ADD COLUMN fx_rate DECIMAL(20,8) NULL;
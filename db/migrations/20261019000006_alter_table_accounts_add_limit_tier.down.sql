@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_transactions_account_id_created_at;
+
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS limit_tier;
@@ -0,0 +1,5 @@
+-- An empty tier means the configured limits.default_tier
+ALTER TABLE accounts
+    ADD COLUMN limit_tier VARCHAR(20) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_transactions_account_id_created_at ON transactions(account_id, created_at);
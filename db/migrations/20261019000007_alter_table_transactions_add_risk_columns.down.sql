@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_transactions_account_id_device_id;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS risk_rules,
+    DROP COLUMN IF EXISTS risk_score,
+    DROP COLUMN IF EXISTS risk_action,
+    DROP COLUMN IF EXISTS device_id;
@@ -0,0 +1,8 @@
+-- risk_* hold the screening decision, NULL for payments that were not screened; rules is a comma-separated list of rule names
+ALTER TABLE transactions
+    ADD COLUMN device_id VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN risk_action VARCHAR(10) NULL,
+    ADD COLUMN risk_score INT NULL,
+    ADD COLUMN risk_rules VARCHAR(255) NULL;
+
+CREATE INDEX idx_transactions_account_id_device_id ON transactions(account_id, device_id);
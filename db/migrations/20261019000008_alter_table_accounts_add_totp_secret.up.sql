@@ -0,0 +1,3 @@
+-- An empty secret means step-up challenges are answered with an OTP sent to the account holder
+ALTER TABLE accounts
+    ADD COLUMN totp_secret VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+-- Customer devices bound to an account; public_key is the base64 Ed25519 key payment requests are signed with
+CREATE TABLE devices (
+    device_id VARCHAR(64) NOT NULL,
+    account_id VARCHAR(100) NOT NULL REFERENCES accounts(account_id),
+    public_key VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP NULL,
+    PRIMARY KEY (account_id, device_id)
+);
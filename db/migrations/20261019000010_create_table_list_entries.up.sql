@@ -0,0 +1,18 @@
+-- Blacklist and whitelist entries; removed entries are kept for auditing
+CREATE TABLE list_entries (
+    entry_id BIGSERIAL PRIMARY KEY,
+    list VARCHAR(20) NOT NULL,
+    entry_type VARCHAR(20) NOT NULL,
+    value VARCHAR(100) NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NULL,
+    created_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_by VARCHAR(100) NULL,
+    updated_at TIMESTAMP NULL,
+    removed_by VARCHAR(100) NULL,
+    removed_at TIMESTAMP NULL
+);
+
+-- At most one live entry per value and list
+CREATE UNIQUE INDEX idx_list_entries_live ON list_entries(list, entry_type, value) WHERE removed_at IS NULL;
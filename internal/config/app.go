@@ -1,14 +1,22 @@
 package config
 
 import (
+	"context"
+	"time"
+
 	"golang-clean-architecture/internal/delivery/http"
 	"golang-clean-architecture/internal/delivery/http/middleware"
 	"golang-clean-architecture/internal/delivery/http/route"
+	"golang-clean-architecture/internal/delivery/worker"
+	"golang-clean-architecture/internal/gateway/switching"
+	"golang-clean-architecture/internal/metrics"
 	"golang-clean-architecture/internal/repository"
 	"golang-clean-architecture/internal/usecase"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/contrib/otelfiber/v2"
 	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -24,14 +32,106 @@ type BootstrapConfig struct {
 	RedisClient *redis.Client
 }
 
-func Bootstrap(config *BootstrapConfig) {
+func Bootstrap(config *BootstrapConfig) *Lifecycle {
+	lifecycle := NewLifecycle()
+
+	// setup metrics
+	appMetrics := metrics.New()
+	if sqlDB, err := config.DB.DB(); err == nil {
+		appMetrics.RegisterDatabase(sqlDB)
+	}
+	appMetrics.RegisterRedis(config.RedisClient)
+
 	// setup repositories
 	apiClientRepository := repository.NewApiClientRepository(config.Log)
 	merchantRepository := repository.NewMerchantRepository(config.Log)
 	accountRepository := repository.NewAccountRepository(config.Log)
 	transactionRepository := repository.NewTransactionRepository(config.Log)
+	migrationRepository := repository.NewMigrationRepository(config.Log)
+	reconciliationRepository := repository.NewReconciliationRepository(config.Log)
+	deviceRepository := repository.NewDeviceRepository(config.Log)
+	listEntryRepository := repository.NewListEntryRepository(config.Log)
+	auditEventRepository := repository.NewAuditEventRepository(config.Log)
+
+	// setup gateways
+	offUs := usecase.OffUsConfig{
+		OnUsAcquirers:   config.Config.GetStringSlice("switching.on_us_acquirers"),
+		ReversalRetries: config.Config.GetInt("switching.reversal_retries"),
+	}
+	if config.Config.GetBool("switching.enabled") {
+		offUs.Client = newSwitchingClient(config, lifecycle)
+	}
 
 	// setup use cases
+	auditUseCase := usecase.NewAuditUseCase(
+		config.DB,
+		config.Log,
+		config.Validate,
+		auditEventRepository,
+		config.Config.GetInt("audit.chains"),
+	)
+	adminUseCase := usecase.NewAdminUseCase(
+		config.DB,
+		config.Log,
+		config.Validate,
+		apiClientRepository,
+		accountRepository,
+		merchantRepository,
+		deviceRepository,
+		auditUseCase,
+	)
+	// Payments only require a device signature with device binding on; devices can always be registered
+	var paymentDevices *usecase.DeviceUseCase
+	if config.Config.GetBool("device_binding.enabled") {
+		paymentDevices = usecase.NewDeviceUseCase(config.DB, config.Log, deviceRepository)
+	}
+	listUseCase := usecase.NewListUseCase(
+		config.DB,
+		config.Log,
+		config.Validate,
+		config.RedisClient,
+		listEntryRepository,
+		time.Duration(config.Config.GetInt("lists.cache_ttl"))*time.Second,
+		auditUseCase,
+		NewLocation(config.Config, config.Log),
+	)
+	// Lists can always be managed; payments are only checked against them with lists on
+	var paymentLists *usecase.ListUseCase
+	if config.Config.GetBool("lists.enabled") {
+		paymentLists = listUseCase
+	}
+	var limitUseCase *usecase.LimitUseCase
+	if config.Config.GetBool("limits.enabled") {
+		limitUseCase = usecase.NewLimitUseCase(
+			config.DB,
+			config.Log,
+			config.Validate,
+			config.RedisClient,
+			accountRepository,
+			transactionRepository,
+			NewLimitTiers(config.Config),
+			config.Config.GetString("limits.default_tier"),
+			NewLocation(config.Config, config.Log),
+		)
+	}
+	var hotAccountBatcher *usecase.HotAccountBatcher
+	if config.Config.GetBool("payment.hot_account.enabled") {
+		hotAccountBatcher = usecase.NewHotAccountBatcher(
+			config.DB,
+			config.Log,
+			accountRepository,
+			transactionRepository,
+			auditUseCase,
+			config.Config.GetStringSlice("payment.hot_account.accounts"),
+			config.Config.GetInt("payment.hot_account.max_batch_size"),
+			time.Duration(config.Config.GetInt("payment.hot_account.max_wait_ms"))*time.Millisecond,
+		)
+		// Workers stop after the HTTP server drained, so no payment is queued anymore
+		lifecycle.Go(func(ctx context.Context) {
+			<-ctx.Done()
+			hotAccountBatcher.Stop()
+		})
+	}
 	qrisUseCase := usecase.NewQrisUseCase(
 		config.DB,
 		config.Log,
@@ -40,26 +140,171 @@ func Bootstrap(config *BootstrapConfig) {
 		merchantRepository,
 		accountRepository,
 		transactionRepository,
+		usecase.PaymentLockConfig{
+			Strategy:     config.Config.GetString("payment.lock.strategy"),
+			MaxRetries:   config.Config.GetInt("payment.lock.max_retries"),
+			RetryBackoff: time.Duration(config.Config.GetInt("payment.lock.retry_backoff_ms")) * time.Millisecond,
+		},
+		config.Config.GetFloat64("payment.mdr_percent"),
+		hotAccountBatcher,
+		offUs,
+		usecase.FxConfig{
+			Provider:     NewFxProvider(config.Config, config.Log),
+			HomeCurrency: config.Config.GetString("fx.home_currency"),
+			LockTTL:      time.Duration(config.Config.GetInt("fx.lock_ttl")) * time.Second,
+		},
+		limitUseCase,
+		NewRiskConfig(config.Config, config.Log, usecase.NewRiskHistory(config.DB, transactionRepository)),
+		NewStepUpConfig(config.Config, config.Log),
+		paymentDevices,
+		usecase.PinLockoutConfig{
+			MaxAttempts: config.Config.GetInt("pin.max_attempts"),
+			Duration:    time.Duration(config.Config.GetInt("pin.lockout")) * time.Second,
+		},
+		paymentLists,
+		auditUseCase,
+		appMetrics,
 	)
 	transactionUseCase := usecase.NewTransactionUseCase(
 		config.DB,
 		config.Log,
+		config.Validate,
 		transactionRepository,
 		accountRepository,
+		offUs.Client,
+		auditUseCase,
+	)
+
+	reportUseCase := usecase.NewReportUseCase(
+		config.DB,
+		config.Log,
+		config.Validate,
+		merchantRepository,
+		transactionRepository,
+		NewLocation(config.Config, config.Log),
+		config.Config.GetInt("report.max_range_days"),
+	)
+
+	reconciliationUseCase := usecase.NewReconciliationUseCase(
+		config.DB,
+		config.Log,
+		config.Validate,
+		transactionRepository,
+		reconciliationRepository,
+		NewSettlementParser(config.Config),
+		NewLocation(config.Config, config.Log),
+		config.Config.GetInt("reconciliation.batch_size"),
+	)
+
+	healthUseCase := usecase.NewHealthUseCase(
+		config.DB,
+		config.Log,
+		config.RedisClient,
+		migrationRepository,
+		LatestMigrationVersion(),
+		time.Duration(config.Config.GetInt("health.timeout_ms"))*time.Millisecond,
 	)
 
 	// setup controllers
 	qrisController := http.NewQrisController(qrisUseCase, config.Log)
 	transactionController := http.NewTransactionController(transactionUseCase, config.Log)
+	reportController := http.NewReportController(reportUseCase, config.Log)
+	reconciliationController := http.NewReconciliationController(reconciliationUseCase, config.Log)
+	limitController := http.NewLimitController(limitUseCase, config.Log)
+	deviceController := http.NewDeviceController(adminUseCase, config.Log)
+	listController := http.NewListController(listUseCase, config.Log)
+	auditController := http.NewAuditController(auditUseCase, config.Log)
+	healthController := http.NewHealthController(healthUseCase, config.Log)
+	metricsController := http.NewMetricsController(newMetricsGatherer(config, lifecycle, appMetrics))
 
 	// setup middleware
 	hmacMiddleware := middleware.NewHMACAuth(config.DB, apiClientRepository, config.Log)
+	adminMiddleware := middleware.NewAdminOnly(config.Config.GetStringSlice("admin.client_ids"), config.Log)
+	requestIDMiddleware := middleware.NewRequestID()
+	tracingMiddleware := otelfiber.Middleware()
+	metricsMiddleware := middleware.NewMetrics(appMetrics)
 
 	routeConfig := route.RouteConfig{
-		App:                   config.App,
-		QrisController:        qrisController,
-		TransactionController: transactionController,
-		HMACMiddleware:        hmacMiddleware,
+		App:                      config.App,
+		QrisController:           qrisController,
+		TransactionController:    transactionController,
+		ReportController:         reportController,
+		ReconciliationController: reconciliationController,
+		LimitController:          limitController,
+		DeviceController:         deviceController,
+		ListController:           listController,
+		AuditController:          auditController,
+		HMACMiddleware:           hmacMiddleware,
+		AdminMiddleware:          adminMiddleware,
+		RequestIDMiddleware:      requestIDMiddleware,
+		TracingMiddleware:        tracingMiddleware,
+		MetricsController:        metricsController,
+		HealthController:         healthController,
+		MetricsMiddleware:        metricsMiddleware,
 	}
 	routeConfig.Setup()
+	lifecycle.HealthUseCase = healthUseCase
+
+	// setup background workers
+	if config.Config.GetBool("worker.sweeper.enabled") {
+		interval := time.Duration(config.Config.GetInt("worker.sweeper.interval")) * time.Second
+		transactionSweeper := worker.NewTransactionSweeper(
+			transactionUseCase,
+			worker.NewLeaderLock(config.RedisClient, "lock:transaction-sweeper", 2*interval),
+			config.Log,
+			interval,
+			time.Duration(config.Config.GetInt("worker.sweeper.pending_ttl"))*time.Second,
+			config.Config.GetInt("worker.sweeper.batch_size"),
+		)
+		lifecycle.Go(transactionSweeper.Start)
+
+		appMetrics.RegisterCounterFunc("sweeper_expired_total", "Stale pending transactions marked EXPIRED.", func() float64 {
+			return float64(transactionSweeper.Stats().Expired)
+		})
+		appMetrics.RegisterCounterFunc("sweeper_failed_total", "Stale pending transactions reversed and marked FAILED.", func() float64 {
+			return float64(transactionSweeper.Stats().Failed)
+		})
+		appMetrics.RegisterCounterFunc("sweeper_errors_total", "Sweeper runs that failed.", func() float64 {
+			return float64(transactionSweeper.Stats().Errors)
+		})
+	}
+
+	return lifecycle
+}
+
+// newSwitchingClient connects to the switch with JSON over HTTP, or keeps a signed-on ISO 8583
+// TCP link for the lifetime of the process
+func newSwitchingClient(config *BootstrapConfig, lifecycle *Lifecycle) switching.Client {
+	timeout := time.Duration(config.Config.GetInt("switching.timeout_ms")) * time.Millisecond
+	if config.Config.GetString("switching.protocol") != "iso8583" {
+		return switching.NewHTTPClient(config.Log, config.Config.GetString("switching.url"), timeout)
+	}
+
+	link := switching.NewLink(config.Log, switching.LinkConfig{
+		Address:      config.Config.GetString("switching.iso8583.address"),
+		Institution:  config.Config.GetString("switching.iso8583.institution_id"),
+		Timeout:      timeout,
+		EchoInterval: time.Duration(config.Config.GetInt("switching.iso8583.echo_interval_ms")) * time.Millisecond,
+		ReconnectMin: time.Duration(config.Config.GetInt("switching.iso8583.reconnect_min_ms")) * time.Millisecond,
+		ReconnectMax: time.Duration(config.Config.GetInt("switching.iso8583.reconnect_max_ms")) * time.Millisecond,
+	})
+	lifecycle.Go(link.Start)
+	return switching.NewISOClient(config.Log, link)
+}
+
+// newMetricsGatherer aggregates metrics across child processes when prefork is on,
+// otherwise the local registry is scraped directly
+func newMetricsGatherer(config *BootstrapConfig, lifecycle *Lifecycle, appMetrics *metrics.Metrics) prometheus.Gatherer {
+	if !config.Config.GetBool("web.prefork") {
+		return appMetrics.Registry
+	}
+
+	gatherer := metrics.NewPreforkGatherer(
+		appMetrics.Registry,
+		config.RedisClient,
+		config.Log,
+		time.Duration(config.Config.GetInt("metrics.prefork_interval"))*time.Second,
+	)
+	lifecycle.Go(gatherer.Start)
+	return gatherer
 }
@@ -1,6 +1,10 @@
 package config
 
 import (
+	"errors"
+
+	"golang-clean-architecture/internal/model"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/spf13/viper"
 )
@@ -15,15 +19,28 @@ func NewFiber(config *viper.Viper) *fiber.App {
 	return app
 }
 
+// NewErrorHandler renders every error in the model.ApiResponse envelope with a stable error
+// code and a message localized from the Accept-Language header
 func NewErrorHandler() fiber.ErrorHandler {
 	return func(ctx *fiber.Ctx, err error) error {
-		code := fiber.StatusInternalServerError
-		if e, ok := err.(*fiber.Error); ok {
-			code = e.Code
+		var appErr *model.Error
+		status := fiber.StatusInternalServerError
+
+		var fiberErr *fiber.Error
+		if errors.As(err, &appErr) {
+			status = appErr.Status()
+		} else if errors.As(err, &fiberErr) {
+			status = fiberErr.Code
+			appErr = model.NewError(model.ErrorCodeForStatus(status))
+		} else {
+			appErr = model.NewError(model.ErrCodeInternal)
 		}
 
-		return ctx.Status(code).JSON(fiber.Map{
-			"errors": err.Error(),
+		language := ctx.AcceptsLanguages(model.LanguageEnglish, model.LanguageIndonesian)
+		return ctx.Status(status).JSON(model.ApiResponse{
+			Status: "error",
+			Code:   string(appErr.Code),
+			Errors: appErr.Message(language),
 		})
 	}
 }
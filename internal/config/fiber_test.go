@@ -0,0 +1,53 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"golang-clean-architecture/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestErrorHandler checks that the code of every error response agrees with its status
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   model.ErrorCode
+	}{
+		{name: "application error", err: model.NewError(model.ErrCodeInvalidPIN), status: 401, code: model.ErrCodeInvalidPIN},
+		{name: "bad request", err: fiber.ErrBadRequest, status: 400, code: model.ErrCodeBadRequest},
+		{name: "unauthorized", err: fiber.ErrUnauthorized, status: 401, code: model.ErrCodeUnauthorized},
+		{name: "not found", err: fiber.ErrNotFound, status: 404, code: model.ErrCodeNotFound},
+		{name: "too many requests", err: fiber.ErrTooManyRequests, status: 429, code: model.ErrCodeTooManyRequests},
+		{name: "service unavailable", err: fiber.ErrServiceUnavailable, status: 503, code: model.ErrCodeServiceUnavailable},
+		{name: "unmapped server error", err: fiber.ErrBadGateway, status: 502, code: model.ErrCodeInternal},
+		{name: "unexpected error", err: errors.New("boom"), status: 500, code: model.ErrCodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: NewErrorHandler()})
+			app.Get("/", func(ctx *fiber.Ctx) error {
+				return tt.err
+			})
+
+			response, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil), -1)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			defer response.Body.Close()
+
+			body := new(model.ApiResponse)
+			if err := json.NewDecoder(response.Body).Decode(body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if response.StatusCode != tt.status || body.Code != string(tt.code) {
+				t.Fatalf("got %d %s, want %d %s", response.StatusCode, body.Code, tt.status, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"golang-clean-architecture/internal/gateway/fx"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// NewFxProvider builds the exchange rate provider configured under fx.provider. Startup fails on
+// a provider it does not know, rather than quoting rates from another source.
+func NewFxProvider(viper *viper.Viper, log *logrus.Logger) fx.Provider {
+	switch provider := viper.GetString("fx.provider"); provider {
+	case "static":
+		static, err := fx.NewStaticProvider(viper.GetString("fx.static_file"))
+		if err != nil {
+			log.Fatalf("failed to load fx rates: %v", err)
+		}
+		return static
+	default:
+		log.Fatalf("unknown fx.provider: %q", provider)
+		return nil
+	}
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -12,31 +15,33 @@ import (
 )
 
 func NewDatabase(viper *viper.Viper, log *logrus.Logger) *gorm.DB {
-	username := viper.GetString("database.username")
-	password := viper.GetString("database.password")
-	host := viper.GetString("database.host")
-	port := viper.GetInt("database.port")
-	database := viper.GetString("database.name")
 	idleConnection := viper.GetInt("database.pool.idle")
 	maxConnection := viper.GetInt("database.pool.max")
 	maxLifeTimeConnection := viper.GetInt("database.pool.lifetime")
 
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=Asia/Jakarta",
-		host, username, password, database, port)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.New(&logrusWriter{Logger: log}, logger.Config{
-			SlowThreshold:             time.Second * 5,
-			Colorful:                  false,
-			IgnoreRecordNotFoundError: true,
-			ParameterizedQueries:      true,
-			LogLevel:                  logger.Info,
-		}),
+	location := NewLocation(viper, log)
+
+	db, err := gorm.Open(postgres.Open(databaseDSN(viper)), &gorm.Config{
+		Logger: &logrusWriter{
+			Logger:        log,
+			SlowThreshold: time.Second * 5,
+			LogLevel:      gormLogLevels[viper.GetString("database.log_level")],
+		},
+		// TIMESTAMP columns hold wall clock time in the session time zone, so timestamps set by
+		// GORM must use the same zone as NOW() in SQL
+		NowFunc: func() time.Time {
+			return time.Now().In(location)
+		},
+		TranslateError: true,
 	})
 	if err != nil {
 		log.Fatalf("failed to connect database: %v", err)
 	}
 
+	if err := registerGormTracing(db); err != nil {
+		log.Fatalf("failed to instrument database: %v", err)
+	}
+
 	connection, err := db.DB()
 	if err != nil {
 		log.Fatalf("failed to connect database: %v", err)
@@ -49,10 +54,91 @@ func NewDatabase(viper *viper.Viper, log *logrus.Logger) *gorm.DB {
 	return db
 }
 
+// gormLogLevels maps database.log_level to GORM's levels; info traces every statement
+var gormLogLevels = map[string]logger.LogLevel{
+	"silent": logger.Silent,
+	"error":  logger.Error,
+	"warn":   logger.Warn,
+	"info":   logger.Info,
+}
+
+func databaseDSN(viper *viper.Viper) string {
+	username := viper.GetString("database.username")
+	password := viper.GetString("database.password")
+	host := viper.GetString("database.host")
+	port := viper.GetInt("database.port")
+	database := viper.GetString("database.name")
+	timezone := viper.GetString("database.timezone")
+
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=%s",
+		host, username, password, database, port, timezone)
+}
+
+// NewLocation loads database.timezone, the zone database timestamps and report days are expressed in
+func NewLocation(viper *viper.Viper, log *logrus.Logger) *time.Location {
+	location, err := time.LoadLocation(viper.GetString("database.timezone"))
+	if err != nil {
+		log.Fatalf("failed to load database timezone: %v", err)
+	}
+	return location
+}
+
+// logrusWriter is a GORM logger that logs through logrus with the query context,
+// so SQL lines carry the request and trace IDs of the request that issued them
 type logrusWriter struct {
-	Logger *logrus.Logger
+	Logger        *logrus.Logger
+	SlowThreshold time.Duration
+	LogLevel      logger.LogLevel
+}
+
+func (l *logrusWriter) LogMode(level logger.LogLevel) logger.Interface {
+	writer := *l
+	writer.LogLevel = level
+	return &writer
+}
+
+func (l *logrusWriter) Info(ctx context.Context, message string, args ...interface{}) {
+	if l.LogLevel >= logger.Info {
+		l.Logger.WithContext(ctx).Infof(message, args...)
+	}
+}
+
+func (l *logrusWriter) Warn(ctx context.Context, message string, args ...interface{}) {
+	if l.LogLevel >= logger.Warn {
+		l.Logger.WithContext(ctx).Warnf(message, args...)
+	}
+}
+
+func (l *logrusWriter) Error(ctx context.Context, message string, args ...interface{}) {
+	if l.LogLevel >= logger.Error {
+		l.Logger.WithContext(ctx).Errorf(message, args...)
+	}
+}
+
+func (l *logrusWriter) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.LogLevel <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	entry := l.Logger.WithContext(ctx).WithFields(logrus.Fields{
+		"elapsed_ms": float64(elapsed.Nanoseconds()) / 1e6,
+		"rows":       rows,
+	})
+
+	switch {
+	case err != nil && l.LogLevel >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		entry.WithError(err).Warn(sql)
+	case elapsed > l.SlowThreshold && l.SlowThreshold != 0 && l.LogLevel >= logger.Warn:
+		entry.Warnf("SLOW SQL >= %v: %s", l.SlowThreshold, sql)
+	case l.LogLevel == logger.Info:
+		entry.Trace(sql)
+	}
 }
 
-func (l *logrusWriter) Printf(message string, args ...interface{}) {
-	l.Logger.Tracef(message, args...)
+// ParamsFilter keeps bind parameters out of logged SQL at every level, including failed and
+// slow statements, so values such as PIN hashes and secrets are never logged
+func (l *logrusWriter) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
 }
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "otel:span"
+
+// gormSpan remembers the statement context from before the span was started so it can be restored
+type gormSpan struct {
+	span   trace.Span
+	parent context.Context
+}
+
+// registerGormTracing opens a client span around every GORM statement. Bind parameters are
+// never recorded, only the parameterized SQL.
+func registerGormTracing(db *gorm.DB) error {
+	tracer := otel.Tracer("gorm.io/gorm")
+
+	before := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			parent := tx.Statement.Context
+			ctx, span := tracer.Start(parent, "gorm."+operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(operation)),
+			)
+			tx.Statement.Context = ctx
+			tx.InstanceSet(gormSpanKey, &gormSpan{span: span, parent: parent})
+		}
+	}
+
+	after := func(tx *gorm.DB) {
+		value, ok := tx.InstanceGet(gormSpanKey)
+		if !ok {
+			return
+		}
+		current := value.(*gormSpan)
+
+		current.span.SetAttributes(
+			semconv.DBCollectionName(tx.Statement.Table),
+			semconv.DBQueryText(tx.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+		)
+		if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			current.span.RecordError(tx.Error)
+			current.span.SetStatus(codes.Error, tx.Error.Error())
+		}
+		current.span.End()
+		tx.Statement.Context = current.parent
+	}
+
+	callback := db.Callback()
+	for _, err := range []error{
+		callback.Create().Before("gorm:create").Register("otel:before_create", before("create")),
+		callback.Create().After("gorm:create").Register("otel:after_create", after),
+		callback.Query().Before("gorm:query").Register("otel:before_query", before("query")),
+		callback.Query().After("gorm:query").Register("otel:after_query", after),
+		callback.Update().Before("gorm:update").Register("otel:before_update", before("update")),
+		callback.Update().After("gorm:update").Register("otel:after_update", after),
+		callback.Delete().Before("gorm:delete").Register("otel:before_delete", before("delete")),
+		callback.Delete().After("gorm:delete").Register("otel:after_delete", after),
+		callback.Row().Before("gorm:row").Register("otel:before_row", before("row")),
+		callback.Row().After("gorm:row").Register("otel:after_row", after),
+		callback.Raw().Before("gorm:raw").Register("otel:before_raw", before("raw")),
+		callback.Raw().After("gorm:raw").Register("otel:after_raw", after),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
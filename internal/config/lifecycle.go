@@ -0,0 +1,56 @@
+package config
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang-clean-architecture/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Lifecycle tracks what Bootstrap started so main can shut the process down in order
+type Lifecycle struct {
+	HealthUseCase *usecase.HealthUseCase
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+}
+
+func NewLifecycle() *Lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Lifecycle{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Go runs a background worker until StopWorkers is called
+func (l *Lifecycle) Go(worker func(ctx context.Context)) {
+	l.workers.Add(1)
+	go func() {
+		defer l.workers.Done()
+		worker(l.ctx)
+	}()
+}
+
+// StopWorkers cancels every background worker and waits for them to return
+func (l *Lifecycle) StopWorkers() {
+	l.cancel()
+	l.workers.Wait()
+}
+
+// Shutdown fails readiness and gives load balancers delay to stop routing new requests here,
+// then stops accepting connections, waits up to timeout for in-flight requests and stops the
+// workers, which may still be using the database and Redis. It returns the error of a server
+// that did not drain in time; the workers are stopped regardless.
+func (l *Lifecycle) Shutdown(app *fiber.App, delay time.Duration, timeout time.Duration) error {
+	l.HealthUseCase.MarkShuttingDown()
+	time.Sleep(delay)
+
+	err := app.ShutdownWithTimeout(timeout)
+	l.StopWorkers()
+	return err
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang-clean-architecture/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestShutdownDrainsInFlightRequests starts shutting down while a slow payment is being handled:
+// the payment completes, new connections are refused and the workers stop only afterwards
+func TestShutdownDrainsInFlightRequests(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler time.Duration
+		timeout time.Duration
+		drained bool
+	}{
+		{name: "within the deadline", handler: 300 * time.Millisecond, timeout: 5 * time.Second, drained: true},
+		{name: "past the deadline", handler: 2 * time.Second, timeout: 100 * time.Millisecond, drained: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			handled := make(chan time.Time, 1)
+			app := fiber.New(fiber.Config{DisableStartupMessage: true})
+			app.Post("/api/payments", func(ctx *fiber.Ctx) error {
+				close(started)
+				time.Sleep(tt.handler)
+				handled <- time.Now()
+				return ctx.SendString("paid")
+			})
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("listen: %v", err)
+			}
+			go app.Listener(listener)
+			url := "http://" + listener.Addr().String() + "/api/payments"
+
+			lifecycle := NewLifecycle()
+			lifecycle.HealthUseCase = new(usecase.HealthUseCase)
+			workerStopped := make(chan time.Time, 1)
+			lifecycle.Go(func(ctx context.Context) {
+				<-ctx.Done()
+				workerStopped <- time.Now()
+			})
+
+			type result struct {
+				body string
+				err  error
+			}
+			inFlight := make(chan result, 1)
+			go func() {
+				response, err := http.Post(url, "application/json", nil)
+				if err != nil {
+					inFlight <- result{err: err}
+					return
+				}
+				defer response.Body.Close()
+				body, err := io.ReadAll(response.Body)
+				inFlight <- result{body: string(body), err: err}
+			}()
+			<-started
+
+			err = lifecycle.Shutdown(app, 0, tt.timeout)
+			if drained := err == nil; drained != tt.drained {
+				t.Fatalf("Shutdown error = %v, want drained %v", err, tt.drained)
+			}
+			stoppedAt := <-workerStopped
+
+			if _, err := http.Post(url, "application/json", nil); err == nil {
+				t.Fatalf("new request accepted after shutdown")
+			}
+
+			if !tt.drained {
+				return
+			}
+			got := <-inFlight
+			if got.err != nil || got.body != "paid" {
+				t.Fatalf("in-flight request got %q, error %v", got.body, got.err)
+			}
+			if handledAt := <-handled; stoppedAt.Before(handledAt) {
+				t.Fatalf("worker stopped at %v, before the in-flight request was handled at %v", stoppedAt, handledAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"golang-clean-architecture/internal/usecase"
+
+	"github.com/spf13/viper"
+)
+
+// NewLimitTiers reads the spending limit tiers configured under limits.tiers, keyed by name
+func NewLimitTiers(viper *viper.Viper) map[string]usecase.LimitTier {
+	tiers := make(map[string]usecase.LimitTier)
+	for name := range viper.GetStringMap("limits.tiers") {
+		key := "limits.tiers." + name
+		tiers[name] = usecase.LimitTier{
+			SingleMax:   viper.GetFloat64(key + ".single_max"),
+			DailyMax:    viper.GetFloat64(key + ".daily_max"),
+			MonthlyMax:  viper.GetFloat64(key + ".monthly_max"),
+			HourlyCount: viper.GetInt(key + ".hourly_count"),
+		}
+	}
+	return tiers
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"golang-clean-architecture/internal/model"
+
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
@@ -10,6 +12,23 @@ func NewLogger(viper *viper.Viper) *logrus.Logger {
 
 	log.SetLevel(logrus.Level(viper.GetInt32("log.level")))
 	log.SetFormatter(&logrus.JSONFormatter{})
+	log.AddHook(&requestContextHook{})
+	log.AddHook(&redactionHook{})
 
 	return log
 }
+
+// requestContextHook adds the request and trace IDs to entries logged with WithContext
+type requestContextHook struct{}
+
+func (h *requestContextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *requestContextHook) Fire(entry *logrus.Entry) error {
+	if requestContext, ok := model.RequestContextFrom(entry.Context); ok {
+		entry.Data["request_id"] = requestContext.RequestID
+		entry.Data["trace_id"] = requestContext.TraceID
+	}
+	return nil
+}
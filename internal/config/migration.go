@@ -0,0 +1,231 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"golang-clean-architecture/db"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// migrationLockID is the Postgres advisory lock key serializing migrations across replicas
+const migrationLockID int64 = 7_361_020_260_225
+
+// Migrator applies the SQL migrations embedded in the binary, keeping the schema_migrations
+// table compatible with the golang-migrate CLI
+type Migrator struct {
+	Log     *logrus.Logger
+	DB      *sql.DB
+	Migrate *migrate.Migrate
+}
+
+func NewMigrator(viper *viper.Viper, log *logrus.Logger) (*Migrator, error) {
+	sqlDB, err := sql.Open("pgx", databaseDSN(viper))
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := iofs.New(db.Migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := pgx.WithInstance(sqlDB, &pgx.Config{})
+	if err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "pgx5", driver)
+	if err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+
+	return &Migrator{
+		Log:     log,
+		DB:      sqlDB,
+		Migrate: m,
+	}, nil
+}
+
+// Close releases the migration connection pool (the driver closes the underlying sql.DB)
+func (m *Migrator) Close() {
+	m.Migrate.Close()
+}
+
+// Up applies every pending migration while holding the advisory lock
+func (m *Migrator) Up() error {
+	return m.withAdvisoryLock(func() error {
+		if err := m.ensureClean(); err != nil {
+			return err
+		}
+
+		err := m.Migrate.Up()
+		if errors.Is(err, migrate.ErrNoChange) {
+			m.Log.Info("Database schema is up to date")
+			return nil
+		}
+		return err
+	})
+}
+
+// Down reverts the given number of migrations
+func (m *Migrator) Down(steps int) error {
+	return m.withAdvisoryLock(func() error {
+		return m.Migrate.Steps(-steps)
+	})
+}
+
+// Force sets the schema version without running migrations, clearing the dirty flag
+func (m *Migrator) Force(version int) error {
+	return m.withAdvisoryLock(func() error {
+		return m.Migrate.Force(version)
+	})
+}
+
+// Version returns the applied version, 0 when no migration has been applied yet
+func (m *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := m.Migrate.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+func (m *Migrator) ensureClean() error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("database schema is dirty at version %d, fix it manually then run: migrate force <version>", version)
+	}
+	return nil
+}
+
+func (m *Migrator) withAdvisoryLock(fn func() error) error {
+	ctx := context.Background()
+
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	return fn()
+}
+
+// LatestMigrationVersion returns the highest version among the embedded migrations
+func LatestMigrationVersion() uint {
+	entries, err := fs.ReadDir(db.Migrations, "migrations")
+	if err != nil {
+		return 0
+	}
+
+	var latest uint64
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok {
+			continue
+		}
+		if version, err := strconv.ParseUint(prefix, 10, 64); err == nil && version > latest {
+			latest = version
+		}
+	}
+	return uint(latest)
+}
+
+// PrepareSchema runs at startup: it applies pending migrations when database.migrations.auto
+// is on and refuses to start when the schema is dirty
+func PrepareSchema(viper *viper.Viper, log *logrus.Logger) {
+	migrator, err := NewMigrator(viper, log)
+	if err != nil {
+		log.Fatalf("failed to open migrations: %v", err)
+	}
+	defer migrator.Close()
+
+	if viper.GetBool("database.migrations.auto") {
+		if err := migrator.Up(); err != nil {
+			log.Fatalf("failed to migrate database: %v", err)
+		}
+	}
+
+	version, dirty, err := migrator.Version()
+	if err != nil {
+		log.Fatalf("failed to read schema version: %v", err)
+	}
+	if dirty {
+		log.Fatalf("database schema is dirty at version %d, refusing to start", version)
+	}
+	if latest := LatestMigrationVersion(); version != latest {
+		log.Warnf("Database schema is at version %d but this build expects %d", version, latest)
+	}
+}
+
+// RunMigrateCommand implements: migrate up | down [steps] | status | force <version>
+func RunMigrateCommand(viper *viper.Viper, log *logrus.Logger, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up | down [steps] | status | force <version>")
+	}
+
+	migrator, err := NewMigrator(viper, log)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	switch args[0] {
+	case "up":
+		if err := migrator.Up(); err != nil {
+			return err
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid steps: %s", args[1])
+			}
+		}
+		if err := migrator.Down(steps); err != nil {
+			return err
+		}
+	case "force":
+		if len(args) < 2 {
+			return errors.New("usage: migrate force <version>")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version: %s", args[1])
+		}
+		if err := migrator.Force(version); err != nil {
+			return err
+		}
+	case "status":
+	default:
+		return fmt.Errorf("unknown migrate command: %s", args[0])
+	}
+
+	version, dirty, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("version: %d\ndirty: %t\nlatest: %d\n", version, dirty, LatestMigrationVersion())
+	return nil
+}
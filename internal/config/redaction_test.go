@@ -0,0 +1,117 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang-clean-architecture/internal/model"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func TestRedactText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "otp", text: "Fake OTP for account: user_123456, otp: 482913", want: "Fake OTP for account: *******3456, otp: [REDACTED]"},
+		{name: "struct otp code", text: "challenge {OtpCode:482913 ChallengeID:abc}", want: "challenge {OtpCode:[REDACTED] ChallengeID:abc}"},
+		{name: "json otp code", text: `{"challenge_id":"abc","otp_code":"482913"}`, want: `{"challenge_id":"abc","otp_code":"[REDACTED]"}`},
+		{
+			name: "switch response code",
+			text: "Switch declined transaction: 5f0c6a1e, response code: 05",
+			want: "Switch declined transaction: 5f0c6a1e, response code: 05",
+		},
+		{name: "pin query", text: "pin=123456&amount=100", want: "pin=[REDACTED]&amount=100"},
+		{name: "user", text: "Invalid PIN for user: user_123456", want: "Invalid PIN for user: *******3456"},
+		{name: "short account", text: "account_id=1234", want: "account_id=****"},
+		{
+			name: "empty struct field",
+			text: "{UserID:user_123456 Pincode:123456 DeviceID: Signature:c2lnbmVk}",
+			want: "{UserID:*******3456 Pincode:[REDACTED] DeviceID: Signature:[REDACTED]}",
+		},
+		{name: "client secret", text: "client_secret: 9f86d081884c", want: "client_secret: [REDACTED]"},
+		{name: "nothing sensitive", text: "Applied hot account batch: 3 of 4 debits, total 150000.00", want: "Applied hot account batch: 3 of 4 debits, total 150000.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactText(tt.text); got != tt.want {
+				t.Fatalf("redactText(%q)\n got %q\nwant %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactField(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value interface{}
+		want  interface{}
+	}{
+		{name: "pin", key: "pin", value: "123456", want: redacted},
+		{name: "otp code", key: "otp_code", value: "482913", want: redacted},
+		{name: "response code", key: "response_code", value: "05", want: "05"},
+		{name: "secret", key: "client_secret", value: "9f86d081884c", want: redacted},
+		{name: "signature header", key: "X-Signature", value: "a5f8e", want: redacted},
+		{name: "account", key: "account_id", value: "user_123456", want: "*******3456"},
+		{name: "account number", key: "account_id", value: 9876543210, want: "******3210"},
+		{name: "error text", key: "error", value: errors.New("bad request user: user_123456"), want: "bad request user: *******3456"},
+		{name: "other", key: "entry_type", value: "MERCHANT_ID", want: "MERCHANT_ID"},
+		{name: "count", key: "scanned", value: 12, want: 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactField(tt.key, tt.value); got != tt.want {
+				t.Fatalf("redactField(%q, %v) = %v, want %v", tt.key, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLoggerRedacts checks the written entries, so the hooks run in the right order: fields the
+// request context hook adds are redacted as well
+func TestLoggerRedacts(t *testing.T) {
+	log := NewLogger(viper.New())
+	log.SetLevel(logrus.DebugLevel)
+	output := new(bytes.Buffer)
+	log.SetOutput(output)
+
+	ctx := model.WithRequestContext(t.Context(), &model.RequestContext{RequestID: "req_1", Actor: "merchant_app"})
+	log.WithContext(ctx).WithFields(logrus.Fields{
+		"entry_type": "ACCOUNT_ID",
+		"account_id": "user_123456",
+	}).Infof("List entry added by %s: %s", "admin_app", "chargeback fraud")
+	log.WithField("account_id", "user_123456").Debugf("Applied hot account batch: %d of %d debits, total %.2f", 3, 4, 150000.0)
+	log.Infof("Fake OTP for account: %s, otp: %s", "user_123456", "482913")
+
+	written := output.String()
+	for _, secret := range []string{"user_123456", "482913"} {
+		if strings.Contains(written, secret) {
+			t.Fatalf("log output contains %q:\n%s", secret, written)
+		}
+	}
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(written), "\n") {
+		entry := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3:\n%s", len(entries), written)
+	}
+	if entries[0]["account_id"] != "*******3456" || entries[0]["request_id"] != "req_1" {
+		t.Fatalf("list entry logged as %v", entries[0])
+	}
+	if entries[1]["account_id"] != "*******3456" {
+		t.Fatalf("hot account batch logged as %v", entries[1])
+	}
+}
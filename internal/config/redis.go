@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -21,6 +22,10 @@ func NewRedis(viper *viper.Viper, log *logrus.Logger) *redis.Client {
 		DB:       db,
 	})
 
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		log.Fatalf("failed to instrument redis: %v", err)
+	}
+
 	_, err := client.Ping(context.Background()).Result()
 	if err != nil {
 		log.Fatalf("failed to connect redis: %v", err)
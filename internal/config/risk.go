@@ -0,0 +1,50 @@
+package config
+
+import (
+	"time"
+
+	"golang-clean-architecture/internal/risk"
+	"golang-clean-architecture/internal/usecase"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// NewRiskConfig builds the payment screening configured under risk; screening is off when
+// risk.enabled is false
+func NewRiskConfig(viper *viper.Viper, log *logrus.Logger, history risk.History) usecase.RiskConfig {
+	if !viper.GetBool("risk.enabled") {
+		return usecase.RiskConfig{}
+	}
+
+	var settings []RiskRule
+	if err := viper.UnmarshalKey("risk.rules", &settings); err != nil {
+		log.Fatalf("failed to load risk rules: %v", err)
+	}
+
+	rules := make([]risk.Rule, 0, len(settings))
+	for _, rule := range settings {
+		rules = append(rules, risk.Rule{
+			Name:         rule.Name,
+			Type:         rule.Type,
+			Score:        rule.Score,
+			Action:       risk.Action(rule.Action),
+			Multiplier:   rule.Multiplier,
+			Lookback:     time.Duration(rule.LookbackDays) * 24 * time.Hour,
+			MinHistory:   int64(rule.MinHistory),
+			Window:       time.Duration(rule.WindowMinutes) * time.Minute,
+			MaxMerchants: rule.MaxMerchants,
+			Accounts:     rule.Accounts,
+			Merchants:    rule.Merchants,
+		})
+	}
+
+	return usecase.RiskConfig{
+		Engine: risk.NewRuleEngine(risk.Config{
+			Rules:        rules,
+			StepUpScore:  viper.GetInt("risk.step_up_score"),
+			DeclineScore: viper.GetInt("risk.decline_score"),
+		}, history),
+		Shadow: viper.GetBool("risk.shadow"),
+	}
+}
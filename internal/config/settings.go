@@ -0,0 +1,251 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/viper"
+)
+
+// Settings is the typed shape of the configuration. It is only used to validate the loaded
+// configuration at startup; components keep reading their keys from viper.
+type Settings struct {
+	App struct {
+		Name string `mapstructure:"name" validate:"required"`
+	} `mapstructure:"app"`
+	Web struct {
+		Prefork  bool `mapstructure:"prefork"`
+		Port     int  `mapstructure:"port" validate:"min=1,max=65535"`
+		Shutdown struct {
+			Delay   int `mapstructure:"delay" validate:"gte=0"`
+			Timeout int `mapstructure:"timeout" validate:"gt=0"`
+		} `mapstructure:"shutdown"`
+	} `mapstructure:"web"`
+	Log struct {
+		Level int `mapstructure:"level" validate:"gte=0,lte=6"`
+	} `mapstructure:"log"`
+	Database struct {
+		Username string `mapstructure:"username" validate:"required"`
+		Password string `mapstructure:"password"`
+		Host     string `mapstructure:"host" validate:"required"`
+		Port     int    `mapstructure:"port" validate:"min=1,max=65535"`
+		Name     string `mapstructure:"name" validate:"required"`
+		Timezone string `mapstructure:"timezone" validate:"required,timezone"`
+		LogLevel string `mapstructure:"log_level" validate:"oneof=silent error warn info"`
+		Pool     struct {
+			Idle     int `mapstructure:"idle" validate:"gte=0"`
+			Max      int `mapstructure:"max" validate:"gt=0"`
+			Lifetime int `mapstructure:"lifetime" validate:"gte=0"`
+		} `mapstructure:"pool"`
+		Migrations struct {
+			Auto bool `mapstructure:"auto"`
+		} `mapstructure:"migrations"`
+	} `mapstructure:"database"`
+	Redis struct {
+		Host     string `mapstructure:"host" validate:"required"`
+		Port     int    `mapstructure:"port" validate:"min=1,max=65535"`
+		Password string `mapstructure:"password"`
+		DB       int    `mapstructure:"db" validate:"gte=0,lte=15"`
+	} `mapstructure:"redis"`
+	Payment struct {
+		Lock struct {
+			Strategy       string `mapstructure:"strategy" validate:"oneof=optimistic pessimistic"`
+			MaxRetries     int    `mapstructure:"max_retries" validate:"gte=0"`
+			RetryBackoffMs int    `mapstructure:"retry_backoff_ms" validate:"gte=0"`
+		} `mapstructure:"lock"`
+		HotAccount struct {
+			Enabled      bool     `mapstructure:"enabled"`
+			Accounts     []string `mapstructure:"accounts" validate:"required_if=Enabled true"`
+			MaxBatchSize int      `mapstructure:"max_batch_size" validate:"required_if=Enabled true,gte=0"`
+			MaxWaitMs    int      `mapstructure:"max_wait_ms" validate:"gte=0"`
+		} `mapstructure:"hot_account"`
+		MDRPercent float64 `mapstructure:"mdr_percent" validate:"gte=0,lte=100"`
+	} `mapstructure:"payment"`
+	Limits struct {
+		Enabled     bool                 `mapstructure:"enabled"`
+		DefaultTier string               `mapstructure:"default_tier" validate:"required_if=Enabled true"`
+		Tiers       map[string]LimitTier `mapstructure:"tiers" validate:"required_if=Enabled true,dive"`
+	} `mapstructure:"limits"`
+	Risk struct {
+		Enabled      bool       `mapstructure:"enabled"`
+		Shadow       bool       `mapstructure:"shadow"`
+		StepUpScore  int        `mapstructure:"step_up_score" validate:"gte=0"`
+		DeclineScore int        `mapstructure:"decline_score" validate:"gte=0"`
+		Rules        []RiskRule `mapstructure:"rules" validate:"dive"`
+	} `mapstructure:"risk"`
+	StepUp struct {
+		Enabled     bool    `mapstructure:"enabled"`
+		Threshold   float64 `mapstructure:"threshold" validate:"gte=0"`
+		MaxAttempts int     `mapstructure:"max_attempts" validate:"required_if=Enabled true,gte=0"`
+		Sender      string  `mapstructure:"sender" validate:"oneof=webhook fake"`
+		Webhook     struct {
+			URL       string `mapstructure:"url" validate:"omitempty,url"`
+			TimeoutMs int    `mapstructure:"timeout_ms" validate:"gt=0"`
+		} `mapstructure:"webhook"`
+	} `mapstructure:"step_up"`
+	Pin struct {
+		MaxAttempts int `mapstructure:"max_attempts" validate:"gte=0"`
+		Lockout     int `mapstructure:"lockout" validate:"required_with=MaxAttempts,gte=0"`
+	} `mapstructure:"pin"`
+	DeviceBinding struct {
+		Enabled bool `mapstructure:"enabled"`
+	} `mapstructure:"device_binding"`
+	Lists struct {
+		Enabled  bool `mapstructure:"enabled"`
+		CacheTTL int  `mapstructure:"cache_ttl" validate:"gt=0"`
+	} `mapstructure:"lists"`
+	Admin struct {
+		ClientIDs []string `mapstructure:"client_ids" validate:"dive,required"`
+	} `mapstructure:"admin"`
+	Audit struct {
+		Chains int `mapstructure:"chains" validate:"gt=0,lte=1024"`
+	} `mapstructure:"audit"`
+	Fx struct {
+		HomeCurrency string `mapstructure:"home_currency" validate:"len=3,uppercase"`
+		LockTTL      int    `mapstructure:"lock_ttl" validate:"gt=0,lte=300"`
+		Provider     string `mapstructure:"provider" validate:"oneof=static"`
+		StaticFile   string `mapstructure:"static_file" validate:"required_if=Provider static"`
+	} `mapstructure:"fx"`
+	Switching struct {
+		Enabled         bool     `mapstructure:"enabled"`
+		Protocol        string   `mapstructure:"protocol" validate:"oneof=http iso8583"`
+		URL             string   `mapstructure:"url" validate:"required_if=Enabled true Protocol http,omitempty,url"`
+		TimeoutMs       int      `mapstructure:"timeout_ms" validate:"gt=0"`
+		ReversalRetries int      `mapstructure:"reversal_retries" validate:"gte=0"`
+		OnUsAcquirers   []string `mapstructure:"on_us_acquirers" validate:"required_if=Enabled true"`
+		ISO8583         struct {
+			Address        string `mapstructure:"address" validate:"omitempty,hostname_port"`
+			InstitutionID  string `mapstructure:"institution_id" validate:"omitempty,numeric,max=11"`
+			EchoIntervalMs int    `mapstructure:"echo_interval_ms" validate:"gt=0"`
+			ReconnectMinMs int    `mapstructure:"reconnect_min_ms" validate:"gt=0"`
+			ReconnectMaxMs int    `mapstructure:"reconnect_max_ms" validate:"gt=0"`
+		} `mapstructure:"iso8583"`
+	} `mapstructure:"switching"`
+	Worker struct {
+		Sweeper struct {
+			Enabled    bool `mapstructure:"enabled"`
+			Interval   int  `mapstructure:"interval" validate:"required_if=Enabled true,gte=0"`
+			PendingTTL int  `mapstructure:"pending_ttl" validate:"required_if=Enabled true,gte=0"`
+			BatchSize  int  `mapstructure:"batch_size" validate:"required_if=Enabled true,gte=0"`
+		} `mapstructure:"sweeper"`
+	} `mapstructure:"worker"`
+	Telemetry struct {
+		Tracing struct {
+			Exporter    string  `mapstructure:"exporter" validate:"oneof=none otlp"`
+			Endpoint    string  `mapstructure:"endpoint" validate:"required_if=Exporter otlp"`
+			Insecure    bool    `mapstructure:"insecure"`
+			SampleRatio float64 `mapstructure:"sample_ratio" validate:"gte=0,lte=1"`
+		} `mapstructure:"tracing"`
+	} `mapstructure:"telemetry"`
+	Metrics struct {
+		PreforkInterval int `mapstructure:"prefork_interval" validate:"gt=0"`
+	} `mapstructure:"metrics"`
+	Health struct {
+		TimeoutMs int `mapstructure:"timeout_ms" validate:"gt=0"`
+	} `mapstructure:"health"`
+	Report struct {
+		MaxRangeDays int `mapstructure:"max_range_days" validate:"gt=0"`
+	} `mapstructure:"report"`
+	Reconciliation struct {
+		BatchSize int `mapstructure:"batch_size" validate:"gt=0"`
+		File      struct {
+			Format        string  `mapstructure:"format" validate:"oneof=csv fixed_width"`
+			Delimiter     string  `mapstructure:"delimiter" validate:"required_if=Format csv,omitempty,len=1"`
+			SkipHeader    bool    `mapstructure:"skip_header"`
+			AmountDivisor float64 `mapstructure:"amount_divisor" validate:"gt=0"`
+			Fields        struct {
+				Reference SettlementField `mapstructure:"reference"`
+				Amount    SettlementField `mapstructure:"amount"`
+			} `mapstructure:"fields"`
+		} `mapstructure:"file"`
+	} `mapstructure:"reconciliation"`
+}
+
+// LimitTier is a spending limit tier; zero leaves a limit off
+type LimitTier struct {
+	SingleMax   float64 `mapstructure:"single_max" validate:"gte=0"`
+	DailyMax    float64 `mapstructure:"daily_max" validate:"gte=0"`
+	MonthlyMax  float64 `mapstructure:"monthly_max" validate:"gte=0"`
+	HourlyCount int     `mapstructure:"hourly_count" validate:"gte=0"`
+}
+
+// RiskRule is a payment screening rule; only the parameters of its type apply
+type RiskRule struct {
+	Name          string   `mapstructure:"name" validate:"required"`
+	Type          string   `mapstructure:"type" validate:"oneof=new_device unusual_amount merchant_velocity blacklist"`
+	Score         int      `mapstructure:"score" validate:"gte=0"`
+	Action        string   `mapstructure:"action" validate:"omitempty,oneof=step_up decline"`
+	Multiplier    float64  `mapstructure:"multiplier" validate:"required_if=Type unusual_amount,gte=0"`
+	LookbackDays  int      `mapstructure:"lookback_days" validate:"required_if=Type unusual_amount,gte=0"`
+	MinHistory    int      `mapstructure:"min_history" validate:"gte=0"`
+	WindowMinutes int      `mapstructure:"window_minutes" validate:"required_if=Type merchant_velocity,gte=0"`
+	MaxMerchants  int      `mapstructure:"max_merchants" validate:"required_if=Type merchant_velocity,gte=0"`
+	Accounts      []string `mapstructure:"accounts"`
+	Merchants     []string `mapstructure:"merchants"`
+}
+
+// SettlementField locates a value by CSV column or by fixed-width offset and length
+type SettlementField struct {
+	Column int `mapstructure:"column" validate:"gte=0"`
+	Start  int `mapstructure:"start" validate:"gte=0"`
+	Length int `mapstructure:"length" validate:"gt=0"`
+}
+
+// ValidateSettings decodes the configuration into Settings and reports every invalid or
+// missing value by its configuration key, e.g. "database.host is required"
+func ValidateSettings(config *viper.Viper) (*Settings, error) {
+	settings := new(Settings)
+	if err := config.Unmarshal(settings); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return field.Tag.Get("mapstructure")
+	})
+
+	err := validate.Struct(settings)
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return settings, err
+	}
+
+	problems := make([]string, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		key := fieldError.Namespace()[strings.Index(fieldError.Namespace(), ".")+1:]
+		problems = append(problems, fmt.Sprintf("%s %s (got %v)", key, describeRule(fieldError), fieldError.Value()))
+	}
+	return nil, fmt.Errorf("invalid config: %s", strings.Join(problems, "; "))
+}
+
+func describeRule(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required", "required_if":
+		return "is required"
+	case "oneof":
+		return "must be one of [" + fieldError.Param() + "]"
+	case "url":
+		return "must be a URL"
+	case "hostname_port":
+		return "must be a host:port address"
+	case "numeric":
+		return "must be numeric"
+	case "uppercase":
+		return "must be uppercase"
+	case "len":
+		return "must have length " + fieldError.Param()
+	case "min", "gte":
+		return "must be at least " + fieldError.Param()
+	case "max", "lte":
+		return "must be at most " + fieldError.Param()
+	case "timezone":
+		return "must be an IANA time zone name"
+	case "gt":
+		return "must be greater than " + fieldError.Param()
+	default:
+		return "failed " + fieldError.Tag() + " " + fieldError.Param()
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"golang-clean-architecture/internal/settlement"
+
+	"github.com/spf13/viper"
+)
+
+// NewSettlementParser reads the layout of the switch settlement files from reconciliation.file
+func NewSettlementParser(viper *viper.Viper) *settlement.Parser {
+	delimiter := ','
+	if value := []rune(viper.GetString("reconciliation.file.delimiter")); len(value) > 0 {
+		delimiter = value[0]
+	}
+
+	return settlement.NewParser(settlement.Config{
+		Format:        viper.GetString("reconciliation.file.format"),
+		Delimiter:     delimiter,
+		SkipHeader:    viper.GetBool("reconciliation.file.skip_header"),
+		AmountDivisor: viper.GetFloat64("reconciliation.file.amount_divisor"),
+		Reference:     settlementField(viper, "reconciliation.file.fields.reference"),
+		Amount:        settlementField(viper, "reconciliation.file.fields.amount"),
+	})
+}
+
+func settlementField(viper *viper.Viper, key string) settlement.Field {
+	return settlement.Field{
+		Column: viper.GetInt(key + ".column"),
+		Start:  viper.GetInt(key + ".start"),
+		Length: viper.GetInt(key + ".length"),
+	}
+}
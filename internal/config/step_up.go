@@ -0,0 +1,44 @@
+package config
+
+import (
+	"time"
+
+	"golang-clean-architecture/internal/otp"
+	"golang-clean-architecture/internal/usecase"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// NewStepUpConfig builds the step-up authentication configured under step_up; challenges are
+// off when step_up.enabled is false
+func NewStepUpConfig(viper *viper.Viper, log *logrus.Logger) usecase.StepUpConfig {
+	if !viper.GetBool("step_up.enabled") {
+		return usecase.StepUpConfig{}
+	}
+
+	return usecase.StepUpConfig{
+		Sender:      NewOTPSender(viper, log),
+		Threshold:   viper.GetFloat64("step_up.threshold"),
+		MaxAttempts: viper.GetInt("step_up.max_attempts"),
+	}
+}
+
+// NewOTPSender builds the sender selected by step_up.sender. Startup fails when the webhook has
+// no URL, rather than issuing challenges nobody can answer.
+func NewOTPSender(viper *viper.Viper, log *logrus.Logger) otp.Sender {
+	switch sender := viper.GetString("step_up.sender"); sender {
+	case "webhook":
+		url := viper.GetString("step_up.webhook.url")
+		if url == "" {
+			log.Fatalf("step_up.sender is webhook but step_up.webhook.url is not set")
+		}
+		return otp.NewWebhookSender(log, url, time.Duration(viper.GetInt("step_up.webhook.timeout_ms"))*time.Millisecond)
+	case "fake":
+		log.Warn("Step-up OTPs are not delivered: step_up.sender is fake")
+		return otp.NewFakeSender(log)
+	default:
+		log.Fatalf("unknown step_up.sender: %q", sender)
+		return nil
+	}
+}
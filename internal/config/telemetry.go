@@ -0,0 +1,67 @@
+package config
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// NewTracerProvider installs the global OpenTelemetry tracer provider using the exporter
+// selected by telemetry.tracing.exporter: "otlp" (gRPC) or "none"
+func NewTracerProvider(viper *viper.Viper, log *logrus.Logger) *sdktrace.TracerProvider {
+	var exporter sdktrace.SpanExporter
+
+	switch viper.GetString("telemetry.tracing.exporter") {
+	case "otlp":
+		options := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(viper.GetString("telemetry.tracing.endpoint")),
+		}
+		if viper.GetBool("telemetry.tracing.insecure") {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+
+		otlpExporter, err := otlptracegrpc.New(context.Background(), options...)
+		if err != nil {
+			log.Fatalf("failed to create otlp trace exporter: %v", err)
+		}
+		exporter = otlpExporter
+	}
+
+	return NewTracerProviderWithExporter(viper, exporter)
+}
+
+// NewTracerProviderWithExporter installs a tracer provider exporting to the given exporter, such
+// as an in-memory one in tests. A nil exporter keeps trace context propagation working without recording root spans.
+func NewTracerProviderWithExporter(viper *viper.Viper, exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(viper.GetString("app.name")),
+		)),
+	}
+
+	if exporter == nil {
+		options = append(options, sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.NeverSample())))
+	} else {
+		ratio := viper.GetFloat64("telemetry.tracing.sample_ratio")
+		options = append(options,
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+			sdktrace.WithBatcher(exporter),
+		)
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return provider
+}
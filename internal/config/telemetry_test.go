@@ -0,0 +1,171 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang-clean-architecture/internal/entity"
+
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newTracingViper(ratio float64) *viper.Viper {
+	config := viper.New()
+	config.Set("app.name", "qris-test")
+	config.Set("telemetry.tracing.sample_ratio", ratio)
+	return config
+}
+
+// installTracerProvider restores the global provider and propagator when the test ends
+func installTracerProvider(t *testing.T, config *viper.Viper, exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	t.Helper()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	provider := NewTracerProviderWithExporter(config, exporter)
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return provider
+}
+
+// newDryRunDatabase builds SQL without a server, with the tracing callbacks registered
+func newDryRunDatabase(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry run database: %v", err)
+	}
+	if err := registerGormTracing(db); err != nil {
+		t.Fatalf("register gorm tracing: %v", err)
+	}
+	return db
+}
+
+func attributeValue(span tracetest.SpanStub, key attribute.Key) string {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestTracerProviderExportsSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := installTracerProvider(t, newTracingViper(1), exporter)
+	db := newDryRunDatabase(t)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "QrisUseCase.Pay")
+	account := new(entity.Account)
+	db.WithContext(ctx).Where("account_id = ?", "user_123456").Take(account)
+	parent.End()
+
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2: %v", len(spans), spans.Snapshots())
+	}
+
+	query, pay := spans[0], spans[1]
+	if pay.Name != "QrisUseCase.Pay" {
+		t.Fatalf("root span %q, want QrisUseCase.Pay", pay.Name)
+	}
+	if got, _ := pay.Resource.Set().Value(semconv.ServiceNameKey); got.Emit() != "qris-test" {
+		t.Fatalf("service name %q, want qris-test", got.Emit())
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{name: "name", got: query.Name, want: "gorm.query"},
+		{name: "kind", got: query.SpanKind, want: trace.SpanKindClient},
+		{name: "parent", got: query.Parent.SpanID(), want: pay.SpanContext.SpanID()},
+		{name: "trace", got: query.SpanContext.TraceID(), want: pay.SpanContext.TraceID()},
+		{name: "table", got: attributeValue(query, semconv.DBCollectionNameKey), want: "accounts"},
+		{name: "system", got: attributeValue(query, semconv.DBSystemKey), want: "postgresql"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Fatalf("query span %s = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+
+	// Bind parameters stay out of traces, only the parameterized SQL is recorded
+	statement := attributeValue(query, semconv.DBQueryTextKey)
+	if !strings.Contains(statement, "$1") || strings.Contains(statement, "user_123456") {
+		t.Fatalf("query span recorded %q", statement)
+	}
+}
+
+func TestTracerProviderSampling(t *testing.T) {
+	remoteParent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+
+	tests := []struct {
+		name     string
+		exporter bool
+		ratio    float64
+		parent   bool
+		exported int
+	}{
+		{name: "ratio 1 records roots", exporter: true, ratio: 1, exported: 1},
+		{name: "ratio 0 drops roots", exporter: true, ratio: 0, exported: 0},
+		{name: "sampled caller overrides the ratio", exporter: true, ratio: 0, parent: true, exported: 1},
+		{name: "no exporter", exporter: false, ratio: 1, parent: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			var spanExporter sdktrace.SpanExporter
+			if tt.exporter {
+				spanExporter = exporter
+			}
+			provider := installTracerProvider(t, newTracingViper(tt.ratio), spanExporter)
+
+			ctx := context.Background()
+			if tt.parent {
+				ctx = trace.ContextWithRemoteSpanContext(ctx, remoteParent)
+			}
+			ctx, span := otel.Tracer("test").Start(ctx, "PaymentController.Pay")
+
+			// The caller's trace is carried on to the switch and webhooks either way
+			carrier := propagation.MapCarrier{}
+			otel.GetTextMapPropagator().Inject(ctx, carrier)
+			span.End()
+
+			if tt.parent && !strings.Contains(carrier.Get("traceparent"), remoteParent.TraceID().String()) {
+				t.Fatalf("traceparent %q does not continue trace %s", carrier.Get("traceparent"), remoteParent.TraceID())
+			}
+			if err := provider.ForceFlush(context.Background()); err != nil {
+				t.Fatalf("flush: %v", err)
+			}
+			if got := len(exporter.GetSpans()); got != tt.exported {
+				t.Fatalf("exported %d spans, want %d", got, tt.exported)
+			}
+		})
+	}
+}
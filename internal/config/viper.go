@@ -2,24 +2,63 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
-// NewViper is a function to load config from config.json
-// You can change the implementation, for example load from env file, consul, etcd, etc
-func NewViper() *viper.Viper {
+const envPrefix = "QRIS"
+
+// NewViper loads configuration from configFile, or from config.{json,yaml,toml} in ./ or ./../
+// when configFile is empty. Every key can be overridden by an environment variable named after
+// it (database.host -> QRIS_DATABASE_HOST) and read from a file by appending _FILE to that name
+// (QRIS_DATABASE_PASSWORD_FILE=/run/secrets/db_password), as Docker and Kubernetes mount secrets.
+func NewViper(configFile string) *viper.Viper {
 	config := viper.New()
 
-	config.SetConfigName("config")
-	config.SetConfigType("json")
-	config.AddConfigPath("./../")
-	config.AddConfigPath("./")
-	err := config.ReadInConfig()
+	if configFile != "" {
+		config.SetConfigFile(configFile)
+	} else {
+		config.SetConfigName("config")
+		config.AddConfigPath("./../")
+		config.AddConfigPath("./")
+	}
 
+	err := config.ReadInConfig()
 	if err != nil {
 		panic(fmt.Errorf("Fatal error config file: %w \n", err))
 	}
 
+	config.SetEnvPrefix(envPrefix)
+	config.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	config.AutomaticEnv()
+
+	if err := loadSecretFiles(config); err != nil {
+		panic(fmt.Errorf("Fatal error config secret: %w \n", err))
+	}
+
+	if _, err := ValidateSettings(config); err != nil {
+		panic(fmt.Errorf("Fatal error config: %w \n", err))
+	}
+
 	return config
 }
+
+// loadSecretFiles sets every key whose QRIS_<KEY>_FILE variable points to a readable file
+func loadSecretFiles(config *viper.Viper) error {
+	for _, key := range config.AllKeys() {
+		env := envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_")) + "_FILE"
+		path, ok := os.LookupEnv(env)
+		if !ok {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", env, err)
+		}
+		config.Set(key, strings.TrimRight(string(content), "\r\n"))
+	}
+	return nil
+}
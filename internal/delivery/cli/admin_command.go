@@ -0,0 +1,328 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/usecase"
+)
+
+const AdminUsage = `usage: admin [--config file] [--json] <command>
+
+commands:
+  client create <client_id>
+  client disable <client_id>
+  account create --id <account_id> [--balance <amount>] [--currency IDR] [--tier <limit tier>] [--totp]
+  device register <account_id> <device_id> <base64 public key>
+  device revoke <account_id> <device_id>
+  merchant create --id <merchant_id> --name <name> --mcc <mcc> --city <city>
+  transaction get <transaction_id>
+  transaction force <transaction_id> <SUCCESS|EXPIRED|FAILED>
+  reconcile --file <settlement file> --date <YYYY-MM-DD>
+  reconciliation get <run_id> [--result <result>] [--page 1] [--size 100]
+  audit verify
+
+account create reads the 6 digit PIN from stdin, prompting without echo on a terminal.`
+
+// ErrUsage is returned when the command line does not match any command
+var ErrUsage = errors.New(AdminUsage)
+
+// ErrAuditTampered is returned, once the result is printed, when audit verify finds a broken chain
+var ErrAuditTampered = errors.New("audit chain broken")
+
+// AdminCommand dispatches operator commands to the use cases and prints their result, either
+// as "key: value" lines or, with JSON set, as the same envelope the HTTP API returns
+type AdminCommand struct {
+	AdminUseCase          *usecase.AdminUseCase
+	TransactionUseCase    *usecase.TransactionUseCase
+	ReconciliationUseCase *usecase.ReconciliationUseCase
+	AuditUseCase          *usecase.AuditUseCase
+	In                    io.Reader
+	Out                   io.Writer
+	JSON                  bool
+}
+
+func NewAdminCommand(
+	adminUseCase *usecase.AdminUseCase,
+	transactionUseCase *usecase.TransactionUseCase,
+	reconciliationUseCase *usecase.ReconciliationUseCase,
+	auditUseCase *usecase.AuditUseCase,
+	in io.Reader,
+	out io.Writer,
+	json bool,
+) *AdminCommand {
+	return &AdminCommand{
+		AdminUseCase:          adminUseCase,
+		TransactionUseCase:    transactionUseCase,
+		ReconciliationUseCase: reconciliationUseCase,
+		AuditUseCase:          auditUseCase,
+		In:                    in,
+		Out:                   out,
+		JSON:                  json,
+	}
+}
+
+// Run executes args, e.g. ["client", "create", "merchant_app"]. Use case errors are printed
+// and returned so the caller can exit non-zero.
+func (c *AdminCommand) Run(ctx context.Context, args []string) error {
+	result, err := c.dispatch(ctx, args)
+	if errors.Is(err, ErrUsage) {
+		return err
+	}
+	if err != nil {
+		c.printError(err)
+		return err
+	}
+	if err := c.print(result); err != nil {
+		return err
+	}
+
+	// Tampering is reported through the exit status too, so scheduled checks can alert on it
+	if verification, ok := result.(*model.VerifyAuditResponse); ok && !verification.Valid {
+		return ErrAuditTampered
+	}
+	return nil
+}
+
+func (c *AdminCommand) dispatch(ctx context.Context, args []string) (any, error) {
+	if len(args) > 0 && args[0] == "reconcile" {
+		return c.reconcile(ctx, args[1:])
+	}
+	if len(args) < 2 {
+		return nil, ErrUsage
+	}
+
+	resource, action, rest := args[0], args[1], args[2:]
+	switch resource + " " + action {
+	case "client create":
+		if len(rest) != 1 {
+			return nil, ErrUsage
+		}
+		return c.AdminUseCase.CreateApiClient(ctx, &model.CreateApiClientRequest{ClientID: rest[0]})
+	case "client disable":
+		if len(rest) != 1 {
+			return nil, ErrUsage
+		}
+		return c.AdminUseCase.DisableApiClient(ctx, rest[0])
+	case "account create":
+		request := new(model.CreateAccountRequest)
+		flags := newFlagSet("account create")
+		flags.StringVar(&request.AccountID, "id", "", "account id")
+		flags.Float64Var(&request.Balance, "balance", 0, "opening balance")
+		flags.StringVar(&request.Currency, "currency", "IDR", "ISO 4217 currency code")
+		flags.StringVar(&request.LimitTier, "tier", "", "spending limit tier, empty for limits.default_tier")
+		flags.BoolVar(&request.Totp, "totp", false, "answer step-up challenges with an authenticator app")
+		if err := flags.Parse(rest); err != nil || flags.NArg() > 0 {
+			return nil, ErrUsage
+		}
+		pin, err := c.readPin()
+		if err != nil {
+			return nil, err
+		}
+		request.Pin = pin
+		return c.AdminUseCase.CreateAccount(ctx, request)
+	case "device register":
+		if len(rest) != 3 {
+			return nil, ErrUsage
+		}
+		return c.AdminUseCase.RegisterDevice(ctx, &model.RegisterDeviceRequest{AccountID: rest[0], DeviceID: rest[1], PublicKey: rest[2]})
+	case "device revoke":
+		if len(rest) != 2 {
+			return nil, ErrUsage
+		}
+		return c.AdminUseCase.RevokeDevice(ctx, &model.RevokeDeviceRequest{AccountID: rest[0], DeviceID: rest[1]})
+	case "merchant create":
+		request := new(model.CreateMerchantRequest)
+		flags := newFlagSet("merchant create")
+		flags.StringVar(&request.MerchantID, "id", "", "merchant id")
+		flags.StringVar(&request.MerchantName, "name", "", "merchant name")
+		flags.StringVar(&request.MCC, "mcc", "", "merchant category code")
+		flags.StringVar(&request.City, "city", "", "merchant city")
+		if err := flags.Parse(rest); err != nil || flags.NArg() > 0 {
+			return nil, ErrUsage
+		}
+		return c.AdminUseCase.CreateMerchant(ctx, request)
+	case "transaction get":
+		if len(rest) != 1 {
+			return nil, ErrUsage
+		}
+		return c.TransactionUseCase.Get(ctx, rest[0])
+	case "transaction force":
+		if len(rest) != 2 {
+			return nil, ErrUsage
+		}
+		return c.TransactionUseCase.ForceTransition(ctx, &model.ForceTransitionRequest{
+			TransactionID: rest[0],
+			Status:        strings.ToUpper(rest[1]),
+		})
+	case "reconciliation get":
+		if len(rest) < 1 {
+			return nil, ErrUsage
+		}
+		request := &model.ReconciliationReportRequest{RunID: rest[0]}
+		flags := newFlagSet("reconciliation get")
+		flags.StringVar(&request.Result, "result", "", "only items with this result")
+		flags.IntVar(&request.Page, "page", 1, "page number")
+		flags.IntVar(&request.Size, "size", 100, "page size")
+		if err := flags.Parse(rest[1:]); err != nil || flags.NArg() > 0 {
+			return nil, ErrUsage
+		}
+		request.Result = strings.ToUpper(request.Result)
+		return c.ReconciliationUseCase.Report(ctx, request)
+	case "audit verify":
+		if len(rest) != 0 {
+			return nil, ErrUsage
+		}
+		return c.AuditUseCase.Verify(ctx)
+	default:
+		return nil, ErrUsage
+	}
+}
+
+// reconcile streams a settlement file into a reconciliation run
+func (c *AdminCommand) reconcile(ctx context.Context, args []string) (any, error) {
+	var path, date string
+	flags := newFlagSet("reconcile")
+	flags.StringVar(&path, "file", "", "settlement file")
+	flags.StringVar(&date, "date", "", "settlement date (YYYY-MM-DD)")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 || path == "" {
+		return nil, ErrUsage
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return c.ReconciliationUseCase.Reconcile(ctx, &model.ReconcileRequest{
+		FileName:       filepath.Base(path),
+		SettlementDate: date,
+	}, file)
+}
+
+// readPin reads the PIN from the first line of In rather than a flag, which would leave it in
+// the shell history and the process list. On a terminal it prompts on stderr and turns echo off.
+func (c *AdminCommand) readPin() (string, error) {
+	if file, ok := c.In.(*os.File); ok {
+		if restore, ok := disableEcho(file); ok {
+			fmt.Fprint(os.Stderr, "PIN: ")
+			defer fmt.Fprintln(os.Stderr)
+			defer restore()
+		}
+	}
+
+	line, err := bufio.NewReader(c.In).ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", fmt.Errorf("read pin: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+func (c *AdminCommand) print(result any) error {
+	if c.JSON {
+		return c.printJSON(model.ApiResponse{Status: "success", Data: result})
+	}
+
+	return c.printFields(reflect.ValueOf(result), "")
+}
+
+// printFields prints a struct as "json_name: value" lines, skipping empty omitempty fields.
+// Nested structs are indented under their name and slices print one line per element.
+func (c *AdminCommand) printFields(value reflect.Value, indent string) error {
+	value = reflect.Indirect(value)
+	for i := 0; i < value.NumField(); i++ {
+		name, options, _ := strings.Cut(value.Type().Field(i).Tag.Get("json"), ",")
+		field := value.Field(i)
+		if options == "omitempty" && field.IsZero() {
+			continue
+		}
+
+		var err error
+		switch reflect.Indirect(field).Kind() {
+		case reflect.Struct:
+			if _, err = fmt.Fprintf(c.Out, "%s%s:\n", indent, name); err == nil {
+				err = c.printFields(field, indent+"  ")
+			}
+		case reflect.Slice:
+			if _, err = fmt.Fprintf(c.Out, "%s%s:\n", indent, name); err == nil {
+				err = c.printElements(field, indent+"  ")
+			}
+		default:
+			_, err = fmt.Fprintf(c.Out, "%s%s: %v\n", indent, name, reflect.Indirect(field))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printElements prints each element of a slice on one "- key=value ..." line
+func (c *AdminCommand) printElements(slice reflect.Value, indent string) error {
+	for i := 0; i < slice.Len(); i++ {
+		element := reflect.Indirect(slice.Index(i))
+		if element.Kind() != reflect.Struct {
+			if _, err := fmt.Fprintf(c.Out, "%s- %v\n", indent, element); err != nil {
+				return err
+			}
+			continue
+		}
+
+		pairs := make([]string, 0, element.NumField())
+		for j := 0; j < element.NumField(); j++ {
+			name, options, _ := strings.Cut(element.Type().Field(j).Tag.Get("json"), ",")
+			field := element.Field(j)
+			if options == "omitempty" && field.IsZero() {
+				continue
+			}
+			pairs = append(pairs, fmt.Sprintf("%s=%v", name, reflect.Indirect(field)))
+		}
+		if _, err := fmt.Fprintf(c.Out, "%s- %s\n", indent, strings.Join(pairs, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printError prints use case errors by code; other errors, such as an unreadable file, are
+// local to the operator and printed as they are
+func (c *AdminCommand) printError(err error) {
+	code, message := model.ErrCodeInternal, err.Error()
+	var appErr *model.Error
+	if errors.As(err, &appErr) {
+		code, message = appErr.Code, appErr.Message(model.LanguageEnglish)
+	}
+
+	if c.JSON {
+		c.printJSON(model.ApiResponse{
+			Status: "error",
+			Code:   string(code),
+			Errors: message,
+		})
+		return
+	}
+	fmt.Fprintf(c.Out, "error: %s (%s)\n", message, code)
+}
+
+func (c *AdminCommand) printJSON(response model.ApiResponse) error {
+	encoder := json.NewEncoder(c.Out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(response)
+}
@@ -0,0 +1,25 @@
+//go:build linux
+
+package cli
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// disableEcho turns off echo when file is a terminal. ok is false for pipes and files.
+func disableEcho(file *os.File) (restore func(), ok bool) {
+	fd := int(file.Fd())
+	termios, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return nil, false
+	}
+
+	previous := *termios
+	termios.Lflag &^= unix.ECHO
+	if err := unix.IoctlSetTermios(fd, unix.TCSETS, termios); err != nil {
+		return nil, false
+	}
+	return func() { unix.IoctlSetTermios(fd, unix.TCSETS, &previous) }, true
+}
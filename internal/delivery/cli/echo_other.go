@@ -0,0 +1,10 @@
+//go:build !linux
+
+package cli
+
+import "os"
+
+// disableEcho is only implemented on Linux, elsewhere the PIN is read as typed
+func disableEcho(file *os.File) (restore func(), ok bool) {
+	return nil, false
+}
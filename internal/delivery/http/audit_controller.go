@@ -0,0 +1,66 @@
+package http
+
+import (
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type AuditController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.AuditUseCase
+}
+
+func NewAuditController(useCase *usecase.AuditUseCase, logger *logrus.Logger) *AuditController {
+	return &AuditController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Search godoc
+// @Summary Search Audit Events
+// @Description Page through the audit trail of state-changing operations, newest first, with the before and after snapshots and the chained hashes
+// @Tags Admin
+// @Produce json
+// @Param actor query string false "Client ID, admin CLI operator or system"
+// @Param action query string false "e.g. payment.created, account.pin_failed"
+// @Param target_type query string false "e.g. transaction, account, list_entry"
+// @Param target_id query string false "Target ID"
+// @Param from query string false "Created at or after (RFC 3339, any offset; audit times are UTC)"
+// @Param to query string false "Created before (RFC 3339, any offset; audit times are UTC)"
+// @Param page query int false "Page (default 1)"
+// @Param size query int false "Page size (default 100, max 1000)"
+// @Param X-Client-Key header string true "Client Key"
+// @Param X-Timestamp header string true "Request Timestamp (ISO8601)"
+// @Param X-Signature header string true "HMAC-SHA256 Signature"
+// @Success 200 {object} model.ApiResponse
+// @Failure 400 {object} model.ApiResponse
+// @Failure 401 {object} model.ApiResponse
+// @Failure 403 {object} model.ApiResponse
+// @Router /api/admin/audit-events [get]
+func (c *AuditController) Search(ctx *fiber.Ctx) error {
+	request := &model.SearchAuditEventsRequest{
+		Actor:      ctx.Query("actor"),
+		Action:     ctx.Query("action"),
+		TargetType: ctx.Query("target_type"),
+		TargetID:   ctx.Query("target_id"),
+		From:       ctx.Query("from"),
+		To:         ctx.Query("to"),
+		Page:       ctx.QueryInt("page", 1),
+		Size:       ctx.QueryInt("size", 100),
+	}
+
+	response, err := c.UseCase.Search(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to search audit events: %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.ApiResponse{
+		Status: "success",
+		Data:   response,
+	})
+}
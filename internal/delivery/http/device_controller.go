@@ -0,0 +1,92 @@
+package http
+
+import (
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type DeviceController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.AdminUseCase
+}
+
+func NewDeviceController(useCase *usecase.AdminUseCase, logger *logrus.Logger) *DeviceController {
+	return &DeviceController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Register godoc
+// @Summary Register Customer Device
+// @Description Bind a customer device and its Ed25519 public key to the account, once the customer is verified out of band
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param account_id path string true "Account ID"
+// @Param X-Client-Key header string true "Client Key"
+// @Param X-Timestamp header string true "Request Timestamp (ISO8601)"
+// @Param X-Signature header string true "HMAC-SHA256 Signature"
+// @Param request body model.RegisterDeviceRequest true "Device"
+// @Success 200 {object} model.ApiResponse
+// @Failure 400 {object} model.ApiResponse
+// @Failure 401 {object} model.ApiResponse
+// @Failure 403 {object} model.ApiResponse
+// @Failure 404 {object} model.ApiResponse
+// @Failure 409 {object} model.ApiResponse
+// @Router /api/admin/accounts/{account_id}/devices [post]
+func (c *DeviceController) Register(ctx *fiber.Ctx) error {
+	request := new(model.RegisterDeviceRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse device request body: %+v", err)
+		return model.NewError(model.ErrCodeBadRequest)
+	}
+	request.AccountID = ctx.Params("account_id")
+
+	response, err := c.UseCase.RegisterDevice(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to register device: %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.ApiResponse{
+		Status: "success",
+		Data:   response,
+	})
+}
+
+// Revoke godoc
+// @Summary Revoke Customer Device
+// @Description Unbind a customer device, e.g. a lost phone; payments it signs are refused
+// @Tags Admin
+// @Produce json
+// @Param account_id path string true "Account ID"
+// @Param device_id path string true "Device ID"
+// @Param X-Client-Key header string true "Client Key"
+// @Param X-Timestamp header string true "Request Timestamp (ISO8601)"
+// @Param X-Signature header string true "HMAC-SHA256 Signature"
+// @Success 200 {object} model.ApiResponse
+// @Failure 401 {object} model.ApiResponse
+// @Failure 403 {object} model.ApiResponse
+// @Failure 404 {object} model.ApiResponse
+// @Router /api/admin/accounts/{account_id}/devices/{device_id} [delete]
+func (c *DeviceController) Revoke(ctx *fiber.Ctx) error {
+	request := &model.RevokeDeviceRequest{
+		AccountID: ctx.Params("account_id"),
+		DeviceID:  ctx.Params("device_id"),
+	}
+
+	response, err := c.UseCase.RevokeDevice(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to revoke device: %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.ApiResponse{
+		Status: "success",
+		Data:   response,
+	})
+}
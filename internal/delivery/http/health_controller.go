@@ -0,0 +1,49 @@
+package http
+
+import (
+	"golang-clean-architecture/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type HealthController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.HealthUseCase
+}
+
+func NewHealthController(useCase *usecase.HealthUseCase, logger *logrus.Logger) *HealthController {
+	return &HealthController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Livez godoc
+// @Summary Liveness Probe
+// @Description Report that the process is running; never checks dependencies
+// @Tags System
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /livez [get]
+func (c *HealthController) Livez(ctx *fiber.Ctx) error {
+	return ctx.JSON(fiber.Map{
+		"status": "ok",
+	})
+}
+
+// Readyz godoc
+// @Summary Readiness Probe
+// @Description Ping Postgres and Redis, verify the schema migration version and report per-dependency status and latency
+// @Tags System
+// @Produce json
+// @Success 200 {object} model.ReadinessResponse
+// @Failure 503 {object} model.ReadinessResponse
+// @Router /readyz [get]
+func (c *HealthController) Readyz(ctx *fiber.Ctx) error {
+	response := c.UseCase.Readiness(ctx.UserContext())
+	if response.Status != usecase.ReadinessStatusReady {
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(response)
+	}
+	return ctx.JSON(response)
+}
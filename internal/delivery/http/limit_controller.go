@@ -0,0 +1,56 @@
+package http
+
+import (
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type LimitController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.LimitUseCase
+}
+
+func NewLimitController(useCase *usecase.LimitUseCase, logger *logrus.Logger) *LimitController {
+	return &LimitController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Remaining godoc
+// @Summary Account Spending Limits
+// @Description Limits of the account's tier and what is left today, this month and this hour
+// @Tags Account
+// @Produce json
+// @Param account_id path string true "Account ID"
+// @Param X-Client-Key header string true "Client Key"
+// @Param X-Timestamp header string true "Request Timestamp (ISO8601)"
+// @Param X-Signature header string true "HMAC-SHA256 Signature"
+// @Success 200 {object} model.ApiResponse
+// @Failure 401 {object} model.ApiResponse
+// @Failure 404 {object} model.ApiResponse
+// @Router /api/accounts/{account_id}/limits [get]
+func (c *LimitController) Remaining(ctx *fiber.Ctx) error {
+	// Limits are off; there is nothing to report
+	if c.UseCase == nil {
+		return model.NewError(model.ErrCodeNotFound)
+	}
+
+	request := &model.AccountLimitsRequest{
+		AccountID: ctx.Params("account_id"),
+	}
+
+	response, err := c.UseCase.Remaining(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to get account limits: %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.ApiResponse{
+		Status: "success",
+		Data:   response,
+	})
+}
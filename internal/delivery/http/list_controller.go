@@ -0,0 +1,206 @@
+package http
+
+import (
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ListController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.ListUseCase
+}
+
+func NewListController(useCase *usecase.ListUseCase, logger *logrus.Logger) *ListController {
+	return &ListController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Create godoc
+// @Summary Add List Entry
+// @Description Blacklist or whitelist a merchant ID, account ID, NMID or QR hash (hex SHA-256 of the QRIS payload), optionally until expires_at. The calling admin client is recorded.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Client-Key header string true "Client Key"
+// @Param X-Timestamp header string true "Request Timestamp (ISO8601)"
+// @Param X-Signature header string true "HMAC-SHA256 Signature"
+// @Param request body model.CreateListEntryRequest true "List entry"
+// @Success 200 {object} model.ApiResponse
+// @Failure 400 {object} model.ApiResponse
+// @Failure 401 {object} model.ApiResponse
+// @Failure 403 {object} model.ApiResponse
+// @Failure 409 {object} model.ApiResponse
+// @Router /api/admin/lists [post]
+func (c *ListController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateListEntryRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse list entry request body: %+v", err)
+		return model.NewError(model.ErrCodeBadRequest)
+	}
+	request.Actor, _ = ctx.Locals("client_id").(string)
+
+	response, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to create list entry: %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.ApiResponse{
+		Status: "success",
+		Data:   response,
+	})
+}
+
+// Search godoc
+// @Summary Search List Entries
+// @Description Page through blacklist and whitelist entries, newest first
+// @Tags Admin
+// @Produce json
+// @Param list query string false "BLACKLIST or WHITELIST"
+// @Param entry_type query string false "MERCHANT_ID, ACCOUNT_ID, NMID or QR_HASH"
+// @Param value query string false "Exact value"
+// @Param include_removed query bool false "Include removed entries"
+// @Param page query int false "Page (default 1)"
+// @Param size query int false "Page size (default 100, max 1000)"
+// @Param X-Client-Key header string true "Client Key"
+// @Param X-Timestamp header string true "Request Timestamp (ISO8601)"
+// @Param X-Signature header string true "HMAC-SHA256 Signature"
+// @Success 200 {object} model.ApiResponse
+// @Failure 400 {object} model.ApiResponse
+// @Failure 401 {object} model.ApiResponse
+// @Failure 403 {object} model.ApiResponse
+// @Router /api/admin/lists [get]
+func (c *ListController) Search(ctx *fiber.Ctx) error {
+	request := &model.SearchListEntriesRequest{
+		List:           ctx.Query("list"),
+		EntryType:      ctx.Query("entry_type"),
+		Value:          ctx.Query("value"),
+		IncludeRemoved: ctx.QueryBool("include_removed"),
+		Page:           ctx.QueryInt("page", 1),
+		Size:           ctx.QueryInt("size", 100),
+	}
+
+	response, err := c.UseCase.Search(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to search list entries: %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.ApiResponse{
+		Status: "success",
+		Data:   response,
+	})
+}
+
+// Get godoc
+// @Summary Get List Entry
+// @Description Get a blacklist or whitelist entry with its audit trail
+// @Tags Admin
+// @Produce json
+// @Param entry_id path int true "Entry ID"
+// @Param X-Client-Key header string true "Client Key"
+// @Param X-Timestamp header string true "Request Timestamp (ISO8601)"
+// @Param X-Signature header string true "HMAC-SHA256 Signature"
+// @Success 200 {object} model.ApiResponse
+// @Failure 401 {object} model.ApiResponse
+// @Failure 403 {object} model.ApiResponse
+// @Failure 404 {object} model.ApiResponse
+// @Router /api/admin/lists/{entry_id} [get]
+func (c *ListController) Get(ctx *fiber.Ctx) error {
+	entryID, err := ctx.ParamsInt("entry_id")
+	if err != nil {
+		return model.NewError(model.ErrCodeListEntryNotFound)
+	}
+
+	response, err := c.UseCase.Get(ctx.UserContext(), int64(entryID))
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to get list entry: %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.ApiResponse{
+		Status: "success",
+		Data:   response,
+	})
+}
+
+// Update godoc
+// @Summary Update List Entry
+// @Description Change the reason or the expiry of a live entry; an empty expires_at makes it permanent
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param entry_id path int true "Entry ID"
+// @Param X-Client-Key header string true "Client Key"
+// @Param X-Timestamp header string true "Request Timestamp (ISO8601)"
+// @Param X-Signature header string true "HMAC-SHA256 Signature"
+// @Param request body model.UpdateListEntryRequest true "List entry"
+// @Success 200 {object} model.ApiResponse
+// @Failure 400 {object} model.ApiResponse
+// @Failure 401 {object} model.ApiResponse
+// @Failure 403 {object} model.ApiResponse
+// @Failure 404 {object} model.ApiResponse
+// @Router /api/admin/lists/{entry_id} [patch]
+func (c *ListController) Update(ctx *fiber.Ctx) error {
+	request := new(model.UpdateListEntryRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse list entry request body: %+v", err)
+		return model.NewError(model.ErrCodeBadRequest)
+	}
+	entryID, err := ctx.ParamsInt("entry_id")
+	if err != nil {
+		return model.NewError(model.ErrCodeListEntryNotFound)
+	}
+	request.EntryID = int64(entryID)
+	request.Actor, _ = ctx.Locals("client_id").(string)
+
+	response, err := c.UseCase.Update(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to update list entry: %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.ApiResponse{
+		Status: "success",
+		Data:   response,
+	})
+}
+
+// Remove godoc
+// @Summary Remove List Entry
+// @Description Take an entry off its list. The entry is kept with the admin client that removed it.
+// @Tags Admin
+// @Produce json
+// @Param entry_id path int true "Entry ID"
+// @Param X-Client-Key header string true "Client Key"
+// @Param X-Timestamp header string true "Request Timestamp (ISO8601)"
+// @Param X-Signature header string true "HMAC-SHA256 Signature"
+// @Success 200 {object} model.ApiResponse
+// @Failure 401 {object} model.ApiResponse
+// @Failure 403 {object} model.ApiResponse
+// @Failure 404 {object} model.ApiResponse
+// @Router /api/admin/lists/{entry_id} [delete]
+func (c *ListController) Remove(ctx *fiber.Ctx) error {
+	entryID, err := ctx.ParamsInt("entry_id")
+	if err != nil {
+		return model.NewError(model.ErrCodeListEntryNotFound)
+	}
+	request := &model.RemoveListEntryRequest{EntryID: int64(entryID)}
+	request.Actor, _ = ctx.Locals("client_id").(string)
+
+	response, err := c.UseCase.Remove(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to remove list entry: %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.ApiResponse{
+		Status: "success",
+		Data:   response,
+	})
+}
@@ -0,0 +1,29 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type MetricsController struct {
+	Handler fiber.Handler
+}
+
+func NewMetricsController(gatherer prometheus.Gatherer) *MetricsController {
+	return &MetricsController{
+		Handler: adaptor.HTTPHandler(promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})),
+	}
+}
+
+// Scrape godoc
+// @Summary Prometheus Metrics
+// @Description Expose request, business and runtime metrics in the Prometheus text format
+// @Tags System
+// @Produce plain
+// @Success 200 {string} string
+// @Router /metrics [get]
+func (c *MetricsController) Scrape(ctx *fiber.Ctx) error {
+	return c.Handler(ctx)
+}
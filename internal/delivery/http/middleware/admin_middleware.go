@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"slices"
+
+	"golang-clean-architecture/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// NewAdminOnly lets through only the API clients allowed to administer the service. It must run
+// after the HMAC authentication, which identifies the client.
+func NewAdminOnly(clientIDs []string, log *logrus.Logger) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		clientID, _ := ctx.Locals("client_id").(string)
+		if clientID == "" || !slices.Contains(clientIDs, clientID) {
+			log.WithContext(ctx.UserContext()).Warnf("Client %s is not allowed to use admin endpoints", clientID)
+			return model.NewError(model.ErrCodeForbidden)
+		}
+		return ctx.Next()
+	}
+}
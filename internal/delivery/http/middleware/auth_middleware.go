@@ -21,21 +21,15 @@ func NewHMACAuth(db *gorm.DB, apiClientRepo *repository.ApiClientRepository, log
 		signature := ctx.Get("X-Signature")
 
 		if clientKey == "" || timestamp == "" || signature == "" {
-			log.Warn("Missing required auth headers")
-			return ctx.Status(fiber.StatusUnauthorized).JSON(model.ApiResponse{
-				Status: "error",
-				Errors: "Missing required headers: X-Client-Key, X-Timestamp, X-Signature",
-			})
+			log.WithContext(ctx.UserContext()).Warn("Missing required auth headers")
+			return model.NewError(model.ErrCodeMissingAuthHeaders)
 		}
 
 		// Look up client
 		client := new(entity.ApiClient)
-		if err := apiClientRepo.FindByClientID(db, client, clientKey); err != nil {
-			log.Warnf("Invalid client key: %s, error: %+v", clientKey, err)
-			return ctx.Status(fiber.StatusUnauthorized).JSON(model.ApiResponse{
-				Status: "error",
-				Errors: "Invalid client key",
-			})
+		if err := apiClientRepo.FindByClientID(db.WithContext(ctx.UserContext()), client, clientKey); err != nil {
+			log.WithContext(ctx.UserContext()).Warnf("Invalid client key: %s, error: %+v", clientKey, err)
+			return model.NewError(model.ErrCodeInvalidClientKey)
 		}
 
 		// Build payload: method + path + timestamp + body
@@ -48,15 +42,15 @@ func NewHMACAuth(db *gorm.DB, apiClientRepo *repository.ApiClientRepository, log
 		expectedSignature := hex.EncodeToString(mac.Sum(nil))
 
 		if !hmac.Equal([]byte(signature), []byte(expectedSignature)) {
-			log.Warnf("Invalid HMAC signature for client: %s", clientKey)
-			return ctx.Status(fiber.StatusUnauthorized).JSON(model.ApiResponse{
-				Status: "error",
-				Errors: "Invalid signature",
-			})
+			log.WithContext(ctx.UserContext()).Warnf("Invalid HMAC signature for client: %s", clientKey)
+			return model.NewError(model.ErrCodeInvalidSignature)
 		}
 
-		log.Debugf("Authenticated client: %s", clientKey)
+		log.WithContext(ctx.UserContext()).Debugf("Authenticated client: %s", clientKey)
 		ctx.Locals("client_id", clientKey)
+		if requestContext, ok := model.RequestContextFrom(ctx.UserContext()); ok {
+			requestContext.Actor = clientKey
+		}
 		return ctx.Next()
 	}
 }
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"golang-clean-architecture/internal/metrics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NewMetrics records request latency per route pattern and final response status
+func NewMetrics(m *metrics.Metrics) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		start := time.Now()
+
+		// Render errors here so the observed status is the one sent to the client
+		if err := ctx.Next(); err != nil {
+			if err := ctx.App().ErrorHandler(ctx, err); err != nil {
+				_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		m.HTTPRequestDuration.
+			WithLabelValues(ctx.Method(), ctx.Route().Path, strconv.Itoa(ctx.Response().StatusCode())).
+			Observe(time.Since(start).Seconds())
+		return nil
+	}
+}
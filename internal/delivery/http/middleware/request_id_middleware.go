@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"golang-clean-architecture/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	HeaderRequestID   = "X-Request-ID"
+	HeaderTraceParent = "traceparent"
+)
+
+var (
+	requestIDPattern   = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+	traceParentPattern = regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+)
+
+// NewRequestID accepts or generates X-Request-ID and a W3C traceparent, stores them in the
+// request context for logging and persistence, and echoes them in the response headers.
+// When a tracing middleware already started a server span its trace and span IDs are reused.
+func NewRequestID() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		requestID := ctx.Get(HeaderRequestID)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		requestContext := &model.RequestContext{RequestID: requestID}
+		flags := "01"
+		if spanContext := trace.SpanContextFromContext(ctx.UserContext()); spanContext.IsValid() {
+			requestContext.TraceID = spanContext.TraceID().String()
+			requestContext.SpanID = spanContext.SpanID().String()
+			flags = spanContext.TraceFlags().String()
+		} else if match := traceParentPattern.FindStringSubmatch(strings.ToLower(ctx.Get(HeaderTraceParent))); match != nil && match[1] != strings.Repeat("0", 32) {
+			requestContext.TraceID, requestContext.SpanID, flags = match[1], randomHex(8), match[3]
+		} else {
+			requestContext.TraceID, requestContext.SpanID = randomHex(16), randomHex(8)
+		}
+		ctx.SetUserContext(model.WithRequestContext(ctx.UserContext(), requestContext))
+		ctx.Locals("request_id", requestID)
+
+		ctx.Set(HeaderRequestID, requestID)
+		ctx.Set(HeaderTraceParent, "00-"+requestContext.TraceID+"-"+requestContext.SpanID+"-"+flags)
+
+		return ctx.Next()
+	}
+}
+
+func randomHex(size int) string {
+	buf := make([]byte, size)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -27,6 +27,7 @@ func NewQrisController(useCase *usecase.QrisUseCase, logger *logrus.Logger) *Qri
 // @Accept json
 // @Produce json
 // @Param qris_payload path string true "QRIS Payload string"
+// @Param currency query string false "Account currency to quote cross-border QRs in (ISO 4217, default home currency)"
 // @Param X-Client-Key header string true "Client Key"
 // @Param X-Timestamp header string true "Request Timestamp (ISO8601)"
 // @Param X-Signature header string true "HMAC-SHA256 Signature"
@@ -37,15 +38,12 @@ func NewQrisController(useCase *usecase.QrisUseCase, logger *logrus.Logger) *Qri
 func (c *QrisController) Inquiry(ctx *fiber.Ctx) error {
 	qrisPayload := ctx.Params("qris_payload")
 	if qrisPayload == "" {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.ApiResponse{
-			Status: "error",
-			Errors: "QRIS payload is required",
-		})
+		return model.NewError(model.ErrCodeQrisPayloadRequired)
 	}
 
-	response, metadata, err := c.UseCase.Inquiry(ctx.UserContext(), qrisPayload)
+	response, metadata, err := c.UseCase.Inquiry(ctx.UserContext(), qrisPayload, ctx.Query("currency"))
 	if err != nil {
-		c.Log.Warnf("Failed to process QRIS inquiry: %+v", err)
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to process QRIS inquiry: %+v", err)
 		return err
 	}
 
@@ -74,16 +72,49 @@ func (c *QrisController) Inquiry(ctx *fiber.Ctx) error {
 func (c *QrisController) Payment(ctx *fiber.Ctx) error {
 	request := new(model.PaymentRequest)
 	if err := ctx.BodyParser(request); err != nil {
-		c.Log.Warnf("Failed to parse payment request body: %+v", err)
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.ApiResponse{
-			Status: "error",
-			Errors: "Invalid request body",
-		})
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse payment request body: %+v", err)
+		return model.NewError(model.ErrCodeBadRequest)
 	}
 
 	response, err := c.UseCase.Payment(ctx.UserContext(), request)
 	if err != nil {
-		c.Log.Warnf("Failed to process payment: %+v", err)
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to process payment: %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.ApiResponse{
+		Status: "success",
+		Data:   response,
+	})
+}
+
+// VerifyChallenge godoc
+// @Summary Verify Payment Challenge
+// @Description Answer the step-up challenge of a high-value or flagged payment with a TOTP or the OTP sent, completing the payment
+// @Tags QRIS
+// @Accept json
+// @Produce json
+// @Param challenge_id path string true "Challenge ID"
+// @Param X-Client-Key header string true "Client Key"
+// @Param X-Timestamp header string true "Request Timestamp (ISO8601)"
+// @Param X-Signature header string true "HMAC-SHA256 Signature"
+// @Param request body model.VerifyChallengeRequest true "Verification Code"
+// @Success 200 {object} model.ApiResponse
+// @Failure 400 {object} model.ApiResponse
+// @Failure 401 {object} model.ApiResponse
+// @Failure 423 {object} model.ApiResponse
+// @Router /api/qris/payment/{challenge_id}/verify [post]
+func (c *QrisController) VerifyChallenge(ctx *fiber.Ctx) error {
+	request := new(model.VerifyChallengeRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse challenge request body: %+v", err)
+		return model.NewError(model.ErrCodeBadRequest)
+	}
+	request.ChallengeID = ctx.Params("challenge_id")
+
+	response, err := c.UseCase.VerifyChallenge(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to verify payment challenge: %+v", err)
 		return err
 	}
 
@@ -0,0 +1,125 @@
+package http_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-clean-architecture/internal/config"
+	"golang-clean-architecture/internal/delivery/http"
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/repository"
+	"golang-clean-architecture/internal/usecase"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newPaymentApp serves the payment endpoint for account user_123 with PIN 123456. The database
+// runs dry and answers every account lookup with that account; writes fail.
+func newPaymentApp(t *testing.T) *fiber.App {
+	t.Helper()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	pinHash, err := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash pin: %v", err)
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry run database: %v", err)
+	}
+	db.Callback().Query().Replace("gorm:query", func(db *gorm.DB) {
+		if account, ok := db.Statement.Dest.(*entity.Account); ok {
+			*account = entity.Account{AccountID: "user_123", PinHash: string(pinHash), Balance: 5000000, Currency: "IDR"}
+			db.RowsAffected = 1
+		}
+	})
+
+	server := miniredis.RunT(t)
+	server.Set("inquiry:inq_1", `{"merchant_id":"MERCH_001"}`)
+	server.SetTTL("inquiry:inq_1", 5*time.Minute)
+
+	accountRepository := repository.NewAccountRepository(log)
+	transactionRepository := repository.NewTransactionRepository(log)
+	audit := usecase.NewAuditUseCase(db, log, validator.New(), repository.NewAuditEventRepository(log), 1)
+	// The hot account path reads the account without opening a database transaction
+	batcher := usecase.NewHotAccountBatcher(db, log, accountRepository, transactionRepository, audit, []string{"user_123"}, 10, time.Millisecond)
+	t.Cleanup(batcher.Stop)
+
+	useCase := &usecase.QrisUseCase{
+		DB:                    db,
+		Log:                   log,
+		Validate:              validator.New(),
+		RedisClient:           redis.NewClient(&redis.Options{Addr: server.Addr()}),
+		AccountRepository:     accountRepository,
+		TransactionRepository: transactionRepository,
+		HotAccountBatcher:     batcher,
+		Fx:                    usecase.FxConfig{HomeCurrency: "IDR"},
+		Audit:                 audit,
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: config.NewErrorHandler()})
+	app.Post("/api/qris/payment", http.NewQrisController(useCase, log).Payment)
+	return app
+}
+
+// TestPaymentFormBodyCannotSkipPin posts form bodies, which Fiber binds by field name too:
+// none of them may mark the payment as completing a verified step-up challenge
+func TestPaymentFormBodyCannotSkipPin(t *testing.T) {
+	app := newPaymentApp(t)
+
+	tests := []struct {
+		name  string
+		extra url.Values
+	}{
+		{name: "plain", extra: url.Values{}},
+		{name: "challenge id", extra: url.Values{"ChallengeID": {"x"}}},
+		{name: "verified", extra: url.Values{"Verified": {"true"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{
+				"InquiryID":     {"inq_1"},
+				"UserID":        {"user_123"},
+				"Amount":        {"10000"},
+				"PaymentMethod": {"balance"},
+				"Pincode":       {"000000"},
+			}
+			for key, values := range tt.extra {
+				form[key] = values
+			}
+
+			request := httptest.NewRequest(fiber.MethodPost, "/api/qris/payment", strings.NewReader(form.Encode()))
+			request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+			response, err := app.Test(request, -1)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			defer response.Body.Close()
+
+			body := new(model.ApiResponse)
+			if err := json.NewDecoder(response.Body).Decode(body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if response.StatusCode != fiber.StatusUnauthorized || body.Code != string(model.ErrCodeInvalidPIN) {
+				t.Fatalf("got %d %s, want %d %s", response.StatusCode, body.Code, fiber.StatusUnauthorized, model.ErrCodeInvalidPIN)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ReconciliationController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.ReconciliationUseCase
+}
+
+func NewReconciliationController(useCase *usecase.ReconciliationUseCase, logger *logrus.Logger) *ReconciliationController {
+	return &ReconciliationController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Report godoc
+// @Summary Reconciliation Report
+// @Description Summary of a settlement file reconciliation run with a page of its classified items
+// @Tags Reconciliation
+// @Produce json
+// @Param run_id path string true "Reconciliation run ID (UUID)"
+// @Param result query string false "MATCHED, AMOUNT_MISMATCH, MISSING_ON_OUR_SIDE, DUPLICATE or UNMATCHED"
+// @Param page query int false "Page number (default 1)"
+// @Param size query int false "Page size, at most 1000 (default 100)"
+// @Param X-Client-Key header string true "Client Key"
+// @Param X-Timestamp header string true "Request Timestamp (ISO8601)"
+// @Param X-Signature header string true "HMAC-SHA256 Signature"
+// @Success 200 {object} model.ApiResponse
+// @Failure 400 {object} model.ApiResponse
+// @Failure 401 {object} model.ApiResponse
+// @Failure 403 {object} model.ApiResponse
+// @Failure 404 {object} model.ApiResponse
+// @Router /api/admin/reconciliations/{run_id} [get]
+func (c *ReconciliationController) Report(ctx *fiber.Ctx) error {
+	request := &model.ReconciliationReportRequest{
+		RunID:  ctx.Params("run_id"),
+		Result: ctx.Query("result"),
+		Page:   ctx.QueryInt("page", 1),
+		Size:   ctx.QueryInt("size", 100),
+	}
+
+	response, err := c.UseCase.Report(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to get reconciliation report: %+v", err)
+		return err
+	}
+
+	return ctx.JSON(model.ApiResponse{
+		Status: "success",
+		Data:   response,
+	})
+}
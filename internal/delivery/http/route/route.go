@@ -7,13 +7,30 @@ import (
 )
 
 type RouteConfig struct {
-	App                   *fiber.App
-	QrisController        *http.QrisController
-	TransactionController *http.TransactionController
-	HMACMiddleware        fiber.Handler
+	App                      *fiber.App
+	QrisController           *http.QrisController
+	TransactionController    *http.TransactionController
+	ReportController         *http.ReportController
+	ReconciliationController *http.ReconciliationController
+	LimitController          *http.LimitController
+	DeviceController         *http.DeviceController
+	ListController           *http.ListController
+	AuditController          *http.AuditController
+	MetricsController        *http.MetricsController
+	HealthController         *http.HealthController
+	HMACMiddleware           fiber.Handler
+	AdminMiddleware          fiber.Handler
+	RequestIDMiddleware      fiber.Handler
+	TracingMiddleware        fiber.Handler
+	MetricsMiddleware        fiber.Handler
 }
 
 func (c *RouteConfig) Setup() {
+	// Tracing, request correlation and metrics for every route
+	c.App.Use(c.TracingMiddleware)
+	c.App.Use(c.RequestIDMiddleware)
+	c.App.Use(c.MetricsMiddleware)
+
 	// Health check (no auth required)
 	c.App.Get("/health", func(ctx *fiber.Ctx) error {
 		return ctx.JSON(fiber.Map{
@@ -21,13 +38,37 @@ func (c *RouteConfig) Setup() {
 		})
 	})
 
+	// Kubernetes probes (no auth required)
+	c.App.Get("/livez", c.HealthController.Livez)
+	c.App.Get("/readyz", c.HealthController.Readyz)
+
+	// Prometheus scrape endpoint (no auth required)
+	c.App.Get("/metrics", c.MetricsController.Scrape)
+
 	// API routes with HMAC signature authentication
 	api := c.App.Group("/api", c.HMACMiddleware)
 
 	// QRIS endpoints
 	api.Get("/qris/inquiry/:qris_payload", c.QrisController.Inquiry)
 	api.Post("/qris/payment", c.QrisController.Payment)
+	api.Post("/qris/payment/:challenge_id/verify", c.QrisController.VerifyChallenge)
 
 	// Transaction endpoints
 	api.Get("/transaction/status/:transaction_id", c.TransactionController.GetStatus)
+
+	// Account spending limit endpoints
+	api.Get("/accounts/:account_id/limits", c.LimitController.Remaining)
+
+	// Admin endpoints, restricted to the configured admin clients
+	admin := api.Group("/admin", c.AdminMiddleware)
+	admin.Post("/lists", c.ListController.Create)
+	admin.Get("/lists", c.ListController.Search)
+	admin.Get("/lists/:entry_id", c.ListController.Get)
+	admin.Patch("/lists/:entry_id", c.ListController.Update)
+	admin.Delete("/lists/:entry_id", c.ListController.Remove)
+	admin.Post("/accounts/:account_id/devices", c.DeviceController.Register)
+	admin.Delete("/accounts/:account_id/devices/:device_id", c.DeviceController.Revoke)
+	admin.Get("/audit-events", c.AuditController.Search)
+	admin.Get("/merchants/:merchant_id/reports/sales", c.ReportController.SalesReport)
+	admin.Get("/reconciliations/:run_id", c.ReconciliationController.Report)
 }
@@ -37,15 +37,12 @@ func NewTransactionController(useCase *usecase.TransactionUseCase, logger *logru
 func (c *TransactionController) GetStatus(ctx *fiber.Ctx) error {
 	transactionID := ctx.Params("transaction_id")
 	if transactionID == "" {
-		return ctx.Status(fiber.StatusBadRequest).JSON(model.ApiResponse{
-			Status: "error",
-			Errors: "Transaction ID is required",
-		})
+		return model.NewError(model.ErrCodeTransactionIDRequired)
 	}
 
 	response, err := c.UseCase.GetStatus(ctx.UserContext(), transactionID)
 	if err != nil {
-		c.Log.Warnf("Failed to get transaction status: %+v", err)
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to get transaction status: %+v", err)
 		return err
 	}
 
@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireScript takes the lock when it is free and extends it when we already hold it
+var acquireScript = redis.NewScript(`
+local owner = redis.call('GET', KEYS[1])
+if owner == false then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+end
+if owner == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// releaseScript deletes the lock only when it is still held by the caller
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// LeaderLock elects a single replica to run a background job using a Redis key with a TTL.
+// If the leader dies the key expires and another replica takes over on its next attempt.
+type LeaderLock struct {
+	RedisClient *redis.Client
+	Key         string
+	ID          string
+	TTL         time.Duration
+}
+
+func NewLeaderLock(redisClient *redis.Client, key string, ttl time.Duration) *LeaderLock {
+	return &LeaderLock{
+		RedisClient: redisClient,
+		Key:         key,
+		ID:          uuid.New().String(),
+		TTL:         ttl,
+	}
+}
+
+// Acquire returns true when this instance holds the lock after the call
+func (l *LeaderLock) Acquire(ctx context.Context) (bool, error) {
+	acquired, err := acquireScript.Run(ctx, l.RedisClient, []string{l.Key}, l.ID, l.TTL.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return acquired == 1, nil
+}
+
+func (l *LeaderLock) Release(ctx context.Context) error {
+	return releaseScript.Run(ctx, l.RedisClient, []string{l.Key}, l.ID).Err()
+}
@@ -0,0 +1,112 @@
+package worker
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"golang-clean-architecture/internal/usecase"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TransactionSweeperStats holds cumulative counters since the process started
+type TransactionSweeperStats struct {
+	Runs    int64
+	Expired int64
+	Failed  int64
+	Errors  int64
+}
+
+// TransactionSweeper periodically resolves transactions stuck in PENDING.
+// Only the replica holding the leader lock performs a sweep.
+type TransactionSweeper struct {
+	Log        *logrus.Logger
+	UseCase    *usecase.TransactionUseCase
+	Lock       *LeaderLock
+	Interval   time.Duration
+	PendingTTL time.Duration
+	BatchSize  int
+
+	runs    atomic.Int64
+	expired atomic.Int64
+	failed  atomic.Int64
+	errors  atomic.Int64
+}
+
+func NewTransactionSweeper(
+	useCase *usecase.TransactionUseCase,
+	lock *LeaderLock,
+	log *logrus.Logger,
+	interval time.Duration,
+	pendingTTL time.Duration,
+	batchSize int,
+) *TransactionSweeper {
+	return &TransactionSweeper{
+		Log:        log,
+		UseCase:    useCase,
+		Lock:       lock,
+		Interval:   interval,
+		PendingTTL: pendingTTL,
+		BatchSize:  batchSize,
+	}
+}
+
+// Start runs the sweeper until ctx is cancelled
+func (w *TransactionSweeper) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	w.Log.Infof("Transaction sweeper started, interval: %s, pending ttl: %s", w.Interval, w.PendingTTL)
+	for {
+		select {
+		case <-ctx.Done():
+			if err := w.Lock.Release(context.Background()); err != nil {
+				w.Log.Warnf("Failed to release sweeper leader lock: %+v", err)
+			}
+			w.Log.Info("Transaction sweeper stopped")
+			return
+		case <-ticker.C:
+			w.sweep(ctx)
+		}
+	}
+}
+
+func (w *TransactionSweeper) sweep(ctx context.Context) {
+	leader, err := w.Lock.Acquire(ctx)
+	if err != nil {
+		w.errors.Add(1)
+		w.Log.Warnf("Failed to acquire sweeper leader lock: %+v", err)
+		return
+	}
+	if !leader {
+		return
+	}
+
+	result, err := w.UseCase.ExpirePending(ctx, w.PendingTTL, w.BatchSize)
+	w.runs.Add(1)
+	if err != nil {
+		w.errors.Add(1)
+		return
+	}
+
+	w.expired.Add(int64(result.Expired))
+	w.failed.Add(int64(result.Failed))
+
+	if result.Scanned > 0 {
+		w.Log.WithFields(logrus.Fields{
+			"scanned": result.Scanned,
+			"expired": result.Expired,
+			"failed":  result.Failed,
+		}).Info("Transaction sweeper resolved pending transactions")
+	}
+}
+
+func (w *TransactionSweeper) Stats() TransactionSweeperStats {
+	return TransactionSweeperStats{
+		Runs:    w.runs.Load(),
+		Expired: w.expired.Load(),
+		Failed:  w.failed.Load(),
+		Errors:  w.errors.Load(),
+	}
+}
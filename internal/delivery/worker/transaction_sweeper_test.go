@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"golang-clean-architecture/internal/repository"
+	"golang-clean-architecture/internal/usecase"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// TestTransactionSweeperLeaderLock sweeps only while holding the leader lock. The database runs
+// dry, so a sweep finds no pending transactions.
+func TestTransactionSweeperLeaderLock(t *testing.T) {
+	tests := []struct {
+		name  string
+		owner string
+		down  bool
+		want  TransactionSweeperStats
+		held  bool
+	}{
+		{name: "lock free", want: TransactionSweeperStats{Runs: 1}, held: true},
+		{name: "held by this replica", owner: "self", want: TransactionSweeperStats{Runs: 1}, held: true},
+		{name: "held by another replica", owner: "replica_2", want: TransactionSweeperStats{}},
+		{name: "redis down", down: true, want: TransactionSweeperStats{Errors: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+				DryRun:               true,
+				DisableAutomaticPing: true,
+			})
+			if err != nil {
+				t.Fatalf("open dry run database: %v", err)
+			}
+			log := logrus.New()
+			log.SetOutput(io.Discard)
+
+			server := miniredis.RunT(t)
+			lock := NewLeaderLock(redis.NewClient(&redis.Options{Addr: server.Addr()}), "lock:sweeper", time.Minute)
+			switch tt.owner {
+			case "":
+			case "self":
+				server.Set(lock.Key, lock.ID)
+			default:
+				server.Set(lock.Key, tt.owner)
+			}
+			server.SetTTL(lock.Key, 10*time.Second)
+			if tt.down {
+				server.Close()
+			}
+
+			useCase := usecase.NewTransactionUseCase(db, log, nil, repository.NewTransactionRepository(log), repository.NewAccountRepository(log), nil, nil)
+			sweeper := NewTransactionSweeper(useCase, lock, log, time.Minute, 15*time.Minute, 100)
+			sweeper.sweep(context.Background())
+
+			if stats := sweeper.Stats(); stats != tt.want {
+				t.Fatalf("stats %+v, want %+v", stats, tt.want)
+			}
+			if tt.down {
+				return
+			}
+
+			owner, _ := server.Get(lock.Key)
+			if held := owner == lock.ID; held != tt.held {
+				t.Fatalf("lock owned by %q after the sweep", owner)
+			}
+			// The leader extends its lease, nobody touches another replica's
+			if ttl := server.TTL(lock.Key); tt.held && ttl != time.Minute || !tt.held && ttl != 10*time.Second {
+				t.Fatalf("lock ttl %v", ttl)
+			}
+		})
+	}
+}
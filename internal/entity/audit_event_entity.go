@@ -0,0 +1,55 @@
+package entity
+
+import "time"
+
+// AuditActorSystem is the actor of events without a caller, e.g. those of background workers
+const AuditActorSystem = "system"
+
+const (
+	AuditPaymentCreated     = "payment.created"
+	AuditPaymentRejected    = "payment.rejected"
+	AuditPaymentReversed    = "payment.reversed"
+	AuditPinFailed          = "account.pin_failed"
+	AuditStepUpFailed       = "account.step_up_failed"
+	AuditAccountCreated     = "account.created"
+	AuditTransactionExpired = "transaction.expired"
+	AuditTransactionForced  = "transaction.forced"
+	AuditDeviceRegistered   = "device.registered"
+	AuditDeviceRevoked      = "device.revoked"
+	AuditListEntryCreated   = "list_entry.created"
+	AuditListEntryUpdated   = "list_entry.updated"
+	AuditListEntryRemoved   = "list_entry.removed"
+	AuditApiClientCreated   = "api_client.created"
+	AuditApiClientDisabled  = "api_client.disabled"
+	AuditMerchantCreated    = "merchant.created"
+)
+
+const (
+	AuditTargetTransaction = "transaction"
+	AuditTargetAccount     = "account"
+	AuditTargetDevice      = "device"
+	AuditTargetListEntry   = "list_entry"
+	AuditTargetApiClient   = "api_client"
+	AuditTargetMerchant    = "merchant"
+)
+
+// AuditEvent is one entry of the hash-chained audit trail, chained to the event before it in its
+// Chain. Before and After are JSON snapshots of the target, empty when there is none.
+type AuditEvent struct {
+	EventID    int64     `gorm:"column:event_id;primaryKey;autoIncrement"`
+	Chain      int16     `gorm:"column:chain"`
+	Actor      string    `gorm:"column:actor"`
+	Action     string    `gorm:"column:action"`
+	TargetType string    `gorm:"column:target_type"`
+	TargetID   string    `gorm:"column:target_id"`
+	Before     string    `gorm:"column:before_snapshot"`
+	After      string    `gorm:"column:after_snapshot"`
+	RequestID  string    `gorm:"column:request_id"`
+	PrevHash   string    `gorm:"column:prev_hash"`
+	Hash       string    `gorm:"column:hash"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
+
+func (e *AuditEvent) TableName() string {
+	return "audit_events"
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+const (
+	DeviceStatusActive  = "ACTIVE"
+	DeviceStatusRevoked = "REVOKED"
+)
+
+type Device struct {
+	DeviceID  string     `gorm:"column:device_id;primaryKey"`
+	AccountID string     `gorm:"column:account_id;primaryKey"`
+	PublicKey string     `gorm:"column:public_key"`
+	Status    string     `gorm:"column:status;default:ACTIVE"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+	RevokedAt *time.Time `gorm:"column:revoked_at"`
+}
+
+func (d *Device) TableName() string {
+	return "devices"
+}
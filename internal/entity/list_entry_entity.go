@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+const (
+	// ListBlacklist entries stop inquiries and payments
+	ListBlacklist = "BLACKLIST"
+	// ListWhitelist entries are trusted and skip risk screening
+	ListWhitelist = "WHITELIST"
+)
+
+const (
+	ListEntryMerchantID = "MERCHANT_ID"
+	ListEntryAccountID  = "ACCOUNT_ID"
+	// ListEntryNMID is the national merchant ID of the QRIS repository template (tag 51)
+	ListEntryNMID = "NMID"
+	// ListEntryQRHash is the hex SHA-256 of a QRIS payload
+	ListEntryQRHash = "QR_HASH"
+)
+
+type ListEntry struct {
+	EntryID   int64      `gorm:"column:entry_id;primaryKey;autoIncrement"`
+	List      string     `gorm:"column:list"`
+	EntryType string     `gorm:"column:entry_type"`
+	Value     string     `gorm:"column:value"`
+	Reason    string     `gorm:"column:reason"`
+	ExpiresAt *time.Time `gorm:"column:expires_at"`
+	CreatedBy string     `gorm:"column:created_by"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedBy *string    `gorm:"column:updated_by"`
+	UpdatedAt *time.Time `gorm:"column:updated_at"`
+	RemovedBy *string    `gorm:"column:removed_by"`
+	RemovedAt *time.Time `gorm:"column:removed_at"`
+}
+
+func (e *ListEntry) TableName() string {
+	return "list_entries"
+}
@@ -0,0 +1,49 @@
+package entity
+
+import "time"
+
+const (
+	// ReconciliationMatched is a settlement row whose transaction and amount agree with ours
+	ReconciliationMatched = "MATCHED"
+	// ReconciliationAmountMismatch is a settlement row whose transaction was found with another amount
+	ReconciliationAmountMismatch = "AMOUNT_MISMATCH"
+	// ReconciliationMissingOnOurSide is a settlement row without a matching transaction here
+	ReconciliationMissingOnOurSide = "MISSING_ON_OUR_SIDE"
+	// ReconciliationDuplicate is a settlement row repeating a reference seen earlier in the file
+	ReconciliationDuplicate = "DUPLICATE"
+	// ReconciliationUnmatched is one of our successful transactions absent from the settlement file
+	ReconciliationUnmatched = "UNMATCHED"
+)
+
+type ReconciliationRun struct {
+	RunID            string    `gorm:"column:run_id;primaryKey;type:uuid;default:gen_random_uuid()"`
+	SettlementDate   time.Time `gorm:"column:settlement_date;type:date"`
+	FileName         string    `gorm:"column:file_name"`
+	TotalRows        int       `gorm:"column:total_rows"`
+	Matched          int       `gorm:"column:matched"`
+	AmountMismatch   int       `gorm:"column:amount_mismatch"`
+	MissingOnOurSide int       `gorm:"column:missing_on_our_side"`
+	Duplicate        int       `gorm:"column:duplicate"`
+	Unmatched        int       `gorm:"column:unmatched"`
+	CreatedAt        time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (r *ReconciliationRun) TableName() string {
+	return "reconciliation_runs"
+}
+
+type ReconciliationItem struct {
+	ItemID           int64    `gorm:"column:item_id;primaryKey;autoIncrement"`
+	RunID            string   `gorm:"column:run_id"`
+	LineNumber       int      `gorm:"column:line_number"`
+	Reference        string   `gorm:"column:reference"`
+	TransactionID    *string  `gorm:"column:transaction_id"`
+	SettlementAmount *float64 `gorm:"column:settlement_amount"`
+	OurAmount        *float64 `gorm:"column:our_amount"`
+	OurStatus        *string  `gorm:"column:our_status"`
+	Result           string   `gorm:"column:result"`
+}
+
+func (i *ReconciliationItem) TableName() string {
+	return "reconciliation_items"
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// SalesSummary is one aggregated row of merchant transactions. Columns that are not part of the
+// grouping are left empty.
+type SalesSummary struct {
+	Date       *time.Time `gorm:"column:date"`
+	TerminalID string     `gorm:"column:terminal_id"`
+	Status     string     `gorm:"column:status"`
+	Count      int64      `gorm:"column:count"`
+	Gross      float64    `gorm:"column:gross"`
+	Fees       float64    `gorm:"column:fees"`
+}
@@ -2,16 +2,41 @@ package entity
 
 import "time"
 
+const (
+	TransactionStatusPending = "PENDING"
+	TransactionStatusSuccess = "SUCCESS"
+	TransactionStatusExpired = "EXPIRED"
+	TransactionStatusFailed  = "FAILED"
+	// TransactionStatusSwitchApproved is an off-us payment the switch approved that could not be
+	// marked SUCCESS. The acquirer has the money, so it is never refunded automatically; an
+	// operator confirms it with a forced SUCCESS.
+	TransactionStatusSwitchApproved = "SWITCH_APPROVED"
+)
+
 type Transaction struct {
-	TransactionID string    `gorm:"column:transaction_id;primaryKey;type:uuid;default:gen_random_uuid()"`
-	TraceID       string    `gorm:"column:trace_id;index"`
-	AccountID     string    `gorm:"column:account_id"`
-	MerchantID    string    `gorm:"column:merchant_id"`
-	Amount        float64   `gorm:"column:amount;type:decimal(18,2)"`
-	Status        string    `gorm:"column:status;default:PENDING"`
-	CreatedAt     time.Time `gorm:"column:created_at;autoCreateTime"`
-	Account       Account   `gorm:"foreignKey:AccountID;references:AccountID"`
-	Merchant      Merchant  `gorm:"foreignKey:MerchantID;references:MerchantID"`
+	TransactionID    string     `gorm:"column:transaction_id;primaryKey;type:uuid;default:gen_random_uuid()"`
+	TraceID          string     `gorm:"column:trace_id;index"`
+	AccountID        string     `gorm:"column:account_id"`
+	MerchantID       string     `gorm:"column:merchant_id"`
+	TerminalID       string     `gorm:"column:terminal_id"`
+	AcquirerID       string     `gorm:"column:acquirer_id"`
+	Amount           float64    `gorm:"column:amount;type:decimal(18,2)"`
+	Currency         string     `gorm:"column:currency;default:IDR"`
+	OriginalAmount   *float64   `gorm:"column:original_amount;type:decimal(18,2)"`
+	OriginalCurrency *string    `gorm:"column:original_currency"`
+	FxRate           *float64   `gorm:"column:fx_rate;type:decimal(20,8)"`
+	Fee              float64    `gorm:"column:fee;type:decimal(18,2)"`
+	Status           string     `gorm:"column:status;default:PENDING"`
+	CreatedAt        time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt        time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+	DebitedAt        *time.Time `gorm:"column:debited_at"`
+	SwitchReference  *string    `gorm:"column:switch_reference"`
+	DeviceID         string     `gorm:"column:device_id"`
+	RiskAction       *string    `gorm:"column:risk_action"`
+	RiskScore        *int       `gorm:"column:risk_score"`
+	RiskRules        *string    `gorm:"column:risk_rules"`
+	Account          Account    `gorm:"foreignKey:AccountID;references:AccountID"`
+	Merchant         Merchant   `gorm:"foreignKey:MerchantID;references:MerchantID"`
 }
 
 func (t *Transaction) TableName() string {
@@ -0,0 +1,30 @@
+package fx
+
+// currencies maps the ISO 4217 numeric codes QRs carry in tag 53 to alphabetic codes, for the
+// currencies of the cross-border QR corridors
+var currencies = map[string]string{
+	"036": "AUD",
+	"096": "BND",
+	"116": "KHR",
+	"156": "CNY",
+	"344": "HKD",
+	"360": "IDR",
+	"392": "JPY",
+	"410": "KRW",
+	"418": "LAK",
+	"458": "MYR",
+	"608": "PHP",
+	"702": "SGD",
+	"764": "THB",
+	"704": "VND",
+	"784": "AED",
+	"826": "GBP",
+	"840": "USD",
+	"978": "EUR",
+}
+
+// CurrencyCode returns the alphabetic code of an ISO 4217 numeric currency code
+func CurrencyCode(numeric string) (string, bool) {
+	code, ok := currencies[numeric]
+	return code, ok
+}
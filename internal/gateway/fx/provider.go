@@ -0,0 +1,15 @@
+package fx
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrRateNotFound means the provider does not quote the currency pair
+var ErrRateNotFound = errors.New("fx: rate not found")
+
+// Provider quotes exchange rates as the amount of quote currency one unit of base currency buys.
+// Currencies are ISO 4217 alphabetic codes.
+type Provider interface {
+	Rate(ctx context.Context, base string, quote string) (float64, error)
+}
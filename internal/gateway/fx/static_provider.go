@@ -0,0 +1,46 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// StaticProvider serves rates from a JSON file of "BASE/QUOTE" pairs, e.g. {"MYR/IDR": 3550.25},
+// for local runs and tests. Inverse pairs are derived when only one direction is listed.
+type StaticProvider struct {
+	Rates map[string]float64
+}
+
+func NewStaticProvider(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make(map[string]float64)
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("invalid rates file %s: %w", path, err)
+	}
+	for pair, rate := range rates {
+		if rate <= 0 {
+			return nil, fmt.Errorf("invalid rates file %s: rate for %s must be positive", path, pair)
+		}
+	}
+
+	return &StaticProvider{Rates: rates}, nil
+}
+
+func (p *StaticProvider) Rate(ctx context.Context, base string, quote string) (float64, error) {
+	if base == quote {
+		return 1, nil
+	}
+	if rate, ok := p.Rates[base+"/"+quote]; ok {
+		return rate, nil
+	}
+	if rate, ok := p.Rates[quote+"/"+base]; ok {
+		return 1 / rate, nil
+	}
+	return 0, fmt.Errorf("%w: %s/%s", ErrRateNotFound, base, quote)
+}
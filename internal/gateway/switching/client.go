@@ -0,0 +1,23 @@
+package switching
+
+import (
+	"context"
+	"errors"
+
+	"golang-clean-architecture/internal/model"
+)
+
+var (
+	// ErrTimeout means the switch did not answer in time; the outcome of the request is unknown
+	ErrTimeout = errors.New("switching: timeout")
+	// ErrUnavailable means the request could not be delivered or was rejected by the switch itself
+	ErrUnavailable = errors.New("switching: unavailable")
+)
+
+// Client forwards off-us QRIS transactions to the interbank switch. Implementations return
+// ErrTimeout when the deadline passes before an answer, so callers know to send a reversal.
+type Client interface {
+	Inquiry(ctx context.Context, request *model.SwitchInquiryRequest) (*model.SwitchInquiryResponse, error)
+	Payment(ctx context.Context, request *model.SwitchPaymentRequest) (*model.SwitchPaymentResponse, error)
+	Reversal(ctx context.Context, request *model.SwitchReversalRequest) (*model.SwitchReversalResponse, error)
+}
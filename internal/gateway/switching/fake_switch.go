@@ -0,0 +1,130 @@
+package switching
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang-clean-architecture/internal/model"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// FakeDeclineCents makes the fake switch decline payments whose amount ends in .91
+	FakeDeclineCents = 91
+	// FakeTimeoutCents makes the fake switch answer payments whose amount ends in .92 after Delay
+	FakeTimeoutCents = 92
+)
+
+// FakeSwitch is an in-memory switch speaking the HTTPClient protocol, for local runs and tests.
+// It approves every request except amounts ending in FakeDeclineCents (declined with "51",
+// insufficient funds) and FakeTimeoutCents (answered only after Delay, to exercise reversals).
+type FakeSwitch struct {
+	Delay     time.Duration
+	mutex     sync.Mutex
+	payments  map[string]*model.SwitchPaymentRequest
+	reversals map[string]*model.SwitchReversalRequest
+}
+
+func NewFakeSwitch(delay time.Duration) *FakeSwitch {
+	return &FakeSwitch{
+		Delay:     delay,
+		payments:  make(map[string]*model.SwitchPaymentRequest),
+		reversals: make(map[string]*model.SwitchReversalRequest),
+	}
+}
+
+// Handler serves /inquiry, /payment and /reversal
+func (s *FakeSwitch) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /inquiry", s.inquiry)
+	mux.HandleFunc("POST /payment", s.payment)
+	mux.HandleFunc("POST /reversal", s.reversal)
+	return mux
+}
+
+// Reversed reports whether a reversal was received for the payment reference
+func (s *FakeSwitch) Reversed(reference string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, ok := s.reversals[reference]
+	return ok
+}
+
+func (s *FakeSwitch) inquiry(w http.ResponseWriter, r *http.Request) {
+	request := new(model.SwitchInquiryRequest)
+	if !decode(w, r, request) {
+		return
+	}
+
+	encode(w, &model.SwitchInquiryResponse{
+		ResponseCode: model.SwitchResponseApproved,
+		MerchantID:   request.MerchantID,
+		MerchantName: fmt.Sprintf("Merchant %s", request.MerchantID),
+		City:         "Jakarta",
+		TerminalID:   request.TerminalID,
+	})
+}
+
+func (s *FakeSwitch) payment(w http.ResponseWriter, r *http.Request) {
+	request := new(model.SwitchPaymentRequest)
+	if !decode(w, r, request) {
+		return
+	}
+
+	switch cents(request.Amount) {
+	case FakeDeclineCents:
+		encode(w, &model.SwitchPaymentResponse{ResponseCode: "51"})
+		return
+	case FakeTimeoutCents:
+		select {
+		case <-time.After(s.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	s.mutex.Lock()
+	s.payments[request.Reference] = request
+	s.mutex.Unlock()
+
+	encode(w, &model.SwitchPaymentResponse{
+		ResponseCode:    model.SwitchResponseApproved,
+		SwitchReference: uuid.NewString(),
+	})
+}
+
+func (s *FakeSwitch) reversal(w http.ResponseWriter, r *http.Request) {
+	request := new(model.SwitchReversalRequest)
+	if !decode(w, r, request) {
+		return
+	}
+
+	s.mutex.Lock()
+	delete(s.payments, request.Reference)
+	s.reversals[request.Reference] = request
+	s.mutex.Unlock()
+
+	encode(w, &model.SwitchReversalResponse{ResponseCode: model.SwitchResponseApproved})
+}
+
+func cents(amount float64) int {
+	return int(math.Round(amount*100)) % 100
+}
+
+func decode(w http.ResponseWriter, r *http.Request, request any) bool {
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func encode(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
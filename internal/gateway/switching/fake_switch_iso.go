@@ -0,0 +1,129 @@
+package switching
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-clean-architecture/internal/iso8583"
+	"golang-clean-architecture/internal/model"
+)
+
+// ServeISO accepts Link connections and answers them with the same rules as the HTTP handler:
+// 0800s are approved, inquiries confirm the merchant and payments follow the amount cents.
+func (s *FakeSwitch) ServeISO(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+func (s *FakeSwitch) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	var writeMutex sync.Mutex
+	reader := bufio.NewReader(conn)
+	for {
+		data, err := readFrame(reader)
+		if err != nil {
+			return
+		}
+
+		request, err := iso8583.Unpack(iso8583.QRISSpec, data)
+		if err != nil {
+			log.Printf("Dropping malformed message: %v", err)
+			continue
+		}
+
+		// Answer concurrently so a delayed payment does not hold up echoes
+		go func() {
+			var packed []byte
+			response, err := s.answer(request)
+			if err == nil {
+				packed, err = response.Pack()
+			}
+			if err != nil {
+				log.Printf("Failed to answer %s: %v", request.MTI, err)
+				return
+			}
+
+			writeMutex.Lock()
+			defer writeMutex.Unlock()
+			writeFrame(conn, packed)
+		}()
+	}
+}
+
+func (s *FakeSwitch) answer(request *iso8583.Message) (*iso8583.Message, error) {
+	reference, acquirerID := iso8583.AdditionalData(request)
+
+	switch request.MTI {
+	case iso8583.MTINetworkManagementRequest:
+		return iso8583.Response(request, model.SwitchResponseApproved)
+	case iso8583.MTIReversalRequest:
+		s.mutex.Lock()
+		delete(s.payments, reference)
+		s.reversals[reference] = &model.SwitchReversalRequest{
+			Reference:  reference,
+			AcquirerID: acquirerID,
+			Amount:     isoAmount(request),
+		}
+		s.mutex.Unlock()
+		return iso8583.Response(request, model.SwitchResponseApproved)
+	case iso8583.MTIFinancialRequest:
+	default:
+		return nil, fmt.Errorf("unsupported MTI %s", request.MTI)
+	}
+
+	if request.Value(iso8583.FieldProcessingCode) == iso8583.ProcessingCodeQRISInquiry {
+		response, err := iso8583.Response(request, model.SwitchResponseApproved)
+		if err != nil {
+			return nil, err
+		}
+		merchantID := strings.TrimSpace(request.Value(iso8583.FieldMerchantID))
+		return response, response.Set(iso8583.FieldMerchantNameLocation, fmt.Sprintf("%-25.25s%-13.13s%s", "Merchant "+merchantID, "Jakarta", "ID"))
+	}
+
+	amount := isoAmount(request)
+	switch cents(amount) {
+	case FakeDeclineCents:
+		return iso8583.Response(request, "51")
+	case FakeTimeoutCents:
+		time.Sleep(s.Delay)
+	}
+
+	s.mutex.Lock()
+	s.payments[reference] = &model.SwitchPaymentRequest{
+		Reference:   reference,
+		AcquirerID:  acquirerID,
+		MerchantPAN: request.Value(iso8583.FieldDestinationAccount),
+		MerchantID:  strings.TrimSpace(request.Value(iso8583.FieldMerchantID)),
+		TerminalID:  strings.TrimSpace(request.Value(iso8583.FieldTerminalID)),
+		Amount:      amount,
+		Currency:    request.Value(iso8583.FieldCurrency),
+	}
+	s.mutex.Unlock()
+
+	response, err := iso8583.Response(request, model.SwitchResponseApproved)
+	if err != nil {
+		return nil, err
+	}
+	return response, response.Set(iso8583.FieldApprovalCode, request.Value(iso8583.FieldSTAN))
+}
+
+func isoAmount(message *iso8583.Message) float64 {
+	minor, _ := strconv.ParseInt(message.Value(iso8583.FieldAmount), 10, 64)
+	return float64(minor) / 100
+}
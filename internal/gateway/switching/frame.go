@@ -0,0 +1,37 @@
+package switching
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// writeFrame writes a message prefixed with its length as a 2 byte big-endian integer, the
+// framing used on the switch TCP link
+func writeFrame(w io.Writer, data []byte) error {
+	if len(data) > math.MaxUint16 {
+		return fmt.Errorf("frame of %d bytes exceeds %d", len(data), math.MaxUint16)
+	}
+
+	frame := make([]byte, 2+len(data))
+	binary.BigEndian.PutUint16(frame, uint16(len(data)))
+	copy(frame[2:], data)
+
+	_, err := w.Write(frame)
+	return err
+}
+
+// readFrame reads one length-prefixed message
+func readFrame(r io.Reader) ([]byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, binary.BigEndian.Uint16(header))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,99 @@
+package switching
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"golang-clean-architecture/internal/model"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// HTTPClient talks to the switch with JSON over HTTP: POST {BaseURL}/inquiry, /payment and
+// /reversal. Every request is bounded by Timeout and carries the trace context.
+type HTTPClient struct {
+	Log     *logrus.Logger
+	BaseURL string
+	Timeout time.Duration
+	HTTP    *http.Client
+}
+
+func NewHTTPClient(log *logrus.Logger, baseURL string, timeout time.Duration) *HTTPClient {
+	return &HTTPClient{
+		Log:     log,
+		BaseURL: baseURL,
+		Timeout: timeout,
+		HTTP: &http.Client{
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+	}
+}
+
+func (c *HTTPClient) Inquiry(ctx context.Context, request *model.SwitchInquiryRequest) (*model.SwitchInquiryResponse, error) {
+	response := new(model.SwitchInquiryResponse)
+	if err := c.post(ctx, "/inquiry", request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *HTTPClient) Payment(ctx context.Context, request *model.SwitchPaymentRequest) (*model.SwitchPaymentResponse, error) {
+	response := new(model.SwitchPaymentResponse)
+	if err := c.post(ctx, "/payment", request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *HTTPClient) Reversal(ctx context.Context, request *model.SwitchReversalRequest) (*model.SwitchReversalResponse, error) {
+	response := new(model.SwitchReversalResponse)
+	if err := c.post(ctx, "/reversal", request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *HTTPClient) post(ctx context.Context, path string, request any, response any) error {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+
+	httpResponse, err := c.HTTP.Do(httpRequest)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return ErrTimeout
+	}
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Switch request %s failed: %+v", path, err)
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s returned %d", ErrUnavailable, path, httpResponse.StatusCode)
+	}
+
+	if err := json.NewDecoder(httpResponse.Body).Decode(response); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+			return ErrTimeout
+		}
+		return fmt.Errorf("%w: invalid response from %s: %v", ErrUnavailable, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package switching
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"golang-clean-architecture/internal/iso8583"
+	"golang-clean-architecture/internal/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ISOClient talks to the switch with ISO 8583 over the persistent TCP Link
+type ISOClient struct {
+	Log  *logrus.Logger
+	Link *Link
+
+	// payments keeps the 0200 of every payment without a definitive answer, keyed by our
+	// reference, so a reversal can point back at it in field 90
+	payments sync.Map
+}
+
+func NewISOClient(log *logrus.Logger, link *Link) *ISOClient {
+	return &ISOClient{
+		Log:  log,
+		Link: link,
+	}
+}
+
+func (c *ISOClient) Inquiry(ctx context.Context, request *model.SwitchInquiryRequest) (*model.SwitchInquiryResponse, error) {
+	message, err := iso8583.InquiryRequest(request, c.Link.Trace())
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.send(ctx, message, iso8583.MTIFinancialResponse)
+	if err != nil {
+		return nil, err
+	}
+	return iso8583.InquiryResponse(response), nil
+}
+
+func (c *ISOClient) Payment(ctx context.Context, request *model.SwitchPaymentRequest) (*model.SwitchPaymentResponse, error) {
+	message, err := iso8583.PaymentRequest(request, c.Link.Trace())
+	if err != nil {
+		return nil, err
+	}
+
+	c.payments.Store(request.Reference, message)
+	response, err := c.send(ctx, message, iso8583.MTIFinancialResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	c.payments.Delete(request.Reference)
+	return iso8583.PaymentResponse(response), nil
+}
+
+func (c *ISOClient) Reversal(ctx context.Context, request *model.SwitchReversalRequest) (*model.SwitchReversalResponse, error) {
+	original, ok := c.payments.Load(request.Reference)
+	if !ok {
+		// The payment was sent before a restart; field 48 still identifies it to the switch
+		c.Log.WithContext(ctx).Warnf("Original message not found for reversal of: %s", request.Reference)
+		message, err := iso8583.PaymentRequest(&model.SwitchPaymentRequest{
+			Reference:  request.Reference,
+			AcquirerID: request.AcquirerID,
+			Amount:     request.Amount,
+		}, iso8583.Trace{STAN: "000000", Institution: c.Link.Config.Institution})
+		if err != nil {
+			return nil, err
+		}
+		original = message
+	}
+
+	message, err := iso8583.ReversalRequest(original.(*iso8583.Message), c.Link.Trace())
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.send(ctx, message, iso8583.MTIReversalResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Value(iso8583.FieldResponseCode) == model.SwitchResponseApproved {
+		c.payments.Delete(request.Reference)
+	}
+	return iso8583.ReversalResponse(response), nil
+}
+
+func (c *ISOClient) send(ctx context.Context, request *iso8583.Message, mti string) (*iso8583.Message, error) {
+	response, err := c.Link.Send(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if response.MTI != mti {
+		return nil, fmt.Errorf("%w: expected %s response, got %s", ErrUnavailable, mti, response.MTI)
+	}
+	return response, nil
+}
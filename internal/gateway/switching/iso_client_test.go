@@ -0,0 +1,386 @@
+package switching
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-clean-architecture/internal/iso8583"
+	"golang-clean-architecture/internal/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+const testInstitution = "93600014"
+
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	return listener
+}
+
+// startLink runs a link to address until the test ends
+func startLink(t *testing.T, address string, config LinkConfig) *Link {
+	t.Helper()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	config.Address = address
+	config.Institution = testInstitution
+	link := NewLink(log, config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		link.Start(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return link
+}
+
+func waitFor(t *testing.T, what string, timeout time.Duration, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// serveScripted answers every message on conn with answer; a nil response sends nothing and
+// a false ok closes the connection
+func serveScripted(conn net.Conn, answer func(request *iso8583.Message) (response *iso8583.Message, ok bool)) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		data, err := readFrame(reader)
+		if err != nil {
+			return
+		}
+		request, err := iso8583.Unpack(iso8583.QRISSpec, data)
+		if err != nil {
+			return
+		}
+
+		response, ok := answer(request)
+		if !ok {
+			return
+		}
+		if response == nil {
+			continue
+		}
+		packed, err := response.Pack()
+		if err != nil || writeFrame(conn, packed) != nil {
+			return
+		}
+	}
+}
+
+func approve(request *iso8583.Message) (*iso8583.Message, bool) {
+	response, err := iso8583.Response(request, model.SwitchResponseApproved)
+	return response, err == nil
+}
+
+func TestLinkEcho(t *testing.T) {
+	tests := []struct {
+		name        string
+		answerEcho  bool
+		wantAccepts int
+	}{
+		{name: "answered echoes keep the session", answerEcho: true, wantAccepts: 1},
+		{name: "missed echo reconnects", answerEcho: false, wantAccepts: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener := listen(t)
+			var accepts, echoes atomic.Int32
+			go func() {
+				for {
+					conn, err := listener.Accept()
+					if err != nil {
+						return
+					}
+					accepts.Add(1)
+					go serveScripted(conn, func(request *iso8583.Message) (*iso8583.Message, bool) {
+						if request.Value(iso8583.FieldNetworkManagementCode) != iso8583.NetworkEcho {
+							return approve(request)
+						}
+						echoes.Add(1)
+						if !tt.answerEcho {
+							return nil, true
+						}
+						return approve(request)
+					})
+				}
+			}()
+
+			link := startLink(t, listener.Addr().String(), LinkConfig{
+				Timeout:      50 * time.Millisecond,
+				EchoInterval: 20 * time.Millisecond,
+				ReconnectMin: 10 * time.Millisecond,
+				ReconnectMax: 10 * time.Millisecond,
+			})
+			waitFor(t, "sign-on", time.Second, link.Ready)
+			waitFor(t, "echoes", time.Second, func() bool {
+				return echoes.Load() >= 3 || int(accepts.Load()) >= tt.wantAccepts+1
+			})
+
+			if got := int(accepts.Load()); got < tt.wantAccepts || (tt.answerEcho && got != tt.wantAccepts) {
+				t.Fatalf("switch accepted %d connections, want %d", got, tt.wantAccepts)
+			}
+			if tt.answerEcho && !link.Ready() {
+				t.Fatalf("link not ready after %d answered echoes", echoes.Load())
+			}
+		})
+	}
+}
+
+// TestLinkAnswersSwitchEcho checks the link answers the 0800 echoes the switch sends it
+func TestLinkAnswersSwitchEcho(t *testing.T) {
+	listener := listen(t)
+	answered := make(chan *iso8583.Message, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+
+		// Sign on, then echo the link
+		data, err := readFrame(reader)
+		if err != nil {
+			return
+		}
+		signOn, _ := iso8583.Unpack(iso8583.QRISSpec, data)
+		response, _ := approve(signOn)
+		packed, _ := response.Pack()
+		writeFrame(conn, packed)
+
+		echo, _ := iso8583.NetworkRequest(iso8583.NetworkEcho, iso8583.Trace{STAN: "900001", TransmittedAt: time.Now()})
+		packed, _ = echo.Pack()
+		writeFrame(conn, packed)
+
+		for {
+			data, err := readFrame(reader)
+			if err != nil {
+				return
+			}
+			message, err := iso8583.Unpack(iso8583.QRISSpec, data)
+			if err == nil && message.MTI == iso8583.MTINetworkManagementResponse {
+				answered <- message
+				return
+			}
+		}
+	}()
+
+	startLink(t, listener.Addr().String(), LinkConfig{
+		Timeout:      time.Second,
+		EchoInterval: time.Minute,
+		ReconnectMin: 10 * time.Millisecond,
+		ReconnectMax: 10 * time.Millisecond,
+	})
+
+	select {
+	case message := <-answered:
+		if message.Value(iso8583.FieldSTAN) != "900001" || message.Value(iso8583.FieldResponseCode) != model.SwitchResponseApproved {
+			t.Fatalf("echo answered with STAN %s, response code %s", message.Value(iso8583.FieldSTAN), message.Value(iso8583.FieldResponseCode))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("link did not answer the echo")
+	}
+}
+
+// TestLinkReconnectBackoff drops the first connections before sign-on: the delay between
+// attempts doubles up to ReconnectMax, and the link signs on once the switch answers
+func TestLinkReconnectBackoff(t *testing.T) {
+	const refused = 4
+	reconnectMin := 40 * time.Millisecond
+	reconnectMax := 120 * time.Millisecond
+
+	listener := listen(t)
+	fakeSwitch := NewFakeSwitch(0)
+	var mutex sync.Mutex
+	var accepted []time.Time
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			mutex.Lock()
+			accepted = append(accepted, time.Now())
+			attempt := len(accepted)
+			mutex.Unlock()
+
+			if attempt <= refused {
+				conn.Close()
+				continue
+			}
+			go fakeSwitch.serveConn(conn)
+		}
+	}()
+
+	link := startLink(t, listener.Addr().String(), LinkConfig{
+		Timeout:      time.Second,
+		EchoInterval: time.Minute,
+		ReconnectMin: reconnectMin,
+		ReconnectMax: reconnectMax,
+	})
+	waitFor(t, "sign-on", 5*time.Second, link.Ready)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(accepted) != refused+1 {
+		t.Fatalf("switch accepted %d connections, want %d", len(accepted), refused+1)
+	}
+	backoff := reconnectMin
+	for i := 1; i < len(accepted); i++ {
+		gap := accepted[i].Sub(accepted[i-1])
+		if gap < backoff || gap > backoff+100*time.Millisecond {
+			t.Fatalf("attempt %d came %v after the previous one, want a backoff of %v", i+1, gap, backoff)
+		}
+		backoff = min(2*backoff, reconnectMax)
+	}
+}
+
+func TestISOClient(t *testing.T) {
+	listener := listen(t)
+	fakeSwitch := NewFakeSwitch(300 * time.Millisecond)
+	go fakeSwitch.ServeISO(listener)
+
+	link := startLink(t, listener.Addr().String(), LinkConfig{
+		Timeout:      100 * time.Millisecond,
+		EchoInterval: time.Minute,
+		ReconnectMin: 10 * time.Millisecond,
+		ReconnectMax: 10 * time.Millisecond,
+	})
+	waitFor(t, "sign-on", time.Second, link.Ready)
+	client := NewISOClient(link.Log, link)
+	ctx := context.Background()
+
+	inquiry, err := client.Inquiry(ctx, &model.SwitchInquiryRequest{
+		Reference:   "inq_1",
+		AcquirerID:  "ID.CO.ACQUIRER.WWW",
+		MerchantPAN: "936000990000000001",
+		MerchantID:  "M001",
+		TerminalID:  "T001",
+		Currency:    "360",
+		QrisPayload: "00020101021226620014ID.CO.QRIS.WWW",
+	})
+	if err != nil || inquiry.ResponseCode != model.SwitchResponseApproved || inquiry.MerchantName != "Merchant M001" || inquiry.City != "Jakarta" {
+		t.Fatalf("Inquiry = %+v, error %v", inquiry, err)
+	}
+
+	tests := []struct {
+		name         string
+		amount       float64
+		wantCode     string
+		wantErr      error
+		wantReversed bool
+	}{
+		{name: "approved", amount: 150000, wantCode: model.SwitchResponseApproved},
+		{name: "declined", amount: 150000.91, wantCode: "51"},
+		{name: "timeout then reversal", amount: 150000.92, wantErr: ErrTimeout, wantReversed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reference := "trx_" + strings.ReplaceAll(tt.name, " ", "_")
+			request := &model.SwitchPaymentRequest{
+				Reference:   reference,
+				AcquirerID:  "ID.CO.ACQUIRER.WWW",
+				MerchantPAN: "936000990000000001",
+				MerchantID:  "M001",
+				TerminalID:  "T001",
+				Amount:      tt.amount,
+				Currency:    "360",
+			}
+
+			response, err := client.Payment(ctx, request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Payment error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && response.ResponseCode != tt.wantCode {
+				t.Fatalf("Payment response code = %s, want %s", response.ResponseCode, tt.wantCode)
+			}
+			if !tt.wantReversed {
+				return
+			}
+
+			reversal, err := client.Reversal(ctx, &model.SwitchReversalRequest{Reference: reference, AcquirerID: request.AcquirerID, Amount: request.Amount})
+			if err != nil || reversal.ResponseCode != model.SwitchResponseApproved {
+				t.Fatalf("Reversal = %+v, error %v", reversal, err)
+			}
+			if !fakeSwitch.Reversed(reference) {
+				t.Fatalf("switch did not receive the reversal of %s", reference)
+			}
+
+			// The late 0210 is dropped without breaking the session
+			time.Sleep(fakeSwitch.Delay)
+			if !link.Ready() {
+				t.Fatalf("link dropped after a late response")
+			}
+			if _, err := client.Payment(ctx, &model.SwitchPaymentRequest{Reference: reference + "_next", Amount: 1000, Currency: "360"}); err != nil {
+				t.Fatalf("Payment after a late response: %v", err)
+			}
+		})
+	}
+}
+
+// TestISOClientConnectionLost fails an in-flight payment as soon as the switch drops the
+// connection, rather than after the timeout
+func TestISOClientConnectionLost(t *testing.T) {
+	listener := listen(t)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveScripted(conn, func(request *iso8583.Message) (*iso8583.Message, bool) {
+				if request.MTI == iso8583.MTIFinancialRequest {
+					return nil, false
+				}
+				return approve(request)
+			})
+		}
+	}()
+
+	link := startLink(t, listener.Addr().String(), LinkConfig{
+		Timeout:      5 * time.Second,
+		EchoInterval: time.Minute,
+		ReconnectMin: 10 * time.Millisecond,
+		ReconnectMax: 10 * time.Millisecond,
+	})
+	waitFor(t, "sign-on", time.Second, link.Ready)
+	client := NewISOClient(link.Log, link)
+
+	start := time.Now()
+	_, err := client.Payment(context.Background(), &model.SwitchPaymentRequest{Reference: "trx_lost", Amount: 1000, Currency: "360"})
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Payment error = %v, want ErrUnavailable", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Payment failed after %v, want before the %v timeout", elapsed, link.Config.Timeout)
+	}
+
+	// The link reconnects and signs on again
+	waitFor(t, "sign-on after the drop", time.Second, link.Ready)
+}
@@ -0,0 +1,321 @@
+package switching
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang-clean-architecture/internal/iso8583"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LinkConfig configures the persistent TCP session with the switch
+type LinkConfig struct {
+	Address string
+	// Institution is our acquiring institution ID, sent in field 32
+	Institution string
+	// Timeout bounds every request, including sign-on and echo
+	Timeout time.Duration
+	// EchoInterval is how often an idle-check 0800 echo is sent; a missed echo drops the connection
+	EchoInterval time.Duration
+	// ReconnectMin and ReconnectMax bound the exponential backoff between connection attempts
+	ReconnectMin time.Duration
+	ReconnectMax time.Duration
+}
+
+// Link keeps one signed-on ISO 8583 session with the switch. Requests are multiplexed on the
+// connection and matched to their responses by STAN and RRN. When the connection drops every
+// in-flight request fails with ErrUnavailable and the link reconnects with backoff.
+type Link struct {
+	Log    *logrus.Logger
+	Config LinkConfig
+	Dialer *net.Dialer
+
+	stan atomic.Uint32
+
+	mutex   sync.Mutex
+	conn    net.Conn
+	ready   bool
+	pending map[string]chan *iso8583.Message
+
+	writeMutex sync.Mutex
+}
+
+func NewLink(log *logrus.Logger, config LinkConfig) *Link {
+	return &Link{
+		Log:     log,
+		Config:  config,
+		Dialer:  &net.Dialer{Timeout: config.Timeout},
+		pending: make(map[string]chan *iso8583.Message),
+	}
+}
+
+// Start connects, signs on and keeps the session alive until ctx is cancelled, then signs off
+func (l *Link) Start(ctx context.Context) {
+	backoff := l.Config.ReconnectMin
+	for ctx.Err() == nil {
+		conn, err := l.Dialer.DialContext(ctx, "tcp", l.Config.Address)
+		if err == nil {
+			l.Log.Infof("Connected to switch at %s", l.Config.Address)
+			if l.session(ctx, conn) {
+				backoff = l.Config.ReconnectMin
+			}
+		} else if ctx.Err() == nil {
+			l.Log.Warnf("Failed to connect to switch at %s: %+v", l.Config.Address, err)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+			backoff = min(2*backoff, l.Config.ReconnectMax)
+		}
+	}
+}
+
+// Ready reports whether the link is signed on and accepting requests
+func (l *Link) Ready() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.ready
+}
+
+// Trace allocates the STAN and RRN for a new request. STANs wrap after 999999; the RRN is
+// the last digit of the year, the day of the year, the hour and the STAN.
+func (l *Link) Trace() iso8583.Trace {
+	var stan uint32
+	for stan == 0 {
+		stan = l.stan.Add(1) % 1000000
+	}
+
+	now := time.Now()
+	return iso8583.Trace{
+		STAN:          fmt.Sprintf("%06d", stan),
+		RRN:           fmt.Sprintf("%d%03d%s%06d", now.Year()%10, now.YearDay(), now.Format("15"), stan),
+		Institution:   l.Config.Institution,
+		TransmittedAt: now,
+	}
+}
+
+// Send writes a request and waits for its response. It returns ErrUnavailable when the link is
+// not signed on or drops, and ErrTimeout when no response arrives within the timeout.
+func (l *Link) Send(ctx context.Context, request *iso8583.Message) (*iso8583.Message, error) {
+	l.mutex.Lock()
+	conn, ready := l.conn, l.ready
+	l.mutex.Unlock()
+
+	if !ready {
+		return nil, fmt.Errorf("%w: link to %s is not signed on", ErrUnavailable, l.Config.Address)
+	}
+	return l.exchange(ctx, conn, request)
+}
+
+// session runs one connection until it fails or ctx is cancelled. It reports whether sign-on
+// succeeded, so a switch that accepts and then refuses us is retried with growing backoff.
+func (l *Link) session(ctx context.Context, conn net.Conn) bool {
+	l.mutex.Lock()
+	l.conn = conn
+	l.mutex.Unlock()
+
+	// The reader fails in-flight requests as soon as the connection breaks
+	readerDone := make(chan error, 1)
+	go func() {
+		err := l.read(conn)
+		l.disconnect()
+		readerDone <- err
+	}()
+
+	defer func() {
+		conn.Close()
+		<-readerDone
+	}()
+
+	if err := l.network(ctx, conn, iso8583.NetworkSignOn); err != nil {
+		l.Log.Warnf("Sign-on to switch failed: %+v", err)
+		return false
+	}
+
+	l.mutex.Lock()
+	l.ready = l.conn == conn
+	l.mutex.Unlock()
+	l.Log.Infof("Signed on to switch at %s", l.Config.Address)
+
+	ticker := time.NewTicker(l.Config.EchoInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			l.mutex.Lock()
+			l.ready = false
+			l.mutex.Unlock()
+
+			// Our own context is cancelled; sign-off still needs a deadline of its own
+			if err := l.network(context.WithoutCancel(ctx), conn, iso8583.NetworkSignOff); err != nil {
+				l.Log.Warnf("Sign-off from switch failed: %+v", err)
+			} else {
+				l.Log.Infof("Signed off from switch at %s", l.Config.Address)
+			}
+			return true
+		case err := <-readerDone:
+			readerDone <- err
+			l.Log.Warnf("Connection to switch lost: %+v", err)
+			return true
+		case <-ticker.C:
+			if err := l.network(ctx, conn, iso8583.NetworkEcho); err != nil {
+				l.Log.Warnf("Echo to switch failed, reconnecting: %+v", err)
+				return true
+			}
+		}
+	}
+}
+
+// network sends an 0800 and requires it to be approved
+func (l *Link) network(ctx context.Context, conn net.Conn, code string) error {
+	request, err := iso8583.NetworkRequest(code, l.Trace())
+	if err != nil {
+		return err
+	}
+
+	response, err := l.exchange(ctx, conn, request)
+	if err != nil {
+		return err
+	}
+	if responseCode := response.Value(iso8583.FieldResponseCode); responseCode != "00" {
+		return fmt.Errorf("%w: network management %s answered %s", ErrUnavailable, code, responseCode)
+	}
+	return nil
+}
+
+func (l *Link) exchange(ctx context.Context, conn net.Conn, request *iso8583.Message) (*iso8583.Message, error) {
+	ctx, cancel := context.WithTimeout(ctx, l.Config.Timeout)
+	defer cancel()
+
+	data, err := request.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	key := matchKey(request)
+	response := make(chan *iso8583.Message, 1)
+	l.mutex.Lock()
+	if l.conn != conn {
+		l.mutex.Unlock()
+		return nil, fmt.Errorf("%w: connection to %s was closed", ErrUnavailable, l.Config.Address)
+	}
+	l.pending[key] = response
+	l.mutex.Unlock()
+
+	if err := l.write(conn, data); err != nil {
+		l.forget(key, response)
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	select {
+	case message, ok := <-response:
+		if !ok {
+			return nil, fmt.Errorf("%w: connection to %s was closed", ErrUnavailable, l.Config.Address)
+		}
+		return message, nil
+	case <-ctx.Done():
+		l.forget(key, response)
+		return nil, ErrTimeout
+	}
+}
+
+func (l *Link) write(conn net.Conn, data []byte) error {
+	l.writeMutex.Lock()
+	defer l.writeMutex.Unlock()
+
+	conn.SetWriteDeadline(time.Now().Add(l.Config.Timeout))
+	return writeFrame(conn, data)
+}
+
+// read dispatches responses to their waiting requests and answers echoes from the switch
+func (l *Link) read(conn net.Conn) error {
+	reader := bufio.NewReader(conn)
+	for {
+		data, err := readFrame(reader)
+		if err != nil {
+			return err
+		}
+
+		message, err := iso8583.Unpack(iso8583.QRISSpec, data)
+		if err != nil {
+			l.Log.Warnf("Dropping malformed message from switch: %+v", err)
+			continue
+		}
+
+		if isResponse(message.MTI) {
+			l.deliver(message)
+			continue
+		}
+
+		if message.MTI != iso8583.MTINetworkManagementRequest {
+			l.Log.Warnf("Dropping unsupported %s request from switch", message.MTI)
+			continue
+		}
+		response, err := iso8583.Response(message, "00")
+		if err == nil {
+			data, err = response.Pack()
+		}
+		if err == nil {
+			err = l.write(conn, data)
+		}
+		if err != nil {
+			l.Log.Warnf("Failed to answer network management request from switch: %+v", err)
+		}
+	}
+}
+
+func (l *Link) deliver(message *iso8583.Message) {
+	key := matchKey(message)
+
+	l.mutex.Lock()
+	response, ok := l.pending[key]
+	delete(l.pending, key)
+	l.mutex.Unlock()
+
+	if !ok {
+		// The request already timed out and, for payments, is being reversed
+		l.Log.Warnf("Dropping late %s response from switch, STAN: %s", message.MTI, message.Value(iso8583.FieldSTAN))
+		return
+	}
+	response <- message
+}
+
+func (l *Link) forget(key string, response chan *iso8583.Message) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.pending[key] == response {
+		delete(l.pending, key)
+	}
+}
+
+// disconnect fails every in-flight request so callers do not wait for their timeout
+func (l *Link) disconnect() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.conn = nil
+	l.ready = false
+	for key, response := range l.pending {
+		close(response)
+		delete(l.pending, key)
+	}
+}
+
+// matchKey pairs a request with its response: the switch echoes STAN and RRN unchanged
+func matchKey(message *iso8583.Message) string {
+	return message.Value(iso8583.FieldSTAN) + "/" + strings.TrimSpace(message.Value(iso8583.FieldRRN))
+}
+
+// isResponse checks the message function digit of the MTI: 0210, 0410 and 0810 are responses
+func isResponse(mti string) bool {
+	return len(mti) == 4 && (mti[2] == '1' || mti[2] == '3')
+}
@@ -0,0 +1,54 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// encode writes characters in the given encoding
+func encode(value string, encoding Encoding) ([]byte, error) {
+	switch encoding {
+	case BCD:
+		if strings.Trim(value, "0123456789") != "" {
+			return nil, fmt.Errorf("BCD value must be numeric, got %q", value)
+		}
+		if len(value)%2 != 0 {
+			value = "0" + value
+		}
+		packed := make([]byte, len(value)/2)
+		for i := range packed {
+			packed[i] = (value[2*i]-'0')<<4 | (value[2*i+1] - '0')
+		}
+		return packed, nil
+	case Binary:
+		return hex.DecodeString(value)
+	default:
+		return []byte(value), nil
+	}
+}
+
+// decode reads length characters (digits for BCD) written in the given encoding
+func decode(raw []byte, encoding Encoding, length int) string {
+	switch encoding {
+	case BCD:
+		digits := make([]byte, 0, len(raw)*2)
+		for _, b := range raw {
+			digits = append(digits, '0'+b>>4, '0'+b&0x0F)
+		}
+		// Drop the zero added to odd-length values
+		return string(digits[len(digits)-length:])
+	case Binary:
+		return strings.ToUpper(hex.EncodeToString(raw))
+	default:
+		return string(raw)
+	}
+}
+
+// encodedSize is the number of bytes length characters take in the given encoding
+func encodedSize(length int, encoding Encoding) int {
+	if encoding == BCD {
+		return (length + 1) / 2
+	}
+	return length
+}
@@ -0,0 +1,226 @@
+package iso8583
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/model"
+)
+
+// ProcessingCodeQRISInquiry asks the acquirer to confirm a merchant before payment
+const ProcessingCodeQRISInquiry = "370000"
+
+// Sub-elements of field 48. Our references and acquirer GUIDs do not fit the numeric
+// institution and 12 character RRN fields, so they travel here as tag, length, value.
+const (
+	additionalReference = "01"
+	additionalAcquirer  = "02"
+)
+
+// Trace identifies one request on the link. STAN and RRN are allocated by the connection,
+// Institution is our own acquiring institution ID (field 32).
+type Trace struct {
+	STAN          string
+	RRN           string
+	Institution   string
+	TransmittedAt time.Time
+}
+
+// InquiryRequest maps a merchant inquiry to a 0200 carrying the QR payload in field 57
+func InquiryRequest(request *model.SwitchInquiryRequest, trace Trace) (*Message, error) {
+	message, err := financialRequest(ProcessingCodeQRISInquiry, trace, request.Reference, request.AcquirerID, request.MerchantPAN, request.Amount, request.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return message, set(message, map[int]string{
+		FieldTerminalID: request.TerminalID,
+		FieldMerchantID: request.MerchantID,
+		FieldQRData:     request.QrisPayload,
+	})
+}
+
+// InquiryResponse reads the merchant the acquirer confirmed from a 0210
+func InquiryResponse(message *Message) *model.SwitchInquiryResponse {
+	nameLocation := message.Value(FieldMerchantNameLocation)
+	response := &model.SwitchInquiryResponse{
+		ResponseCode: message.Value(FieldResponseCode),
+		MerchantID:   strings.TrimSpace(message.Value(FieldMerchantID)),
+		TerminalID:   strings.TrimSpace(message.Value(FieldTerminalID)),
+	}
+
+	// Field 43 is name (25), city (13) and country (2)
+	if len(nameLocation) == 40 {
+		response.MerchantName = strings.TrimSpace(nameLocation[:25])
+		response.City = strings.TrimSpace(nameLocation[25:38])
+	}
+	return response
+}
+
+// PaymentRequest maps a payment forwarded to the switch to a 0200
+func PaymentRequest(request *model.SwitchPaymentRequest, trace Trace) (*Message, error) {
+	message, err := financialRequest(ProcessingCodeQRISPayment, trace, request.Reference, request.AcquirerID, request.MerchantPAN, request.Amount, request.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return message, set(message, map[int]string{
+		FieldTerminalID: request.TerminalID,
+		FieldMerchantID: request.MerchantID,
+	})
+}
+
+// TransactionRequest maps a debited transaction to a 0200. The customer's PaymentRequest only
+// reaches the switch through the transaction built from it, so the PIN never leaves.
+func TransactionRequest(transaction *entity.Transaction, merchantPAN string, currency string, trace Trace) (*Message, error) {
+	message, err := PaymentRequest(&model.SwitchPaymentRequest{
+		Reference:   transaction.TransactionID,
+		AcquirerID:  transaction.AcquirerID,
+		MerchantPAN: merchantPAN,
+		MerchantID:  transaction.MerchantID,
+		TerminalID:  transaction.TerminalID,
+		Amount:      transaction.Amount,
+		Currency:    currency,
+	}, trace)
+	if err != nil {
+		return nil, err
+	}
+
+	return message, message.Set(FieldSourceAccount, transaction.AccountID)
+}
+
+// PaymentResponse reads the outcome of a payment from a 0210. The RRN echoed by the switch is
+// its reference for the payment.
+func PaymentResponse(message *Message) *model.SwitchPaymentResponse {
+	return &model.SwitchPaymentResponse{
+		ResponseCode:    message.Value(FieldResponseCode),
+		SwitchReference: strings.TrimSpace(message.Value(FieldRRN)),
+	}
+}
+
+// ReversalRequest builds the 0400 cancelling an original 0200. Field 90 points back at the
+// original MTI, STAN, transmission time and acquiring institution.
+func ReversalRequest(original *Message, trace Trace) (*Message, error) {
+	message := NewMessage(original.Spec, MTIReversalRequest)
+	for _, field := range original.Fields() {
+		switch field {
+		case FieldSTAN, FieldTransmissionDateTime, FieldLocalTime, FieldLocalDate, FieldQRData:
+			continue
+		}
+		message.fields[field] = original.fields[field]
+	}
+
+	acquiring := original.Value(FieldAcquiringInstitution)
+	if len(acquiring) > 11 {
+		return nil, fmt.Errorf("%w: acquiring institution %q is too long", ErrInvalidMessage, acquiring)
+	}
+	originalData := original.MTI + original.Value(FieldSTAN) + original.Value(FieldTransmissionDateTime) +
+		strings.Repeat("0", 11-len(acquiring)) + acquiring
+
+	return message, set(message, map[int]string{
+		FieldTransmissionDateTime: trace.TransmittedAt.UTC().Format("0102150405"),
+		FieldSTAN:                 trace.STAN,
+		FieldLocalTime:            trace.TransmittedAt.Format("150405"),
+		FieldLocalDate:            trace.TransmittedAt.Format("0102"),
+		FieldOriginalDataElements: originalData,
+	})
+}
+
+// ReversalResponse reads the outcome of a reversal from a 0410
+func ReversalResponse(message *Message) *model.SwitchReversalResponse {
+	return &model.SwitchReversalResponse{
+		ResponseCode: message.Value(FieldResponseCode),
+	}
+}
+
+// NetworkRequest builds an 0800 for sign-on, sign-off or echo
+func NetworkRequest(code string, trace Trace) (*Message, error) {
+	message := NewMessage(QRISSpec, MTINetworkManagementRequest)
+	return message, set(message, map[int]string{
+		FieldTransmissionDateTime:  trace.TransmittedAt.UTC().Format("0102150405"),
+		FieldSTAN:                  trace.STAN,
+		FieldNetworkManagementCode: code,
+	})
+}
+
+// Response builds the answer to a request: the response MTI with the request's fields echoed
+// and the response code set
+func Response(request *Message, responseCode string) (*Message, error) {
+	mti, err := strconv.Atoi(request.MTI)
+	if err != nil || len(request.MTI) != 4 {
+		return nil, fmt.Errorf("%w: invalid MTI %q", ErrInvalidMessage, request.MTI)
+	}
+
+	response := NewMessage(request.Spec, fmt.Sprintf("%04d", mti+10))
+	for field, value := range request.fields {
+		response.fields[field] = value
+	}
+	return response, response.Set(FieldResponseCode, responseCode)
+}
+
+// AdditionalData reads the reference and acquirer GUID from field 48
+func AdditionalData(message *Message) (reference string, acquirerID string) {
+	data := message.Value(FieldAdditionalData)
+	for len(data) >= 4 {
+		length, err := strconv.Atoi(data[2:4])
+		if err != nil || len(data) < 4+length {
+			break
+		}
+
+		switch data[:2] {
+		case additionalReference:
+			reference = data[4 : 4+length]
+		case additionalAcquirer:
+			acquirerID = data[4 : 4+length]
+		}
+		data = data[4+length:]
+	}
+	return reference, acquirerID
+}
+
+func financialRequest(processingCode string, trace Trace, reference string, acquirerID string, merchantPAN string, amount float64, currency string) (*Message, error) {
+	if len(reference) > 99 || len(acquirerID) > 99 {
+		return nil, fmt.Errorf("%w: reference and acquirer must be shorter than 100 characters", ErrInvalidMessage)
+	}
+
+	message := NewMessage(QRISSpec, MTIFinancialRequest)
+	fields := map[int]string{
+		FieldProcessingCode:       processingCode,
+		FieldAmount:               strconv.FormatInt(int64(math.Round(amount*100)), 10),
+		FieldTransmissionDateTime: trace.TransmittedAt.UTC().Format("0102150405"),
+		FieldSTAN:                 trace.STAN,
+		FieldLocalTime:            trace.TransmittedAt.Format("150405"),
+		FieldLocalDate:            trace.TransmittedAt.Format("0102"),
+		FieldAcquiringInstitution: trace.Institution,
+		FieldRRN:                  trace.RRN,
+		FieldCurrency:             currency,
+		FieldAdditionalData: fmt.Sprintf("%s%02d%s%s%02d%s",
+			additionalReference, len(reference), reference,
+			additionalAcquirer, len(acquirerID), acquirerID),
+		FieldDestinationAccount: merchantPAN,
+	}
+
+	// The first 8 digits of a merchant PAN are the acquirer's national institution number
+	if len(merchantPAN) >= 8 {
+		fields[FieldReceivingInstitution] = merchantPAN[:8]
+	}
+
+	return message, set(message, fields)
+}
+
+// set stores every non-empty field, stopping at the first invalid one
+func set(message *Message, fields map[int]string) error {
+	for field, value := range fields {
+		if value == "" {
+			continue
+		}
+		if err := message.Set(field, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
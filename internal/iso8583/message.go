@@ -0,0 +1,307 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidMessage wraps every packing and unpacking failure
+var ErrInvalidMessage = errors.New("iso8583: invalid message")
+
+// Message is an ISO 8583 message: an MTI and the data elements it carries, kept as strings
+// (hex strings for Binary fields) and validated against the spec when set
+type Message struct {
+	Spec   *Spec
+	MTI    string
+	fields map[int]string
+}
+
+func NewMessage(spec *Spec, mti string) *Message {
+	return &Message{
+		Spec:   spec,
+		MTI:    mti,
+		fields: make(map[int]string),
+	}
+}
+
+// Set stores a field, padding short fixed-length values as their content requires
+func (m *Message) Set(field int, value string) error {
+	spec, ok := m.Spec.Fields[field]
+	if !ok {
+		return fmt.Errorf("%w: field %d is not defined in spec %s", ErrInvalidMessage, field, m.Spec.Name)
+	}
+
+	if spec.Content == Bytes {
+		value = strings.ToUpper(value)
+	}
+	if spec.LengthType == Fixed {
+		value = pad(spec, value)
+	}
+	if err := validate(field, spec, value); err != nil {
+		return err
+	}
+
+	m.fields[field] = value
+	return nil
+}
+
+// Get returns a field and whether it is present
+func (m *Message) Get(field int) (string, bool) {
+	value, ok := m.fields[field]
+	return value, ok
+}
+
+// Value returns a field, or an empty string when it is absent
+func (m *Message) Value(field int) string {
+	return m.fields[field]
+}
+
+// Fields returns the numbers of the present fields in ascending order
+func (m *Message) Fields() []int {
+	fields := make([]int, 0, len(m.fields))
+	for field := range m.fields {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+// Pack encodes the message as MTI, bitmap and fields. A secondary bitmap is added when any
+// field above 64 is present.
+func (m *Message) Pack() ([]byte, error) {
+	if len(m.MTI) != 4 {
+		return nil, fmt.Errorf("%w: MTI must have 4 digits, got %q", ErrInvalidMessage, m.MTI)
+	}
+
+	data, err := encode(m.MTI, m.Spec.MTIEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("%w: MTI: %v", ErrInvalidMessage, err)
+	}
+
+	fields := m.Fields()
+	bitmap := make([]byte, 8)
+	if len(fields) > 0 && fields[len(fields)-1] > 64 {
+		bitmap = make([]byte, 16)
+		bitmap[0] |= 0x80
+	}
+	for _, field := range fields {
+		bitmap[(field-1)/8] |= 0x80 >> ((field - 1) % 8)
+	}
+
+	if m.Spec.BitmapEncoding == ASCII {
+		data = append(data, strings.ToUpper(hex.EncodeToString(bitmap))...)
+	} else {
+		data = append(data, bitmap...)
+	}
+
+	for _, field := range fields {
+		encoded, err := packField(m.Spec.Fields[field], m.fields[field])
+		if err != nil {
+			return nil, fmt.Errorf("%w: field %d: %v", ErrInvalidMessage, field, err)
+		}
+		data = append(data, encoded...)
+	}
+
+	return data, nil
+}
+
+// Unpack decodes a message packed with the same spec
+func Unpack(spec *Spec, data []byte) (*Message, error) {
+	reader := &reader{data: data}
+
+	mtiLength := 4
+	if spec.MTIEncoding == BCD {
+		mtiLength = 2
+	}
+	raw, err := reader.read(mtiLength)
+	if err != nil {
+		return nil, fmt.Errorf("%w: MTI: %v", ErrInvalidMessage, err)
+	}
+	message := NewMessage(spec, decode(raw, spec.MTIEncoding, 4))
+
+	bitmap, err := readBitmap(reader, spec.BitmapEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bitmap: %v", ErrInvalidMessage, err)
+	}
+
+	for field := 2; field <= len(bitmap)*8; field++ {
+		if bitmap[(field-1)/8]&(0x80>>((field-1)%8)) == 0 {
+			continue
+		}
+
+		fieldSpec, ok := spec.Fields[field]
+		if !ok {
+			return nil, fmt.Errorf("%w: field %d is not defined in spec %s", ErrInvalidMessage, field, spec.Name)
+		}
+
+		value, err := unpackField(reader, fieldSpec)
+		if err != nil {
+			return nil, fmt.Errorf("%w: field %d: %v", ErrInvalidMessage, field, err)
+		}
+		if err := validate(field, fieldSpec, value); err != nil {
+			return nil, err
+		}
+		message.fields[field] = value
+	}
+
+	if reader.remaining() > 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidMessage, reader.remaining())
+	}
+	return message, nil
+}
+
+func readBitmap(reader *reader, encoding Encoding) ([]byte, error) {
+	size := 8
+	if encoding == ASCII {
+		size = 16
+	}
+
+	read := func() ([]byte, error) {
+		raw, err := reader.read(size)
+		if err != nil || encoding != ASCII {
+			return raw, err
+		}
+		return hex.DecodeString(string(raw))
+	}
+
+	bitmap, err := read()
+	if err != nil {
+		return nil, err
+	}
+	if bitmap[0]&0x80 != 0 {
+		secondary, err := read()
+		if err != nil {
+			return nil, err
+		}
+		bitmap = append(bitmap, secondary...)
+	}
+	return bitmap, nil
+}
+
+func packField(spec *FieldSpec, value string) ([]byte, error) {
+	encoded, err := encode(value, spec.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	length := len(value)
+	if spec.Encoding == Binary {
+		length = len(encoded)
+	}
+
+	switch spec.LengthType {
+	case LLVAR:
+		prefix, err := encode(fmt.Sprintf("%02d", length), spec.LengthEncoding)
+		return append(prefix, encoded...), err
+	case LLLVAR:
+		digits := fmt.Sprintf("%03d", length)
+		if spec.LengthEncoding == BCD {
+			digits = "0" + digits
+		}
+		prefix, err := encode(digits, spec.LengthEncoding)
+		return append(prefix, encoded...), err
+	default:
+		return encoded, nil
+	}
+}
+
+func unpackField(reader *reader, spec *FieldSpec) (string, error) {
+	length := spec.Length
+	if spec.LengthType != Fixed {
+		digits := 2
+		if spec.LengthType == LLLVAR {
+			digits = 3
+			if spec.LengthEncoding == BCD {
+				digits = 4
+			}
+		}
+
+		raw, err := reader.read(encodedSize(digits, spec.LengthEncoding))
+		if err != nil {
+			return "", err
+		}
+		if length, err = strconv.Atoi(decode(raw, spec.LengthEncoding, digits)); err != nil {
+			return "", fmt.Errorf("invalid length prefix %q", raw)
+		}
+		if length > spec.Length {
+			return "", fmt.Errorf("length %d exceeds maximum %d", length, spec.Length)
+		}
+	}
+
+	size := length
+	if spec.Encoding == BCD {
+		size = encodedSize(length, BCD)
+	}
+	raw, err := reader.read(size)
+	if err != nil {
+		return "", err
+	}
+	return decode(raw, spec.Encoding, length), nil
+}
+
+// pad fills a short fixed-length value: zeros on the left for numbers, spaces on the right otherwise
+func pad(spec *FieldSpec, value string) string {
+	width := spec.Length
+	if spec.Encoding == Binary {
+		width *= 2
+	}
+	if len(value) >= width {
+		return value
+	}
+
+	if spec.Content == Numeric {
+		return strings.Repeat("0", width-len(value)) + value
+	}
+	if spec.Content == Bytes {
+		return value + strings.Repeat("0", width-len(value))
+	}
+	return value + strings.Repeat(" ", width-len(value))
+}
+
+func validate(field int, spec *FieldSpec, value string) error {
+	length := len(value)
+	if spec.Encoding == Binary {
+		length /= 2
+	}
+
+	if spec.LengthType == Fixed && length != spec.Length {
+		return fmt.Errorf("%w: field %d must have length %d, got %d", ErrInvalidMessage, field, spec.Length, length)
+	}
+	if length > spec.Length {
+		return fmt.Errorf("%w: field %d exceeds maximum length %d", ErrInvalidMessage, field, spec.Length)
+	}
+
+	switch spec.Content {
+	case Numeric:
+		if strings.Trim(value, "0123456789") != "" {
+			return fmt.Errorf("%w: field %d must be numeric", ErrInvalidMessage, field)
+		}
+	case Bytes:
+		if _, err := hex.DecodeString(value); err != nil {
+			return fmt.Errorf("%w: field %d must be a hex string", ErrInvalidMessage, field)
+		}
+	}
+	return nil
+}
+
+type reader struct {
+	data     []byte
+	position int
+}
+
+func (r *reader) read(size int) ([]byte, error) {
+	if r.position+size > len(r.data) {
+		return nil, fmt.Errorf("need %d bytes at offset %d, have %d", size, r.position, r.remaining())
+	}
+	raw := r.data[r.position : r.position+size]
+	r.position += size
+	return raw, nil
+}
+
+func (r *reader) remaining() int {
+	return len(r.data) - r.position
+}
@@ -0,0 +1,310 @@
+package iso8583
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"maps"
+	"testing"
+)
+
+// bcdSpec covers the encodings QRISSpec does not use: BCD MTI and fields, an ASCII bitmap and
+// binary fields with BCD length prefixes
+var bcdSpec = &Spec{
+	Name:           "BCD",
+	MTIEncoding:    BCD,
+	BitmapEncoding: ASCII,
+	Fields: map[int]*FieldSpec{
+		FieldPAN:                   llvar("Primary Account Number", Numeric, 19, BCD),
+		FieldProcessingCode:        fixed("Processing Code", Numeric, 6, BCD),
+		FieldAmount:                fixed("Amount, Transaction", Numeric, 12, BCD),
+		FieldSTAN:                  fixed("System Trace Audit Number", Numeric, 6, BCD),
+		FieldTerminalID:            fixed("Card Acceptor Terminal ID", Alphanumeric, 8, ASCII),
+		52:                         fixed("PIN Data", Bytes, 8, Binary),
+		55:                         {Description: "ICC Data", Content: Bytes, LengthType: LLLVAR, Length: 255, Encoding: Binary, LengthEncoding: BCD},
+		FieldQRData:                lllvar("Additional Data, National", Numeric, 999, BCD),
+		FieldNetworkManagementCode: fixed("Network Management Information Code", Numeric, 3, BCD),
+	},
+}
+
+func newTestMessage(t *testing.T, spec *Spec, mti string, fields map[int]string) *Message {
+	t.Helper()
+	message := NewMessage(spec, mti)
+	for field, value := range fields {
+		if err := message.Set(field, value); err != nil {
+			t.Fatalf("Set(%d, %q): %v", field, value, err)
+		}
+	}
+	return message
+}
+
+func TestPackUnpackRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   *Spec
+		mti    string
+		fields map[int]string
+		packed string
+	}{
+		{
+			name: "echo with secondary bitmap",
+			spec: QRISSpec,
+			mti:  MTINetworkManagementRequest,
+			fields: map[int]string{
+				FieldTransmissionDateTime:  "1019090000",
+				FieldSTAN:                  "000001",
+				FieldNetworkManagementCode: NetworkEcho,
+			},
+			packed: hex.EncodeToString([]byte("0800")) +
+				"8220000000000000" + "0400000000000000" +
+				hex.EncodeToString([]byte("1019090000"+"000001"+"301")),
+		},
+		{
+			name: "financial response with primary bitmap only",
+			spec: QRISSpec,
+			mti:  MTIFinancialResponse,
+			fields: map[int]string{
+				FieldSTAN:         "000042",
+				FieldRRN:          "629100000042",
+				FieldResponseCode: "00",
+			},
+			packed: hex.EncodeToString([]byte("0210")) +
+				"002000000a000000" +
+				hex.EncodeToString([]byte("000042"+"629100000042"+"00")),
+		},
+		{
+			name: "financial request with LLVAR and LLLVAR fields",
+			spec: QRISSpec,
+			mti:  MTIFinancialRequest,
+			fields: map[int]string{
+				FieldPAN:                  "9360001234567890",
+				FieldProcessingCode:       ProcessingCodeQRISPayment,
+				FieldAmount:               "000000150000",
+				FieldSTAN:                 "000007",
+				FieldAcquiringInstitution: "93600014",
+				FieldTerminalID:           "T0000001",
+				FieldMerchantID:           "M00000000000001",
+				FieldCurrency:             "360",
+				FieldQRData:               "00020101021226620014ID.CO.QRIS.WWW",
+				FieldDestinationAccount:   "ID1020000000001",
+			},
+		},
+		{
+			name: "reversal with original data elements",
+			spec: QRISSpec,
+			mti:  MTIReversalRequest,
+			fields: map[int]string{
+				FieldAmount:               "000000150000",
+				FieldSTAN:                 "000008",
+				FieldOriginalDataElements: "020000000710190900000000000000000000000000",
+				FieldReceivingInstitution: "93600099",
+			},
+		},
+		{
+			name: "BCD odd-length PAN, binary fields and ASCII bitmap",
+			spec: bcdSpec,
+			mti:  MTIFinancialRequest,
+			fields: map[int]string{
+				FieldPAN:            "936000123456789",
+				FieldProcessingCode: ProcessingCodeQRISPayment,
+				FieldSTAN:           "000009",
+				52:                  "0123456789ABCDEF",
+				55:                  "9F2608C2A1",
+				FieldQRData:         "12345",
+			},
+			packed: "0200" + hex.EncodeToString([]byte("6020000000001280")) +
+				"15" + "0936000123456789" + "260000" + "000009" +
+				"0123456789abcdef" + "0005" + "9f2608c2a1" + "0005" + "012345",
+		},
+		{
+			name:   "BCD network management with secondary bitmap",
+			spec:   bcdSpec,
+			mti:    MTINetworkManagementResponse,
+			fields: map[int]string{FieldSTAN: "000010", FieldNetworkManagementCode: NetworkSignOn},
+			packed: "0810" + hex.EncodeToString([]byte("8020000000000000"+"0400000000000000")) +
+				"000010" + "0001",
+		},
+		{
+			name:   "no fields",
+			spec:   QRISSpec,
+			mti:    MTINetworkManagementResponse,
+			packed: hex.EncodeToString([]byte("0810")) + "0000000000000000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := newTestMessage(t, tt.spec, tt.mti, tt.fields)
+			packed, err := message.Pack()
+			if err != nil {
+				t.Fatalf("Pack: %v", err)
+			}
+			if tt.packed != "" && hex.EncodeToString(packed) != tt.packed {
+				t.Fatalf("Pack\n got %s\nwant %s", hex.EncodeToString(packed), tt.packed)
+			}
+
+			unpacked, err := Unpack(tt.spec, packed)
+			if err != nil {
+				t.Fatalf("Unpack: %v", err)
+			}
+			if unpacked.MTI != tt.mti {
+				t.Fatalf("MTI = %q, want %q", unpacked.MTI, tt.mti)
+			}
+			got := make(map[int]string)
+			for _, field := range unpacked.Fields() {
+				got[field] = unpacked.Value(field)
+			}
+			want := make(map[int]string)
+			for _, field := range message.Fields() {
+				want[field] = message.Value(field)
+			}
+			if !maps.Equal(got, want) {
+				t.Fatalf("fields\n got %v\nwant %v", got, want)
+			}
+
+			repacked, err := unpacked.Pack()
+			if err != nil || !bytes.Equal(repacked, packed) {
+				t.Fatalf("repacked %x, error %v, want %x", repacked, err, packed)
+			}
+		})
+	}
+}
+
+func TestSetPadsAndValidates(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   int
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "numeric padded with zeros", field: FieldAmount, value: "150000", want: "000000150000"},
+		{name: "alphanumeric padded with spaces", field: FieldTerminalID, value: "T1", want: "T1      "},
+		{name: "variable length kept", field: FieldPAN, value: "93600012", want: "93600012"},
+		{name: "undefined field", field: 5, value: "1", wantErr: true},
+		{name: "fixed too long", field: FieldResponseCode, value: "000", wantErr: true},
+		{name: "variable too long", field: FieldAcquiringInstitution, value: "123456789012", wantErr: true},
+		{name: "letters in numeric", field: FieldSTAN, value: "00000A", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := NewMessage(QRISSpec, MTIFinancialRequest)
+			err := message.Set(tt.field, tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidMessage) {
+					t.Fatalf("Set(%d, %q) error = %v, want ErrInvalidMessage", tt.field, tt.value, err)
+				}
+				if _, ok := message.Get(tt.field); ok {
+					t.Fatalf("invalid field %d kept", tt.field)
+				}
+				return
+			}
+			if err != nil || message.Value(tt.field) != tt.want {
+				t.Fatalf("Set(%d, %q) = %q, error %v, want %q", tt.field, tt.value, message.Value(tt.field), err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackInvalidMTI(t *testing.T) {
+	tests := []struct {
+		name string
+		spec *Spec
+		mti  string
+	}{
+		{name: "short", spec: QRISSpec, mti: "200"},
+		{name: "long", spec: QRISSpec, mti: "02000"},
+		{name: "letters in BCD", spec: bcdSpec, mti: "02A0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewMessage(tt.spec, tt.mti).Pack(); !errors.Is(err, ErrInvalidMessage) {
+				t.Fatalf("Pack error = %v, want ErrInvalidMessage", err)
+			}
+		})
+	}
+}
+
+func TestUnpackMalformed(t *testing.T) {
+	ascii := func(parts ...string) []byte {
+		var data []byte
+		for _, part := range parts {
+			data = append(data, part...)
+		}
+		return data
+	}
+	bitmap := func(hexBitmap string) string {
+		raw, _ := hex.DecodeString(hexBitmap)
+		return string(raw)
+	}
+
+	tests := []struct {
+		name string
+		spec *Spec
+		data []byte
+	}{
+		{name: "empty", spec: QRISSpec, data: nil},
+		{name: "short MTI", spec: QRISSpec, data: ascii("08")},
+		{name: "short bitmap", spec: QRISSpec, data: ascii("0800", bitmap("82200000"))},
+		{name: "missing secondary bitmap", spec: QRISSpec, data: ascii("0800", bitmap("8220000000000000"))},
+		{name: "short fixed field", spec: QRISSpec, data: ascii("0810", bitmap("0020000000000000"), "0001")},
+		{name: "short LLVAR prefix", spec: QRISSpec, data: ascii("0200", bitmap("4000000000000000"), "1")},
+		{name: "short LLVAR value", spec: QRISSpec, data: ascii("0200", bitmap("4000000000000000"), "16", "93600012")},
+		{name: "non-numeric LLVAR prefix", spec: QRISSpec, data: ascii("0200", bitmap("4000000000000000"), "1X", "93600012")},
+		{name: "LLVAR over maximum", spec: QRISSpec, data: ascii("0200", bitmap("0000000080000000"), "12", "123456789012")},
+		{name: "short LLLVAR value", spec: QRISSpec, data: ascii("0200", bitmap("0000000000000080"), "010", "abc")},
+		{name: "undefined field", spec: QRISSpec, data: ascii("0200", bitmap("0800000000000000"), "1")},
+		{name: "letters in numeric field", spec: QRISSpec, data: ascii("0810", bitmap("0020000000000000"), "00000A")},
+		{name: "trailing bytes", spec: QRISSpec, data: ascii("0810", bitmap("0020000000000000"), "000001", "00")},
+		{name: "ASCII bitmap not hex", spec: bcdSpec, data: append([]byte{0x08, 0x00}, "ZZ00000000000000"...)},
+		{name: "BCD length over maximum", spec: bcdSpec, data: append(append([]byte{0x02, 0x00}, "0000000000000200"...), 0x02, 0x56)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := Unpack(tt.spec, tt.data)
+			if !errors.Is(err, ErrInvalidMessage) {
+				t.Fatalf("Unpack(%x) = %v, error %v, want ErrInvalidMessage", tt.data, message, err)
+			}
+		})
+	}
+}
+
+// TestUnpackTruncated cuts a valid message at every byte: no prefix may unpack
+func TestUnpackTruncated(t *testing.T) {
+	tests := []struct {
+		name    string
+		message *Message
+	}{
+		{
+			name: "QRIS",
+			message: newTestMessage(t, QRISSpec, MTIFinancialRequest, map[int]string{
+				FieldPAN:                   "9360001234567890",
+				FieldAmount:                "150000",
+				FieldSTAN:                  "000007",
+				FieldQRData:                "000201010212",
+				FieldNetworkManagementCode: NetworkEcho,
+			}),
+		},
+		{
+			name: "BCD",
+			message: newTestMessage(t, bcdSpec, MTIFinancialRequest, map[int]string{
+				FieldPAN:    "936000123456789",
+				52:          "0123456789ABCDEF",
+				55:          "9F2608C2A1",
+				FieldQRData: "12345",
+			}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packed, err := tt.message.Pack()
+			if err != nil {
+				t.Fatalf("Pack: %v", err)
+			}
+			for size := range len(packed) {
+				if _, err := Unpack(tt.message.Spec, packed[:size]); !errors.Is(err, ErrInvalidMessage) {
+					t.Fatalf("Unpack of %d of %d bytes: error %v, want ErrInvalidMessage", size, len(packed), err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package iso8583
+
+// Message type indicators used on the QRIS interbank link
+const (
+	MTIFinancialRequest          = "0200"
+	MTIFinancialResponse         = "0210"
+	MTIReversalRequest           = "0400"
+	MTIReversalResponse          = "0410"
+	MTINetworkManagementRequest  = "0800"
+	MTINetworkManagementResponse = "0810"
+)
+
+// Field numbers of the data elements used by the mapping helpers
+const (
+	FieldPAN                   = 2
+	FieldProcessingCode        = 3
+	FieldAmount                = 4
+	FieldTransmissionDateTime  = 7
+	FieldSTAN                  = 11
+	FieldLocalTime             = 12
+	FieldLocalDate             = 13
+	FieldSettlementDate        = 15
+	FieldMerchantType          = 18
+	FieldAcquiringInstitution  = 32
+	FieldForwardingInstitution = 33
+	FieldRRN                   = 37
+	FieldApprovalCode          = 38
+	FieldResponseCode          = 39
+	FieldTerminalID            = 41
+	FieldMerchantID            = 42
+	FieldMerchantNameLocation  = 43
+	FieldAdditionalData        = 48
+	FieldCurrency              = 49
+	FieldQRData                = 57
+	FieldNetworkManagementCode = 70
+	FieldOriginalDataElements  = 90
+	FieldReceivingInstitution  = 100
+	FieldSourceAccount         = 102
+	FieldDestinationAccount    = 103
+)
+
+// Processing and network management codes of the national QRIS switching spec
+const (
+	ProcessingCodeQRISPayment = "260000"
+
+	NetworkSignOn  = "001"
+	NetworkSignOff = "002"
+	NetworkEcho    = "301"
+)
+
+// QRISSpec is the ISO 8583:1987 layout of the national QRIS switching spec: ASCII MTI and
+// fields with a binary bitmap
+var QRISSpec = &Spec{
+	Name:           "QRIS",
+	MTIEncoding:    ASCII,
+	BitmapEncoding: Binary,
+	Fields: map[int]*FieldSpec{
+		FieldPAN:                   llvar("Primary Account Number", Numeric, 19, ASCII),
+		FieldProcessingCode:        fixed("Processing Code", Numeric, 6, ASCII),
+		FieldAmount:                fixed("Amount, Transaction", Numeric, 12, ASCII),
+		FieldTransmissionDateTime:  fixed("Transmission Date and Time", Numeric, 10, ASCII),
+		FieldSTAN:                  fixed("System Trace Audit Number", Numeric, 6, ASCII),
+		FieldLocalTime:             fixed("Time, Local Transaction", Numeric, 6, ASCII),
+		FieldLocalDate:             fixed("Date, Local Transaction", Numeric, 4, ASCII),
+		FieldSettlementDate:        fixed("Date, Settlement", Numeric, 4, ASCII),
+		FieldMerchantType:          fixed("Merchant Type", Numeric, 4, ASCII),
+		FieldAcquiringInstitution:  llvar("Acquiring Institution ID", Numeric, 11, ASCII),
+		FieldForwardingInstitution: llvar("Forwarding Institution ID", Numeric, 11, ASCII),
+		FieldRRN:                   fixed("Retrieval Reference Number", Alphanumeric, 12, ASCII),
+		FieldApprovalCode:          fixed("Authorization ID Response", Alphanumeric, 6, ASCII),
+		FieldResponseCode:          fixed("Response Code", Alphanumeric, 2, ASCII),
+		FieldTerminalID:            fixed("Card Acceptor Terminal ID", Alphanumeric, 8, ASCII),
+		FieldMerchantID:            fixed("Card Acceptor ID Code", Alphanumeric, 15, ASCII),
+		FieldMerchantNameLocation:  fixed("Card Acceptor Name/Location", Alphanumeric, 40, ASCII),
+		FieldAdditionalData:        lllvar("Additional Data, Private", Alphanumeric, 999, ASCII),
+		FieldCurrency:              fixed("Currency Code, Transaction", Numeric, 3, ASCII),
+		FieldQRData:                lllvar("Additional Data, National (QR payload)", Alphanumeric, 999, ASCII),
+		FieldNetworkManagementCode: fixed("Network Management Information Code", Numeric, 3, ASCII),
+		FieldOriginalDataElements:  fixed("Original Data Elements", Numeric, 42, ASCII),
+		FieldReceivingInstitution:  llvar("Receiving Institution ID", Numeric, 11, ASCII),
+		FieldSourceAccount:         llvar("Account Identification 1", Alphanumeric, 28, ASCII),
+		FieldDestinationAccount:    llvar("Account Identification 2", Alphanumeric, 28, ASCII),
+	},
+}
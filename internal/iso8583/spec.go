@@ -0,0 +1,68 @@
+package iso8583
+
+// Encoding is how a field's characters, or a length prefix, are written on the wire
+type Encoding int
+
+const (
+	// ASCII writes one byte per character
+	ASCII Encoding = iota
+	// BCD packs two decimal digits per byte, left padded with a zero for odd lengths
+	BCD
+	// Binary writes raw bytes; the field value is given as a hex string
+	Binary
+)
+
+// LengthType tells whether a field is fixed or variable length
+type LengthType int
+
+const (
+	Fixed LengthType = iota
+	// LLVAR fields are prefixed with a two digit length
+	LLVAR
+	// LLLVAR fields are prefixed with a three digit length
+	LLLVAR
+)
+
+// Content restricts the characters of a field and decides how short fixed values are padded
+type Content int
+
+const (
+	// Numeric fields hold digits and are left padded with zeros
+	Numeric Content = iota
+	// Alphanumeric fields hold printable characters and are right padded with spaces
+	Alphanumeric
+	// Bytes fields hold binary data as a hex string
+	Bytes
+)
+
+// FieldSpec describes one data element. Length is the exact length of fixed fields and the
+// maximum of variable ones, in characters (digits for BCD, bytes for Binary).
+type FieldSpec struct {
+	Description    string
+	Content        Content
+	LengthType     LengthType
+	Length         int
+	Encoding       Encoding
+	LengthEncoding Encoding
+}
+
+// Spec is a message layout: how the MTI and bitmap are encoded and the fields it may carry
+type Spec struct {
+	Name           string
+	MTIEncoding    Encoding
+	BitmapEncoding Encoding
+	Fields         map[int]*FieldSpec
+}
+
+// Fixed-length and variable-length field constructors for spec tables
+func fixed(description string, content Content, length int, encoding Encoding) *FieldSpec {
+	return &FieldSpec{Description: description, Content: content, LengthType: Fixed, Length: length, Encoding: encoding}
+}
+
+func llvar(description string, content Content, length int, encoding Encoding) *FieldSpec {
+	return &FieldSpec{Description: description, Content: content, LengthType: LLVAR, Length: length, Encoding: encoding, LengthEncoding: encoding}
+}
+
+func lllvar(description string, content Content, length int, encoding Encoding) *FieldSpec {
+	return &FieldSpec{Description: description, Content: content, LengthType: LLLVAR, Length: length, Encoding: encoding, LengthEncoding: encoding}
+}
@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+
+	"golang-clean-architecture/internal/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/redis/go-redis/v9"
+)
+
+const namespace = "qris"
+
+// Metrics holds the Prometheus registry and the business and runtime collectors exposed on /metrics
+type Metrics struct {
+	Registry            *prometheus.Registry
+	HTTPRequestDuration *prometheus.HistogramVec
+	PaymentTotal        *prometheus.CounterVec
+	InquiryTotal        *prometheus.CounterVec
+	RiskDecisionTotal   *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	m := &Metrics{
+		Registry: registry,
+		HTTPRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route and status.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"method", "route", "status"}),
+		PaymentTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "payment_total",
+			Help:      "QRIS payments by outcome.",
+		}, []string{"outcome"}),
+		InquiryTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "inquiry_total",
+			Help:      "QRIS inquiries by merchant data source (cache or database).",
+		}, []string{"source"}),
+		RiskDecisionTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "risk_decision_total",
+			Help:      "Payment risk screening decisions by action and mode (enforced or shadow).",
+		}, []string{"action", "mode"}),
+	}
+	registry.MustRegister(m.HTTPRequestDuration, m.PaymentTotal, m.InquiryTotal, m.RiskDecisionTotal)
+
+	return m
+}
+
+// ObservePayment counts a payment by outcome, derived from the error code it failed with or,
+// for payments waiting on a step-up challenge, from the response status
+func (m *Metrics) ObservePayment(response *model.PaymentResponse, err error) {
+	if m == nil {
+		return
+	}
+
+	outcome := "success"
+	if response != nil && response.Status == model.PaymentStatusChallengeRequired {
+		outcome = model.PaymentStatusChallengeRequired
+	}
+	if err != nil {
+		outcome = "error"
+		var appErr *model.Error
+		if errors.As(err, &appErr) {
+			outcome = paymentOutcomes[appErr.Code]
+			if outcome == "" {
+				outcome = "error"
+			}
+		}
+	}
+	m.PaymentTotal.WithLabelValues(outcome).Inc()
+}
+
+var paymentOutcomes = map[model.ErrorCode]string{
+	model.ErrCodeValidationFailed:      "validation_failed",
+	model.ErrCodeInquiryExpired:        "inquiry_expired",
+	model.ErrCodeAccountNotFound:       "account_not_found",
+	model.ErrCodeInvalidPIN:            "invalid_pin",
+	model.ErrCodePINLocked:             "pin_locked",
+	model.ErrCodeInsufficientBalance:   "insufficient_balance",
+	model.ErrCodeTransactionConflict:   "lock_conflict",
+	model.ErrCodeSwitchDeclined:        "switch_declined",
+	model.ErrCodeSwitchTimeout:         "switch_timeout",
+	model.ErrCodeSwitchUnavailable:     "switch_unavailable",
+	model.ErrCodeCurrencyMismatch:      "currency_mismatch",
+	model.ErrCodeFxQuoteExpired:        "fx_quote_expired",
+	model.ErrCodeTxnLimitExceeded:      "limit_exceeded",
+	model.ErrCodeDailyLimitExceeded:    "limit_exceeded",
+	model.ErrCodeMonthlyLimitExceeded:  "limit_exceeded",
+	model.ErrCodeVelocityLimitExceeded: "limit_exceeded",
+	model.ErrCodeRiskDeclined:          "risk_declined",
+	model.ErrCodeStepUpRequired:        "step_up_required",
+	model.ErrCodeChallengeExpired:      "challenge_expired",
+	model.ErrCodeInvalidOTP:            "invalid_otp",
+	model.ErrCodeOTPAttemptsExceeded:   "otp_locked",
+	model.ErrCodeOTPDeliveryFailed:     "otp_delivery_failed",
+	model.ErrCodeDeviceNotRegistered:   "device_not_registered",
+	model.ErrCodeInvalidDeviceSig:      "invalid_device_signature",
+	model.ErrCodeBlacklisted:           "blacklisted",
+}
+
+// ObserveInquiry counts an inquiry by the source its merchant data came from
+func (m *Metrics) ObserveInquiry(source string) {
+	if m == nil {
+		return
+	}
+	m.InquiryTotal.WithLabelValues(source).Inc()
+}
+
+// ObserveRiskDecision counts a screening decision; shadow decisions are recorded but not enforced
+func (m *Metrics) ObserveRiskDecision(action string, shadow bool) {
+	if m == nil {
+		return
+	}
+
+	mode := "enforced"
+	if shadow {
+		mode = "shadow"
+	}
+	m.RiskDecisionTotal.WithLabelValues(action, mode).Inc()
+}
+
+// RegisterDatabase exposes connection pool statistics from sql.DB.Stats()
+func (m *Metrics) RegisterDatabase(db *sql.DB) {
+	m.Registry.MustRegister(collectors.NewDBStatsCollector(db, "postgres"))
+}
+
+// RegisterRedis exposes connection pool statistics from redis.Client.PoolStats()
+func (m *Metrics) RegisterRedis(client *redis.Client) {
+	m.Registry.MustRegister(&redisPoolCollector{client: client})
+}
+
+// RegisterCounterFunc exposes a monotonically increasing value owned by another component,
+// e.g. background worker statistics
+func (m *Metrics) RegisterCounterFunc(name string, help string, function func() float64) {
+	m.Registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, function))
+}
@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	preforkWorkersKey  = "metrics:prefork:workers"
+	preforkSnapshotKey = "metrics:prefork:snapshot:%s"
+)
+
+// PreforkGatherer aggregates metrics across prefork child processes. Every process publishes
+// a snapshot of its registry to Redis; a scrape served by any child returns the snapshots of
+// all live processes, each labelled with worker=<pid> so they can be summed in queries.
+type PreforkGatherer struct {
+	Registry    *prometheus.Registry
+	RedisClient *redis.Client
+	Log         *logrus.Logger
+	Interval    time.Duration
+	Worker      string
+}
+
+func NewPreforkGatherer(registry *prometheus.Registry, redisClient *redis.Client, log *logrus.Logger, interval time.Duration) *PreforkGatherer {
+	return &PreforkGatherer{
+		Registry:    registry,
+		RedisClient: redisClient,
+		Log:         log,
+		Interval:    interval,
+		Worker:      strconv.Itoa(os.Getpid()),
+	}
+}
+
+// Start publishes this process' snapshot every Interval until ctx is cancelled
+func (g *PreforkGatherer) Start(ctx context.Context) {
+	ticker := time.NewTicker(g.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := g.publish(ctx); err != nil {
+			g.Log.Warnf("Failed to publish prefork metrics snapshot: %+v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			cleanup := context.Background()
+			g.RedisClient.SRem(cleanup, preforkWorkersKey, g.Worker)
+			g.RedisClient.Del(cleanup, fmt.Sprintf(preforkSnapshotKey, g.Worker))
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (g *PreforkGatherer) publish(ctx context.Context) error {
+	families, err := g.Registry.Gather()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	encoder := expfmt.NewEncoder(&buf, expfmt.NewFormat(expfmt.TypeProtoDelim))
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			return err
+		}
+	}
+
+	pipe := g.RedisClient.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf(preforkSnapshotKey, g.Worker), buf.Bytes(), 3*g.Interval)
+	pipe.SAdd(ctx, preforkWorkersKey, g.Worker)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Gather returns the local registry merged with the latest snapshot of every other worker
+func (g *PreforkGatherer) Gather() ([]*dto.MetricFamily, error) {
+	local, err := g.Registry.Gather()
+	if err != nil {
+		return nil, err
+	}
+	gatherers := prometheus.Gatherers{withWorkerLabel(local, g.Worker)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	workers, err := g.RedisClient.SMembers(ctx, preforkWorkersKey).Result()
+	if err != nil {
+		g.Log.Warnf("Failed to list prefork metrics workers: %+v", err)
+		return gatherers.Gather()
+	}
+
+	for _, worker := range workers {
+		if worker == g.Worker {
+			continue
+		}
+
+		snapshot, err := g.RedisClient.Get(ctx, fmt.Sprintf(preforkSnapshotKey, worker)).Bytes()
+		if errors.Is(err, redis.Nil) {
+			// The worker stopped publishing, its snapshot expired
+			g.RedisClient.SRem(ctx, preforkWorkersKey, worker)
+			continue
+		}
+		if err != nil {
+			g.Log.Warnf("Failed to read prefork metrics snapshot for worker %s: %+v", worker, err)
+			continue
+		}
+
+		families, err := decodeSnapshot(snapshot)
+		if err != nil {
+			g.Log.Warnf("Failed to decode prefork metrics snapshot for worker %s: %+v", worker, err)
+			continue
+		}
+		gatherers = append(gatherers, withWorkerLabel(families, worker))
+	}
+
+	return gatherers.Gather()
+}
+
+func decodeSnapshot(snapshot []byte) ([]*dto.MetricFamily, error) {
+	decoder := expfmt.NewDecoder(bytes.NewReader(snapshot), expfmt.NewFormat(expfmt.TypeProtoDelim))
+
+	var families []*dto.MetricFamily
+	for {
+		family := new(dto.MetricFamily)
+		if err := decoder.Decode(family); err != nil {
+			if errors.Is(err, io.EOF) {
+				return families, nil
+			}
+			return nil, err
+		}
+		families = append(families, family)
+	}
+}
+
+func withWorkerLabel(families []*dto.MetricFamily, worker string) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		for _, family := range families {
+			for _, metric := range family.Metric {
+				metric.Label = append(metric.Label, &dto.LabelPair{
+					Name:  proto.String("worker"),
+					Value: proto.String(worker),
+				})
+			}
+		}
+		return families, nil
+	})
+}
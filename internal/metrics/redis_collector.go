@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	redisHitsDesc     = prometheus.NewDesc("redis_pool_hits_total", "Number of times a free connection was found in the pool.", nil, nil)
+	redisMissesDesc   = prometheus.NewDesc("redis_pool_misses_total", "Number of times a free connection was not found in the pool.", nil, nil)
+	redisTimeoutsDesc = prometheus.NewDesc("redis_pool_timeouts_total", "Number of times a wait timeout occurred.", nil, nil)
+	redisTotalDesc    = prometheus.NewDesc("redis_pool_connections", "Number of connections in the pool.", nil, nil)
+	redisIdleDesc     = prometheus.NewDesc("redis_pool_idle_connections", "Number of idle connections in the pool.", nil, nil)
+	redisStaleDesc    = prometheus.NewDesc("redis_pool_stale_connections_total", "Number of stale connections removed from the pool.", nil, nil)
+)
+
+// redisPoolCollector reads go-redis pool statistics on every scrape
+type redisPoolCollector struct {
+	client *redis.Client
+}
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- redisHitsDesc
+	ch <- redisMissesDesc
+	ch <- redisTimeoutsDesc
+	ch <- redisTotalDesc
+	ch <- redisIdleDesc
+	ch <- redisStaleDesc
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(redisHitsDesc, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(redisMissesDesc, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(redisTimeoutsDesc, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(redisTotalDesc, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(redisIdleDesc, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(redisStaleDesc, prometheus.CounterValue, float64(stats.StaleConns))
+}
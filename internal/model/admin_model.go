@@ -0,0 +1,50 @@
+package model
+
+// CreateApiClientRequest registers a new HMAC API client
+type CreateApiClientRequest struct {
+	ClientID string `json:"client_id" validate:"required,max=100"`
+}
+
+// ApiClientResponse describes an API client. ClientSecret is only set when the client is created.
+type ApiClientResponse struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	Status       string `json:"status"`
+	CreatedAt    string `json:"created_at,omitempty"`
+}
+
+// CreateAccountRequest opens a customer account protected by a 6-digit PIN. With Totp the
+// account answers step-up challenges with an authenticator app instead of a sent OTP.
+type CreateAccountRequest struct {
+	AccountID string  `json:"account_id" validate:"required,max=100"`
+	Pin       string  `json:"pin" validate:"required,numeric,len=6"`
+	Balance   float64 `json:"balance" validate:"gte=0"`
+	Currency  string  `json:"currency" validate:"required,len=3,uppercase"`
+	LimitTier string  `json:"limit_tier" validate:"omitempty,max=20"`
+	Totp      bool    `json:"totp"`
+}
+
+// AccountResponse describes an account. TotpSecret is only set when the account is created.
+type AccountResponse struct {
+	AccountID  string  `json:"account_id"`
+	Balance    float64 `json:"balance"`
+	Currency   string  `json:"currency"`
+	LimitTier  string  `json:"limit_tier,omitempty"`
+	TotpSecret string  `json:"totp_secret,omitempty"`
+}
+
+// CreateMerchantRequest registers a merchant that can receive QRIS payments
+type CreateMerchantRequest struct {
+	MerchantID   string `json:"merchant_id" validate:"required,max=100"`
+	MerchantName string `json:"merchant_name" validate:"required,max=255"`
+	MCC          string `json:"mcc" validate:"required,numeric,len=4"`
+	City         string `json:"city" validate:"required,max=100"`
+}
+
+type MerchantResponse struct {
+	MerchantID   string `json:"merchant_id"`
+	MerchantName string `json:"merchant_name"`
+	MCC          string `json:"mcc"`
+	City         string `json:"city"`
+	IsActive     bool   `json:"is_active"`
+}
@@ -0,0 +1,53 @@
+package model
+
+import "encoding/json"
+
+// SearchAuditEventsRequest pages through audit events; From and To (RFC 3339, any offset) select
+// events created in [From, To). Audit times are UTC.
+type SearchAuditEventsRequest struct {
+	Actor      string `json:"actor" validate:"omitempty,max=100"`
+	Action     string `json:"action" validate:"omitempty,max=50"`
+	TargetType string `json:"target_type" validate:"omitempty,max=50"`
+	TargetID   string `json:"target_id" validate:"omitempty,max=100"`
+	From       string `json:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To         string `json:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Page       int    `json:"page" validate:"gte=1"`
+	Size       int    `json:"size" validate:"gte=1,lte=1000"`
+}
+
+type AuditEventResponse struct {
+	EventID    int64           `json:"event_id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Chain      int16           `json:"chain"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+	CreatedAt  string          `json:"created_at"`
+}
+
+type AuditEventsResponse struct {
+	Events []*AuditEventResponse `json:"events"`
+	Paging *PageMetadata         `json:"paging"`
+}
+
+// VerifyAuditResponse is the outcome of recomputing the audit chains. Heads holds the hash of the
+// last event checked per chain; comparing them with copies kept elsewhere also detects removed
+// tail events.
+type VerifyAuditResponse struct {
+	Valid         bool              `json:"valid"`
+	Events        int64             `json:"events"`
+	Heads         []*AuditChainHead `json:"heads,omitempty"`
+	BrokenEventID int64             `json:"broken_event_id,omitempty"`
+	Problem       string            `json:"problem,omitempty"`
+}
+
+type AuditChainHead struct {
+	Chain    int16  `json:"chain"`
+	Events   int64  `json:"events"`
+	HeadHash string `json:"head_hash"`
+}
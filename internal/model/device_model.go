@@ -0,0 +1,22 @@
+package model
+
+// RegisterDeviceRequest binds a customer device to an account. Only operators register devices,
+// once the customer is verified out of band; PublicKey is the device's base64 Ed25519 public key.
+type RegisterDeviceRequest struct {
+	AccountID string `json:"-" validate:"required,max=100"`
+	DeviceID  string `json:"device_id" validate:"required,max=64"`
+	PublicKey string `json:"public_key" validate:"required,base64"`
+}
+
+// RevokeDeviceRequest unbinds a device, e.g. a lost phone
+type RevokeDeviceRequest struct {
+	AccountID string `json:"account_id" validate:"required,max=100"`
+	DeviceID  string `json:"device_id" validate:"required,max=64"`
+}
+
+type DeviceResponse struct {
+	AccountID string `json:"account_id"`
+	DeviceID  string `json:"device_id"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
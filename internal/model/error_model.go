@@ -0,0 +1,349 @@
+package model
+
+import "fmt"
+
+// ErrorCode is a stable machine-readable error identifier returned to API clients.
+// Codes must never be renamed once released; add new ones instead.
+type ErrorCode string
+
+const (
+	ErrCodeBadRequest            ErrorCode = "BAD_REQUEST"
+	ErrCodeValidationFailed      ErrorCode = "VALIDATION_FAILED"
+	ErrCodeMissingAuthHeaders    ErrorCode = "MISSING_AUTH_HEADERS"
+	ErrCodeInvalidClientKey      ErrorCode = "INVALID_CLIENT_KEY"
+	ErrCodeInvalidSignature      ErrorCode = "INVALID_SIGNATURE"
+	ErrCodeQrisPayloadRequired   ErrorCode = "QRIS_PAYLOAD_REQUIRED"
+	ErrCodeQrisInvalidFormat     ErrorCode = "QRIS_INVALID_FORMAT"
+	ErrCodeQrisInvalidCRC        ErrorCode = "QRIS_INVALID_CRC"
+	ErrCodeMerchantNotFound      ErrorCode = "MERCHANT_NOT_FOUND"
+	ErrCodeInquiryExpired        ErrorCode = "INQUIRY_EXPIRED"
+	ErrCodeAccountNotFound       ErrorCode = "ACCOUNT_NOT_FOUND"
+	ErrCodeInvalidPIN            ErrorCode = "INVALID_PIN"
+	ErrCodePINLocked             ErrorCode = "PIN_LOCKED"
+	ErrCodeInsufficientBalance   ErrorCode = "INSUFFICIENT_BALANCE"
+	ErrCodeTransactionConflict   ErrorCode = "TRANSACTION_CONFLICT"
+	ErrCodeTransactionIDRequired ErrorCode = "TRANSACTION_ID_REQUIRED"
+	ErrCodeTransactionNotFound   ErrorCode = "TRANSACTION_NOT_FOUND"
+	ErrCodeTransactionNotPending ErrorCode = "TRANSACTION_NOT_PENDING"
+	ErrCodeInvalidTransition     ErrorCode = "INVALID_STATUS_TRANSITION"
+	ErrCodeApiClientNotFound     ErrorCode = "API_CLIENT_NOT_FOUND"
+	ErrCodeAlreadyExists         ErrorCode = "ALREADY_EXISTS"
+	ErrCodeInvalidReportRange    ErrorCode = "INVALID_REPORT_RANGE"
+	ErrCodeInvalidSettlement     ErrorCode = "INVALID_SETTLEMENT_FILE"
+	ErrCodeReconRunNotFound      ErrorCode = "RECONCILIATION_NOT_FOUND"
+	ErrCodeSwitchDeclined        ErrorCode = "SWITCH_DECLINED"
+	ErrCodeSwitchTimeout         ErrorCode = "SWITCH_TIMEOUT"
+	ErrCodeSwitchUnavailable     ErrorCode = "SWITCH_UNAVAILABLE"
+	ErrCodeCurrencyNotSupported  ErrorCode = "CURRENCY_NOT_SUPPORTED"
+	ErrCodeCurrencyMismatch      ErrorCode = "CURRENCY_MISMATCH"
+	ErrCodeFxRateUnavailable     ErrorCode = "FX_RATE_UNAVAILABLE"
+	ErrCodeFxQuoteExpired        ErrorCode = "FX_QUOTE_EXPIRED"
+	ErrCodeTxnLimitExceeded      ErrorCode = "TRANSACTION_LIMIT_EXCEEDED"
+	ErrCodeDailyLimitExceeded    ErrorCode = "DAILY_LIMIT_EXCEEDED"
+	ErrCodeMonthlyLimitExceeded  ErrorCode = "MONTHLY_LIMIT_EXCEEDED"
+	ErrCodeVelocityLimitExceeded ErrorCode = "VELOCITY_LIMIT_EXCEEDED"
+	ErrCodeRiskDeclined          ErrorCode = "RISK_DECLINED"
+	ErrCodeStepUpRequired        ErrorCode = "STEP_UP_REQUIRED"
+	ErrCodeChallengeExpired      ErrorCode = "CHALLENGE_EXPIRED"
+	ErrCodeInvalidOTP            ErrorCode = "INVALID_OTP"
+	ErrCodeOTPAttemptsExceeded   ErrorCode = "OTP_ATTEMPTS_EXCEEDED"
+	ErrCodeOTPDeliveryFailed     ErrorCode = "OTP_DELIVERY_FAILED"
+	ErrCodeDeviceNotRegistered   ErrorCode = "DEVICE_NOT_REGISTERED"
+	ErrCodeDeviceNotFound        ErrorCode = "DEVICE_NOT_FOUND"
+	ErrCodeInvalidDeviceSig      ErrorCode = "INVALID_DEVICE_SIGNATURE"
+	ErrCodeBlacklisted           ErrorCode = "BLACKLISTED"
+	ErrCodeListEntryNotFound     ErrorCode = "LIST_ENTRY_NOT_FOUND"
+	ErrCodeUnauthorized          ErrorCode = "UNAUTHORIZED"
+	ErrCodeForbidden             ErrorCode = "FORBIDDEN"
+	ErrCodeNotFound              ErrorCode = "NOT_FOUND"
+	ErrCodeMethodNotAllowed      ErrorCode = "METHOD_NOT_ALLOWED"
+	ErrCodeTooManyRequests       ErrorCode = "TOO_MANY_REQUESTS"
+	ErrCodeServiceUnavailable    ErrorCode = "SERVICE_UNAVAILABLE"
+	ErrCodeInternal              ErrorCode = "INTERNAL_ERROR"
+)
+
+const (
+	LanguageEnglish    = "en"
+	LanguageIndonesian = "id"
+)
+
+// errorDefinition is the HTTP status and localized messages for an ErrorCode
+type errorDefinition struct {
+	Status   int
+	Messages map[string]string
+}
+
+var errorCatalog = map[ErrorCode]errorDefinition{
+	ErrCodeBadRequest: {400, map[string]string{
+		LanguageEnglish:    "Invalid request body",
+		LanguageIndonesian: "Format permintaan tidak valid",
+	}},
+	ErrCodeValidationFailed: {400, map[string]string{
+		LanguageEnglish:    "Request validation failed",
+		LanguageIndonesian: "Validasi permintaan gagal",
+	}},
+	ErrCodeMissingAuthHeaders: {401, map[string]string{
+		LanguageEnglish:    "Missing required headers: X-Client-Key, X-Timestamp, X-Signature",
+		LanguageIndonesian: "Header wajib tidak ada: X-Client-Key, X-Timestamp, X-Signature",
+	}},
+	ErrCodeInvalidClientKey: {401, map[string]string{
+		LanguageEnglish:    "Invalid client key",
+		LanguageIndonesian: "Client key tidak valid",
+	}},
+	ErrCodeInvalidSignature: {401, map[string]string{
+		LanguageEnglish:    "Invalid signature",
+		LanguageIndonesian: "Signature tidak valid",
+	}},
+	ErrCodeQrisPayloadRequired: {400, map[string]string{
+		LanguageEnglish:    "QRIS payload is required",
+		LanguageIndonesian: "Payload QRIS wajib diisi",
+	}},
+	ErrCodeQrisInvalidFormat: {400, map[string]string{
+		LanguageEnglish:    "QRIS payload is malformed",
+		LanguageIndonesian: "Format payload QRIS tidak valid",
+	}},
+	ErrCodeQrisInvalidCRC: {400, map[string]string{
+		LanguageEnglish:    "QRIS payload checksum is invalid",
+		LanguageIndonesian: "Checksum payload QRIS tidak valid",
+	}},
+	ErrCodeMerchantNotFound: {404, map[string]string{
+		LanguageEnglish:    "Merchant not found",
+		LanguageIndonesian: "Merchant tidak ditemukan",
+	}},
+	ErrCodeInquiryExpired: {400, map[string]string{
+		LanguageEnglish:    "Invalid or expired inquiry ID",
+		LanguageIndonesian: "Inquiry ID tidak valid atau sudah kedaluwarsa",
+	}},
+	ErrCodeAccountNotFound: {404, map[string]string{
+		LanguageEnglish:    "Account not found",
+		LanguageIndonesian: "Akun tidak ditemukan",
+	}},
+	ErrCodeInvalidPIN: {401, map[string]string{
+		LanguageEnglish:    "Invalid PIN",
+		LanguageIndonesian: "PIN salah",
+	}},
+	ErrCodePINLocked: {423, map[string]string{
+		LanguageEnglish:    "PIN is locked after too many failed attempts",
+		LanguageIndonesian: "PIN terblokir karena terlalu banyak percobaan gagal",
+	}},
+	ErrCodeInsufficientBalance: {400, map[string]string{
+		LanguageEnglish:    "Insufficient balance",
+		LanguageIndonesian: "Saldo tidak mencukupi",
+	}},
+	ErrCodeTransactionConflict: {409, map[string]string{
+		LanguageEnglish:    "Transaction conflict, please retry",
+		LanguageIndonesian: "Terjadi konflik transaksi, silakan coba lagi",
+	}},
+	ErrCodeTransactionIDRequired: {400, map[string]string{
+		LanguageEnglish:    "Transaction ID is required",
+		LanguageIndonesian: "ID transaksi wajib diisi",
+	}},
+	ErrCodeTransactionNotFound: {404, map[string]string{
+		LanguageEnglish:    "Transaction not found",
+		LanguageIndonesian: "Transaksi tidak ditemukan",
+	}},
+	ErrCodeTransactionNotPending: {409, map[string]string{
+		LanguageEnglish:    "Transaction is not pending",
+		LanguageIndonesian: "Transaksi tidak dalam status pending",
+	}},
+	ErrCodeInvalidTransition: {400, map[string]string{
+		LanguageEnglish:    "Transaction cannot be moved to the requested status",
+		LanguageIndonesian: "Status transaksi tidak dapat diubah ke status yang diminta",
+	}},
+	ErrCodeApiClientNotFound: {404, map[string]string{
+		LanguageEnglish:    "API client not found",
+		LanguageIndonesian: "API client tidak ditemukan",
+	}},
+	ErrCodeAlreadyExists: {409, map[string]string{
+		LanguageEnglish:    "Resource already exists",
+		LanguageIndonesian: "Data sudah ada",
+	}},
+	ErrCodeInvalidReportRange: {400, map[string]string{
+		LanguageEnglish:    "Report range is invalid or too long",
+		LanguageIndonesian: "Rentang laporan tidak valid atau terlalu panjang",
+	}},
+	ErrCodeInvalidSettlement: {400, map[string]string{
+		LanguageEnglish:    "Settlement file could not be parsed",
+		LanguageIndonesian: "File settlement tidak dapat dibaca",
+	}},
+	ErrCodeReconRunNotFound: {404, map[string]string{
+		LanguageEnglish:    "Reconciliation run not found",
+		LanguageIndonesian: "Hasil rekonsiliasi tidak ditemukan",
+	}},
+	ErrCodeSwitchDeclined: {422, map[string]string{
+		LanguageEnglish:    "Payment was declined by the merchant's bank",
+		LanguageIndonesian: "Pembayaran ditolak oleh bank merchant",
+	}},
+	ErrCodeSwitchTimeout: {504, map[string]string{
+		LanguageEnglish:    "Merchant's bank did not respond in time, the payment was cancelled",
+		LanguageIndonesian: "Bank merchant tidak merespons tepat waktu, pembayaran dibatalkan",
+	}},
+	ErrCodeSwitchUnavailable: {502, map[string]string{
+		LanguageEnglish:    "Merchant's bank is unreachable",
+		LanguageIndonesian: "Bank merchant tidak dapat dihubungi",
+	}},
+	ErrCodeCurrencyNotSupported: {422, map[string]string{
+		LanguageEnglish:    "Currency is not supported",
+		LanguageIndonesian: "Mata uang tidak didukung",
+	}},
+	ErrCodeCurrencyMismatch: {422, map[string]string{
+		LanguageEnglish:    "Account currency does not match the inquiry quote, inquire again in the account currency",
+		LanguageIndonesian: "Mata uang rekening tidak sesuai dengan kuotasi inquiry, lakukan inquiry ulang dengan mata uang rekening",
+	}},
+	ErrCodeFxRateUnavailable: {502, map[string]string{
+		LanguageEnglish:    "Exchange rate is unavailable",
+		LanguageIndonesian: "Kurs tidak tersedia",
+	}},
+	ErrCodeFxQuoteExpired: {409, map[string]string{
+		LanguageEnglish:    "Exchange rate lock has expired, inquire again",
+		LanguageIndonesian: "Penguncian kurs telah berakhir, lakukan inquiry ulang",
+	}},
+	ErrCodeTxnLimitExceeded: {422, map[string]string{
+		LanguageEnglish:    "Amount exceeds the single transaction limit",
+		LanguageIndonesian: "Nominal melebihi batas per transaksi",
+	}},
+	ErrCodeDailyLimitExceeded: {422, map[string]string{
+		LanguageEnglish:    "Daily spending limit exceeded",
+		LanguageIndonesian: "Batas transaksi harian terlampaui",
+	}},
+	ErrCodeMonthlyLimitExceeded: {422, map[string]string{
+		LanguageEnglish:    "Monthly spending limit exceeded",
+		LanguageIndonesian: "Batas transaksi bulanan terlampaui",
+	}},
+	ErrCodeVelocityLimitExceeded: {422, map[string]string{
+		LanguageEnglish:    "Too many transactions this hour",
+		LanguageIndonesian: "Terlalu banyak transaksi dalam satu jam",
+	}},
+	ErrCodeRiskDeclined: {403, map[string]string{
+		LanguageEnglish:    "Payment was declined for security reasons",
+		LanguageIndonesian: "Pembayaran ditolak demi keamanan",
+	}},
+	ErrCodeStepUpRequired: {403, map[string]string{
+		LanguageEnglish:    "Additional verification is required for this payment",
+		LanguageIndonesian: "Pembayaran ini memerlukan verifikasi tambahan",
+	}},
+	ErrCodeChallengeExpired: {400, map[string]string{
+		LanguageEnglish:    "Invalid or expired challenge ID",
+		LanguageIndonesian: "Challenge ID tidak valid atau sudah kedaluwarsa",
+	}},
+	ErrCodeInvalidOTP: {401, map[string]string{
+		LanguageEnglish:    "Invalid verification code",
+		LanguageIndonesian: "Kode verifikasi salah",
+	}},
+	ErrCodeOTPAttemptsExceeded: {423, map[string]string{
+		LanguageEnglish:    "Too many failed verification attempts, the payment was cancelled",
+		LanguageIndonesian: "Terlalu banyak percobaan verifikasi gagal, pembayaran dibatalkan",
+	}},
+	ErrCodeOTPDeliveryFailed: {502, map[string]string{
+		LanguageEnglish:    "Verification code could not be sent",
+		LanguageIndonesian: "Kode verifikasi tidak dapat dikirim",
+	}},
+	ErrCodeDeviceNotRegistered: {403, map[string]string{
+		LanguageEnglish:    "Device is not registered for this account",
+		LanguageIndonesian: "Perangkat tidak terdaftar untuk rekening ini",
+	}},
+	ErrCodeInvalidDeviceSig: {401, map[string]string{
+		LanguageEnglish:    "Invalid device signature",
+		LanguageIndonesian: "Tanda tangan perangkat tidak valid",
+	}},
+	ErrCodeDeviceNotFound: {404, map[string]string{
+		LanguageEnglish:    "Device not found",
+		LanguageIndonesian: "Perangkat tidak ditemukan",
+	}},
+	ErrCodeBlacklisted: {403, map[string]string{
+		LanguageEnglish:    "Transaction is not allowed",
+		LanguageIndonesian: "Transaksi tidak diizinkan",
+	}},
+	ErrCodeListEntryNotFound: {404, map[string]string{
+		LanguageEnglish:    "List entry not found",
+		LanguageIndonesian: "Entri daftar tidak ditemukan",
+	}},
+	ErrCodeUnauthorized: {401, map[string]string{
+		LanguageEnglish:    "Unauthorized",
+		LanguageIndonesian: "Tidak memiliki otorisasi",
+	}},
+	ErrCodeForbidden: {403, map[string]string{
+		LanguageEnglish:    "Forbidden",
+		LanguageIndonesian: "Akses ditolak",
+	}},
+	ErrCodeNotFound: {404, map[string]string{
+		LanguageEnglish:    "Resource not found",
+		LanguageIndonesian: "Sumber daya tidak ditemukan",
+	}},
+	ErrCodeMethodNotAllowed: {405, map[string]string{
+		LanguageEnglish:    "Method not allowed",
+		LanguageIndonesian: "Metode tidak diizinkan",
+	}},
+	ErrCodeTooManyRequests: {429, map[string]string{
+		LanguageEnglish:    "Too many requests",
+		LanguageIndonesian: "Terlalu banyak permintaan",
+	}},
+	ErrCodeServiceUnavailable: {503, map[string]string{
+		LanguageEnglish:    "Service unavailable",
+		LanguageIndonesian: "Layanan tidak tersedia",
+	}},
+	ErrCodeInternal: {500, map[string]string{
+		LanguageEnglish:    "Internal server error",
+		LanguageIndonesian: "Terjadi kesalahan pada server",
+	}},
+}
+
+// Error is a domain error carrying a stable code from the error catalog
+type Error struct {
+	Code ErrorCode
+}
+
+func NewError(code ErrorCode) *Error {
+	return &Error{Code: code}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message(LanguageEnglish))
+}
+
+// Status returns the HTTP status mapped to the error code
+func (e *Error) Status() int {
+	if definition, ok := errorCatalog[e.Code]; ok {
+		return definition.Status
+	}
+	return 500
+}
+
+// Message returns the message in the requested language, falling back to English
+func (e *Error) Message(language string) string {
+	definition, ok := errorCatalog[e.Code]
+	if !ok {
+		return string(e.Code)
+	}
+	if message, ok := definition.Messages[language]; ok {
+		return message
+	}
+	return definition.Messages[LanguageEnglish]
+}
+
+// ErrorCodeForStatus maps a bare HTTP status (e.g. from the router) to a generic error code.
+// Each code must carry the same status, so that a 401 or 429 from Fiber or its middleware is not
+// reported as BAD_REQUEST.
+func ErrorCodeForStatus(status int) ErrorCode {
+	switch status {
+	case 400:
+		return ErrCodeBadRequest
+	case 401:
+		return ErrCodeUnauthorized
+	case 403:
+		return ErrCodeForbidden
+	case 404:
+		return ErrCodeNotFound
+	case 405:
+		return ErrCodeMethodNotAllowed
+	case 429:
+		return ErrCodeTooManyRequests
+	case 503:
+		return ErrCodeServiceUnavailable
+	}
+
+	if status >= 500 {
+		return ErrCodeInternal
+	}
+	return ErrCodeBadRequest
+}
@@ -0,0 +1,15 @@
+package model
+
+// DependencyStatus is the result of probing a single dependency
+type DependencyStatus struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Message   string  `json:"message,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// ReadinessResponse reports whether the instance can serve traffic and why
+type ReadinessResponse struct {
+	Status string                       `json:"status"`
+	Checks map[string]*DependencyStatus `json:"checks"`
+}
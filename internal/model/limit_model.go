@@ -0,0 +1,25 @@
+package model
+
+// AccountLimitsRequest asks for the spending limits of an account
+type AccountLimitsRequest struct {
+	AccountID string `json:"account_id" validate:"required,max=100"`
+}
+
+// LimitUsage is one limit window. Limit and Remaining are omitted when the window is unlimited.
+type LimitUsage struct {
+	Limit     *float64 `json:"limit,omitempty"`
+	Used      float64  `json:"used"`
+	Remaining *float64 `json:"remaining,omitempty"`
+}
+
+// AccountLimitsResponse reports an account's tier and what is left of each limit, amounts in the
+// account currency. The hourly count is per clock hour.
+type AccountLimitsResponse struct {
+	AccountID            string      `json:"account_id"`
+	Tier                 string      `json:"tier"`
+	Currency             string      `json:"currency"`
+	SingleTransactionMax *float64    `json:"single_transaction_max,omitempty"`
+	Daily                *LimitUsage `json:"daily"`
+	Monthly              *LimitUsage `json:"monthly"`
+	HourlyCount          *LimitUsage `json:"hourly_count"`
+}
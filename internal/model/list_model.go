@@ -0,0 +1,56 @@
+package model
+
+// CreateListEntryRequest adds a value to the blacklist or the whitelist. QR_HASH values are the
+// hex SHA-256 of the QRIS payload; ExpiresAt (RFC 3339) is optional.
+type CreateListEntryRequest struct {
+	List      string `json:"list" validate:"required,oneof=BLACKLIST WHITELIST"`
+	EntryType string `json:"entry_type" validate:"required,oneof=MERCHANT_ID ACCOUNT_ID NMID QR_HASH"`
+	Value     string `json:"value" validate:"required,max=100"`
+	Reason    string `json:"reason" validate:"required,max=255"`
+	ExpiresAt string `json:"expires_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Actor     string `json:"-" validate:"required"`
+}
+
+// UpdateListEntryRequest changes the reason or the expiry of a live entry; an empty ExpiresAt
+// makes it permanent
+type UpdateListEntryRequest struct {
+	EntryID   int64  `json:"-" validate:"required,gt=0"`
+	Reason    string `json:"reason" validate:"required,max=255"`
+	ExpiresAt string `json:"expires_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Actor     string `json:"-" validate:"required"`
+}
+
+type RemoveListEntryRequest struct {
+	EntryID int64  `json:"-" validate:"required,gt=0"`
+	Actor   string `json:"-" validate:"required"`
+}
+
+// SearchListEntriesRequest pages through entries, live ones only unless IncludeRemoved
+type SearchListEntriesRequest struct {
+	List           string `json:"list" validate:"omitempty,oneof=BLACKLIST WHITELIST"`
+	EntryType      string `json:"entry_type" validate:"omitempty,oneof=MERCHANT_ID ACCOUNT_ID NMID QR_HASH"`
+	Value          string `json:"value" validate:"omitempty,max=100"`
+	IncludeRemoved bool   `json:"include_removed"`
+	Page           int    `json:"page" validate:"gte=1"`
+	Size           int    `json:"size" validate:"gte=1,lte=1000"`
+}
+
+type ListEntryResponse struct {
+	EntryID   int64  `json:"entry_id"`
+	List      string `json:"list"`
+	EntryType string `json:"entry_type"`
+	Value     string `json:"value"`
+	Reason    string `json:"reason"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+	CreatedBy string `json:"created_by"`
+	CreatedAt string `json:"created_at"`
+	UpdatedBy string `json:"updated_by,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+	RemovedBy string `json:"removed_by,omitempty"`
+	RemovedAt string `json:"removed_at,omitempty"`
+}
+
+type ListEntriesResponse struct {
+	Entries []*ListEntryResponse `json:"entries"`
+	Paging  *PageMetadata        `json:"paging"`
+}
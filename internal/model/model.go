@@ -6,6 +6,7 @@ type ApiResponse struct {
 	Data     interface{} `json:"data,omitempty"`
 	Message  string      `json:"message,omitempty"`
 	Metadata *Metadata   `json:"metadata,omitempty"`
+	Code     string      `json:"code,omitempty"`
 	Errors   string      `json:"errors,omitempty"`
 }
 
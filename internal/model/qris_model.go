@@ -1,13 +1,30 @@
 package model
 
+import (
+	"strconv"
+	"time"
+)
+
 // InquiryResponse represents the QRIS inquiry result
 type InquiryResponse struct {
-	MerchantID   string  `json:"merchant_id"`
-	MerchantName string  `json:"merchant_name"`
-	TerminalID   string  `json:"terminal_id"`
-	City         string  `json:"city"`
-	FixedAmount  float64 `json:"fixed_amount"`
-	InquiryID    string  `json:"inquiry_id"`
+	MerchantID   string   `json:"merchant_id"`
+	MerchantName string   `json:"merchant_name"`
+	TerminalID   string   `json:"terminal_id"`
+	City         string   `json:"city"`
+	FixedAmount  float64  `json:"fixed_amount"`
+	InquiryID    string   `json:"inquiry_id"`
+	AcquirerID   string   `json:"acquirer_id,omitempty"`
+	FxQuote      *FxQuote `json:"fx_quote,omitempty"`
+}
+
+// FxQuote converts a QR priced in a foreign currency. The rate is locked for the inquiry_id
+// until LockedUntil; Payment debits the account at this rate.
+type FxQuote struct {
+	Currency      string    `json:"currency"`
+	QuoteCurrency string    `json:"quote_currency"`
+	Rate          float64   `json:"rate"`
+	QuotedAmount  float64   `json:"quoted_amount,omitempty"`
+	LockedUntil   time.Time `json:"locked_until"`
 }
 
 // PaymentRequest represents the QRIS payment request body
@@ -17,12 +34,35 @@ type PaymentRequest struct {
 	Amount        float64 `json:"amount" validate:"required,gt=0"`
 	PaymentMethod string  `json:"payment_method" validate:"required"`
 	Pincode       string  `json:"pincode" validate:"required"`
+	DeviceID      string  `json:"device_id" validate:"omitempty,max=64"`
+	// Signature is the base64 Ed25519 signature of SigningMessage by the device's registered key
+	Signature string `json:"signature" validate:"omitempty,base64"`
+}
+
+// SigningMessage is what the customer device signs: "<inquiry_id>|<amount with 2 decimals>|<user_id>"
+func (r *PaymentRequest) SigningMessage() []byte {
+	return []byte(r.InquiryID + "|" + strconv.FormatFloat(r.Amount, 'f', 2, 64) + "|" + r.UserID)
 }
 
-// PaymentResponse represents the QRIS payment result
+const (
+	PaymentStatusProcessing        = "processing"
+	PaymentStatusChallengeRequired = "challenge_required"
+)
+
+// PaymentResponse represents the QRIS payment result. Payments needing step-up authentication
+// are challenge_required and complete once the challenge is verified.
 type PaymentResponse struct {
-	Status              string `json:"status"`
-	TransactionID       string `json:"transaction_id"`
-	Message             string `json:"message"`
-	EstimatedCompletion string `json:"estimated_completion"`
+	Status              string     `json:"status"`
+	TransactionID       string     `json:"transaction_id,omitempty"`
+	Message             string     `json:"message"`
+	EstimatedCompletion string     `json:"estimated_completion,omitempty"`
+	ChallengeID         string     `json:"challenge_id,omitempty"`
+	ChallengeMethod     string     `json:"challenge_method,omitempty"`
+	ChallengeExpiresAt  *time.Time `json:"challenge_expires_at,omitempty"`
+}
+
+// VerifyChallengeRequest answers a step-up challenge with a TOTP or the OTP that was sent
+type VerifyChallengeRequest struct {
+	ChallengeID string `json:"-" validate:"required,uuid"`
+	Code        string `json:"code" validate:"required,numeric,len=6"`
 }
@@ -0,0 +1,51 @@
+package model
+
+// ReconcileRequest describes a settlement file received from the switch for one business day
+type ReconcileRequest struct {
+	FileName       string `json:"file_name" validate:"required,max=255"`
+	SettlementDate string `json:"settlement_date" validate:"required,datetime=2006-01-02"`
+}
+
+type ReconciliationRunResponse struct {
+	RunID            string `json:"run_id"`
+	SettlementDate   string `json:"settlement_date"`
+	FileName         string `json:"file_name"`
+	TotalRows        int    `json:"total_rows"`
+	Matched          int    `json:"matched"`
+	AmountMismatch   int    `json:"amount_mismatch"`
+	MissingOnOurSide int    `json:"missing_on_our_side"`
+	Duplicate        int    `json:"duplicate"`
+	Unmatched        int    `json:"unmatched"`
+	CreatedAt        string `json:"created_at"`
+}
+
+type ReconciliationItemResponse struct {
+	LineNumber       int      `json:"line_number,omitempty"`
+	Reference        string   `json:"reference"`
+	TransactionID    string   `json:"transaction_id,omitempty"`
+	SettlementAmount *float64 `json:"settlement_amount,omitempty"`
+	OurAmount        *float64 `json:"our_amount,omitempty"`
+	OurStatus        string   `json:"our_status,omitempty"`
+	Result           string   `json:"result"`
+}
+
+// ReconciliationReportRequest pages through the items of a run, optionally by result
+type ReconciliationReportRequest struct {
+	RunID  string `json:"run_id" validate:"required,uuid"`
+	Result string `json:"result" validate:"omitempty,oneof=MATCHED AMOUNT_MISMATCH MISSING_ON_OUR_SIDE DUPLICATE UNMATCHED"`
+	Page   int    `json:"page" validate:"gte=1"`
+	Size   int    `json:"size" validate:"gte=1,lte=1000"`
+}
+
+type ReconciliationReportResponse struct {
+	Run    *ReconciliationRunResponse    `json:"run"`
+	Items  []*ReconciliationItemResponse `json:"items"`
+	Paging *PageMetadata                 `json:"paging"`
+}
+
+type PageMetadata struct {
+	Page      int   `json:"page"`
+	Size      int   `json:"size"`
+	TotalItem int64 `json:"total_item"`
+	TotalPage int64 `json:"total_page"`
+}
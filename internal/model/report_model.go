@@ -0,0 +1,46 @@
+package model
+
+import "math"
+
+// SalesReportRequest selects a merchant's transactions between two calendar days (inclusive)
+// in the reporting time zone
+type SalesReportRequest struct {
+	MerchantID string   `json:"merchant_id" validate:"required,max=100"`
+	From       string   `json:"from" validate:"required,datetime=2006-01-02"`
+	To         string   `json:"to" validate:"required,datetime=2006-01-02"`
+	GroupBy    []string `json:"group_by" validate:"unique,dive,oneof=day terminal status"`
+	Format     string   `json:"format" validate:"omitempty,oneof=json csv xlsx"`
+}
+
+// SalesReportRow aggregates transactions; grouping columns that were not requested are empty
+type SalesReportRow struct {
+	Date       string  `json:"date,omitempty"`
+	TerminalID string  `json:"terminal_id,omitempty"`
+	Status     string  `json:"status,omitempty"`
+	Count      int64   `json:"count"`
+	Gross      float64 `json:"gross"`
+	Fees       float64 `json:"fees"`
+	Net        float64 `json:"net"`
+}
+
+// Add accumulates another row into a total
+func (r *SalesReportRow) Add(row *SalesReportRow) {
+	r.Count += row.Count
+	r.Gross = roundCents(r.Gross + row.Gross)
+	r.Fees = roundCents(r.Fees + row.Fees)
+	r.Net = roundCents(r.Net + row.Net)
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+type SalesReportResponse struct {
+	MerchantID string            `json:"merchant_id"`
+	From       string            `json:"from"`
+	To         string            `json:"to"`
+	Timezone   string            `json:"timezone"`
+	GroupBy    []string          `json:"group_by"`
+	Rows       []*SalesReportRow `json:"rows"`
+	Total      SalesReportRow    `json:"total"`
+}
@@ -0,0 +1,51 @@
+package model
+
+// SwitchResponseApproved is the ISO 8583 response code for an approved request
+const SwitchResponseApproved = "00"
+
+// SwitchInquiryRequest asks the acquirer behind the switch to confirm an off-us merchant
+type SwitchInquiryRequest struct {
+	Reference   string  `json:"reference"`
+	AcquirerID  string  `json:"acquirer_id"`
+	MerchantPAN string  `json:"merchant_pan"`
+	MerchantID  string  `json:"merchant_id"`
+	TerminalID  string  `json:"terminal_id"`
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+	QrisPayload string  `json:"qris_payload"`
+}
+
+type SwitchInquiryResponse struct {
+	ResponseCode string `json:"response_code"`
+	MerchantID   string `json:"merchant_id"`
+	MerchantName string `json:"merchant_name"`
+	City         string `json:"city"`
+	TerminalID   string `json:"terminal_id"`
+}
+
+// SwitchPaymentRequest forwards a payment already debited from our customer to the acquirer
+type SwitchPaymentRequest struct {
+	Reference   string  `json:"reference"`
+	AcquirerID  string  `json:"acquirer_id"`
+	MerchantPAN string  `json:"merchant_pan"`
+	MerchantID  string  `json:"merchant_id"`
+	TerminalID  string  `json:"terminal_id"`
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+}
+
+type SwitchPaymentResponse struct {
+	ResponseCode    string `json:"response_code"`
+	SwitchReference string `json:"switch_reference"`
+}
+
+// SwitchReversalRequest cancels a payment whose outcome is unknown, e.g. after a timeout
+type SwitchReversalRequest struct {
+	Reference  string  `json:"reference"`
+	AcquirerID string  `json:"acquirer_id"`
+	Amount     float64 `json:"amount"`
+}
+
+type SwitchReversalResponse struct {
+	ResponseCode string `json:"response_code"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "context"
+
+type requestContextKey struct{}
+
+// RequestContext carries correlation identifiers for a single inbound request and, once
+// authenticated, the caller it acts for
+type RequestContext struct {
+	RequestID string
+	TraceID   string
+	SpanID    string
+	// Actor is the authenticated API client, or the operator of the admin CLI
+	Actor string
+}
+
+func WithRequestContext(ctx context.Context, requestContext *RequestContext) context.Context {
+	return context.WithValue(ctx, requestContextKey{}, requestContext)
+}
+
+// RequestContextFrom returns the correlation identifiers stored in ctx, if any
+func RequestContextFrom(ctx context.Context) (*RequestContext, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	requestContext, ok := ctx.Value(requestContextKey{}).(*RequestContext)
+	return requestContext, ok
+}
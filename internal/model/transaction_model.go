@@ -7,3 +7,33 @@ type TransactionStatusResponse struct {
 	FinalBalance  float64 `json:"final_balance"`
 	Timestamp     string  `json:"timestamp"`
 }
+
+// ExpirePendingResult summarizes one run of the pending transaction sweeper
+type ExpirePendingResult struct {
+	Scanned int `json:"scanned"`
+	Expired int `json:"expired"`
+	Failed  int `json:"failed"`
+}
+
+// TransactionResponse is the full transaction record returned to operators
+type TransactionResponse struct {
+	TransactionID    string   `json:"transaction_id"`
+	TraceID          string   `json:"trace_id"`
+	AccountID        string   `json:"account_id"`
+	MerchantID       string   `json:"merchant_id"`
+	Amount           float64  `json:"amount"`
+	Currency         string   `json:"currency"`
+	OriginalAmount   *float64 `json:"original_amount,omitempty"`
+	OriginalCurrency *string  `json:"original_currency,omitempty"`
+	FxRate           *float64 `json:"fx_rate,omitempty"`
+	Status           string   `json:"status"`
+	CreatedAt        string   `json:"created_at"`
+	UpdatedAt        string   `json:"updated_at"`
+	DebitedAt        string   `json:"debited_at,omitempty"`
+}
+
+// ForceTransitionRequest moves a stuck PENDING transaction to a final status
+type ForceTransitionRequest struct {
+	TransactionID string `json:"transaction_id" validate:"required,uuid"`
+	Status        string `json:"status" validate:"required,oneof=SUCCESS EXPIRED FAILED"`
+}
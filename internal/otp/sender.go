@@ -0,0 +1,44 @@
+package otp
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sender delivers a one-time code to the holder of an account, e.g. by SMS or push notification
+type Sender interface {
+	Send(ctx context.Context, accountID string, code string) error
+}
+
+// FakeSender delivers nothing: it keeps the last code per account in memory, for local
+// development and tests
+type FakeSender struct {
+	Log   *logrus.Logger
+	mu    sync.Mutex
+	codes map[string]string
+}
+
+func NewFakeSender(log *logrus.Logger) *FakeSender {
+	return &FakeSender{
+		Log:   log,
+		codes: make(map[string]string),
+	}
+}
+
+func (s *FakeSender) Send(ctx context.Context, accountID string, code string) error {
+	s.mu.Lock()
+	s.codes[accountID] = code
+	s.mu.Unlock()
+
+	s.Log.WithContext(ctx).Infof("Fake OTP kept for account: %s", accountID)
+	return nil
+}
+
+// LastCode returns the last code sent to the account
+func (s *FakeSender) LastCode(accountID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.codes[accountID]
+}
@@ -0,0 +1,65 @@
+package otp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// WebhookSender hands codes to the notification service that delivers them, with a JSON POST
+// of {"account_id", "code"} to URL. Any status but 2xx is a failed delivery.
+type WebhookSender struct {
+	Log     *logrus.Logger
+	URL     string
+	Timeout time.Duration
+	HTTP    *http.Client
+}
+
+func NewWebhookSender(log *logrus.Logger, url string, timeout time.Duration) *WebhookSender {
+	return &WebhookSender{
+		Log:     log,
+		URL:     url,
+		Timeout: timeout,
+		HTTP: &http.Client{
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+	}
+}
+
+type webhookRequest struct {
+	AccountID string `json:"account_id"`
+	Code      string `json:"code"`
+}
+
+func (s *WebhookSender) Send(ctx context.Context, accountID string, code string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	body, err := json.Marshal(webhookRequest{AccountID: accountID, Code: code})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := s.HTTP.Do(request)
+	if err != nil {
+		return fmt.Errorf("otp webhook failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("otp webhook returned %d", response.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package otp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestWebhookSender(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		delay   time.Duration
+		wantErr bool
+	}{
+		{name: "delivered", status: http.StatusAccepted},
+		{name: "rejected", status: http.StatusBadGateway, wantErr: true},
+		{name: "timeout", status: http.StatusOK, delay: 200 * time.Millisecond, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received webhookRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("unexpected request %s %s", r.Method, r.Header.Get("Content-Type"))
+				}
+				if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+					t.Errorf("decode body: %v", err)
+				}
+				time.Sleep(tt.delay)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			sender := NewWebhookSender(logrus.New(), server.URL, 50*time.Millisecond)
+			err := sender.Send(context.Background(), "user_123", "482913")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send error = %v, want error %v", err, tt.wantErr)
+			}
+			if received.AccountID != "user_123" || received.Code != "482913" {
+				t.Fatalf("webhook received %+v", received)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var (
+	// ErrInvalidFormat is returned for payloads that are not well-formed EMVCo TLV data
+	ErrInvalidFormat = errors.New("qris: invalid format")
+	// ErrInvalidCRC is returned when the CRC (tag 63) is missing or does not match the payload
+	ErrInvalidCRC = errors.New("qris: invalid crc")
+)
+
+const (
+	tagPayloadFormat     = "00"
+	tagPointOfInitiation = "01"
+	tagMCC               = "52"
+	tagCurrency          = "53"
+	tagAmount            = "54"
+	tagCountryCode       = "58"
+	tagMerchantName      = "59"
+	tagCity              = "60"
+	tagPostalCode        = "61"
+	tagAdditionalData    = "62"
+	tagCRC               = "63"
+
+	// tagNationalRepository is the QRIS national merchant repository template (ID.CO.QRIS.WWW)
+	tagNationalRepository = "51"
+)
+
+// MerchantAccount is a merchant account information template (tags 26-51)
+type MerchantAccount struct {
+	Tag string
+	// GUID identifies the acquirer in reverse domain form, e.g. ID.CO.BANKMANDIRI.WWW
+	GUID       string
+	PAN        string
+	MerchantID string
+	Criteria   string
+}
+
+// Payload is a decoded QRIS (EMVCo merchant presented mode) payload
+type Payload struct {
+	PointOfInitiation string
+	MerchantAccounts  []MerchantAccount
+	MCC               string
+	Currency          string
+	Amount            float64
+	CountryCode       string
+	MerchantName      string
+	City              string
+	PostalCode        string
+	BillNumber        string
+	ReferenceLabel    string
+	TerminalLabel     string
+}
+
+// Parse decodes a QRIS payload and verifies its CRC
+func Parse(payload string) (*Payload, error) {
+	fields, err := decode(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if fields[tagPayloadFormat] != "01" {
+		return nil, fmt.Errorf("%w: unsupported payload format %q", ErrInvalidFormat, fields[tagPayloadFormat])
+	}
+
+	crc, ok := fields[tagCRC]
+	if !ok || len(payload) < 8 || payload[len(payload)-8:len(payload)-4] != tagCRC+"04" {
+		return nil, ErrInvalidCRC
+	}
+	if crc != CRC16(payload[:len(payload)-4]) {
+		return nil, ErrInvalidCRC
+	}
+
+	result := &Payload{
+		PointOfInitiation: fields[tagPointOfInitiation],
+		MCC:               fields[tagMCC],
+		Currency:          fields[tagCurrency],
+		CountryCode:       fields[tagCountryCode],
+		MerchantName:      fields[tagMerchantName],
+		City:              fields[tagCity],
+		PostalCode:        fields[tagPostalCode],
+	}
+
+	if amount, ok := fields[tagAmount]; ok {
+		if result.Amount, err = strconv.ParseFloat(amount, 64); err != nil {
+			return nil, fmt.Errorf("%w: invalid amount %q", ErrInvalidFormat, amount)
+		}
+	}
+
+	for tag := 26; tag <= 51; tag++ {
+		template, ok := fields[strconv.Itoa(tag)]
+		if !ok {
+			continue
+		}
+		sub, err := decode(template)
+		if err != nil {
+			return nil, err
+		}
+		result.MerchantAccounts = append(result.MerchantAccounts, MerchantAccount{
+			Tag:        strconv.Itoa(tag),
+			GUID:       sub["00"],
+			PAN:        sub["01"],
+			MerchantID: sub["02"],
+			Criteria:   sub["03"],
+		})
+	}
+
+	if additional, ok := fields[tagAdditionalData]; ok {
+		sub, err := decode(additional)
+		if err != nil {
+			return nil, err
+		}
+		result.BillNumber = sub["01"]
+		result.ReferenceLabel = sub["05"]
+		result.TerminalLabel = sub["07"]
+	}
+
+	return result, nil
+}
+
+// Acquirer returns the template of the institution that acquired the merchant: the first
+// acquirer specific template (26-45), or the national repository template (51) when there is none
+func (p *Payload) Acquirer() *MerchantAccount {
+	var national *MerchantAccount
+	for i := range p.MerchantAccounts {
+		account := &p.MerchantAccounts[i]
+		if account.Tag == tagNationalRepository {
+			national = account
+			continue
+		}
+		if account.Tag <= "45" && account.GUID != "" {
+			return account
+		}
+	}
+	return national
+}
+
+// NMID returns the national merchant ID registered in the QRIS repository template (51), empty
+// when the payload has none
+func (p *Payload) NMID() string {
+	for _, account := range p.MerchantAccounts {
+		if account.Tag == tagNationalRepository {
+			return account.MerchantID
+		}
+	}
+	return ""
+}
+
+// decode splits TLV data (two digit tag, two digit length, value) into its fields
+func decode(data string) (map[string]string, error) {
+	fields := make(map[string]string)
+	for position := 0; position < len(data); {
+		if position+4 > len(data) {
+			return nil, fmt.Errorf("%w: truncated field at %d", ErrInvalidFormat, position)
+		}
+
+		tag := data[position : position+2]
+		length, err := strconv.Atoi(data[position+2 : position+4])
+		if err != nil || length < 0 || position+4+length > len(data) {
+			return nil, fmt.Errorf("%w: invalid length for tag %s", ErrInvalidFormat, tag)
+		}
+
+		fields[tag] = data[position+4 : position+4+length]
+		position += 4 + length
+	}
+	return fields, nil
+}
+
+// CRC16 computes the CRC-16/CCITT-FALSE checksum QRIS uses for tag 63, as four uppercase hex digits
+func CRC16(data string) string {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%04X", crc)
+}
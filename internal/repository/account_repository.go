@@ -5,6 +5,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AccountRepository struct {
@@ -22,6 +23,13 @@ func (r *AccountRepository) FindByAccountID(db *gorm.DB, account *entity.Account
 	return db.Where("account_id = ?", accountID).Take(account).Error
 }
 
+// LockByAccountID loads an account with a row lock (SELECT ... FOR UPDATE) held until the surrounding transaction ends
+func (r *AccountRepository) LockByAccountID(db *gorm.DB, account *entity.Account, accountID string) error {
+	return db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("account_id = ?", accountID).
+		Take(account).Error
+}
+
 // DeductBalance uses optimistic locking to prevent double-spend
 func (r *AccountRepository) DeductBalance(db *gorm.DB, accountID string, amount float64, expectedVersion int) error {
 	result := db.Model(&entity.Account{}).
@@ -41,3 +49,23 @@ func (r *AccountRepository) DeductBalance(db *gorm.DB, accountID string, amount
 
 	return nil
 }
+
+// CreditBalance returns funds to an account, e.g. when reversing a debit
+func (r *AccountRepository) CreditBalance(db *gorm.DB, accountID string, amount float64) error {
+	result := db.Model(&entity.Account{}).
+		Where("account_id = ?", accountID).
+		Updates(map[string]interface{}{
+			"balance": gorm.Expr("balance + ?", amount),
+			"version": gorm.Expr("version + 1"),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -21,3 +21,19 @@ func NewApiClientRepository(log *logrus.Logger) *ApiClientRepository {
 func (r *ApiClientRepository) FindByClientID(db *gorm.DB, client *entity.ApiClient, clientID string) error {
 	return db.Where("client_id = ? AND status = ?", clientID, "ACTIVE").Take(client).Error
 }
+
+func (r *ApiClientRepository) UpdateStatus(db *gorm.DB, clientID string, status string) error {
+	result := db.Model(&entity.ApiClient{}).
+		Where("client_id = ?", clientID).
+		Update("status", status)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// auditChainLockClass is the first key of the Postgres advisory locks serializing appends to each
+// audit chain, the chain is the second
+const auditChainLockClass int32 = 736_102
+
+// AuditEventQuery filters audit events; empty fields and zero times match everything.
+// Events are selected in [From, To).
+type AuditEventQuery struct {
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   string
+	From       time.Time
+	To         time.Time
+}
+
+type AuditEventRepository struct {
+	Repository[entity.AuditEvent]
+	Log *logrus.Logger
+}
+
+func NewAuditEventRepository(log *logrus.Logger) *AuditEventRepository {
+	return &AuditEventRepository{
+		Log: log,
+	}
+}
+
+// LockChain holds an audit chain until the surrounding transaction ends, so each event is
+// chained to the one appended right before it
+func (r *AuditEventRepository) LockChain(db *gorm.DB, chain int16) error {
+	return db.Exec("SELECT pg_advisory_xact_lock(?, ?)", auditChainLockClass, int32(chain)).Error
+}
+
+// FindLast loads the head of a chain; gorm.ErrRecordNotFound means the chain is empty
+func (r *AuditEventRepository) FindLast(db *gorm.DB, event *entity.AuditEvent, chain int16) error {
+	return db.Where("chain = ?", chain).Order("event_id DESC").Take(event).Error
+}
+
+// FindAfter returns the events following afterID in the order they were appended
+func (r *AuditEventRepository) FindAfter(db *gorm.DB, afterID int64, limit int) ([]entity.AuditEvent, error) {
+	var events []entity.AuditEvent
+	err := db.Where("event_id > ?", afterID).
+		Order("event_id ASC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// Search returns a page of events, newest first
+func (r *AuditEventRepository) Search(db *gorm.DB, query AuditEventQuery, offset int, limit int) ([]entity.AuditEvent, int64, error) {
+	tx := db.Model(&entity.AuditEvent{})
+	if query.Actor != "" {
+		tx = tx.Where("actor = ?", query.Actor)
+	}
+	if query.Action != "" {
+		tx = tx.Where("action = ?", query.Action)
+	}
+	if query.TargetType != "" {
+		tx = tx.Where("target_type = ?", query.TargetType)
+	}
+	if query.TargetID != "" {
+		tx = tx.Where("target_id = ?", query.TargetID)
+	}
+	if !query.From.IsZero() {
+		tx = tx.Where("created_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		tx = tx.Where("created_at < ?", query.To)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []entity.AuditEvent
+	err := tx.Order("event_id DESC").Offset(offset).Limit(limit).Find(&events).Error
+	return events, total, err
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"golang-clean-architecture/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type DeviceRepository struct {
+	Repository[entity.Device]
+	Log *logrus.Logger
+}
+
+func NewDeviceRepository(log *logrus.Logger) *DeviceRepository {
+	return &DeviceRepository{
+		Log: log,
+	}
+}
+
+// FindActive loads a device bound to the account that has not been revoked
+func (r *DeviceRepository) FindActive(db *gorm.DB, device *entity.Device, accountID string, deviceID string) error {
+	return db.Where("account_id = ? AND device_id = ? AND status = ?", accountID, deviceID, entity.DeviceStatusActive).
+		Take(device).Error
+}
+
+// Revoke unbinds an active device; gorm.ErrRecordNotFound means there was none
+func (r *DeviceRepository) Revoke(db *gorm.DB, accountID string, deviceID string) error {
+	result := db.Model(&entity.Device{}).
+		Where("account_id = ? AND device_id = ? AND status = ?", accountID, deviceID, entity.DeviceStatusActive).
+		Updates(map[string]interface{}{
+			"status":     entity.DeviceStatusRevoked,
+			"revoked_at": db.NowFunc(),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
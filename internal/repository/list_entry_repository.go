@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ListKey is a value looked up in a list, e.g. {MERCHANT_ID, M001}
+type ListKey struct {
+	EntryType string
+	Value     string
+}
+
+// ListEntryQuery filters list entries; empty fields match everything
+type ListEntryQuery struct {
+	List           string
+	EntryType      string
+	Value          string
+	IncludeRemoved bool
+}
+
+type ListEntryRepository struct {
+	Repository[entity.ListEntry]
+	Log *logrus.Logger
+}
+
+func NewListEntryRepository(log *logrus.Logger) *ListEntryRepository {
+	return &ListEntryRepository{
+		Log: log,
+	}
+}
+
+func (r *ListEntryRepository) FindByEntryID(db *gorm.DB, entry *entity.ListEntry, entryID int64) error {
+	return db.Where("entry_id = ?", entryID).Take(entry).Error
+}
+
+// FindLive returns the entries of a list matching any of the keys that are neither removed
+// nor expired at the given time
+func (r *ListEntryRepository) FindLive(db *gorm.DB, list string, keys []ListKey, now time.Time) ([]entity.ListEntry, error) {
+	match := db.Where("1 = 0")
+	for _, key := range keys {
+		match = match.Or("entry_type = ? AND value = ?", key.EntryType, key.Value)
+	}
+
+	var entries []entity.ListEntry
+	err := db.Where("list = ? AND removed_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", list, now).
+		Where(match).
+		Find(&entries).Error
+	return entries, err
+}
+
+// Search returns a page of entries, newest first
+func (r *ListEntryRepository) Search(db *gorm.DB, query ListEntryQuery, offset int, limit int) ([]entity.ListEntry, int64, error) {
+	tx := db.Model(&entity.ListEntry{})
+	if query.List != "" {
+		tx = tx.Where("list = ?", query.List)
+	}
+	if query.EntryType != "" {
+		tx = tx.Where("entry_type = ?", query.EntryType)
+	}
+	if query.Value != "" {
+		tx = tx.Where("value = ?", query.Value)
+	}
+	if !query.IncludeRemoved {
+		tx = tx.Where("removed_at IS NULL")
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []entity.ListEntry
+	err := tx.Order("entry_id DESC").Offset(offset).Limit(limit).Find(&entries).Error
+	return entries, total, err
+}
+
+// RemoveExpired marks the entries of a value that expired before now as removed by removedBy, as
+// of their expiry, so the value can be listed again
+func (r *ListEntryRepository) RemoveExpired(db *gorm.DB, list string, key ListKey, removedBy string, now time.Time) error {
+	return db.Model(&entity.ListEntry{}).
+		Where("list = ? AND entry_type = ? AND value = ? AND removed_at IS NULL AND expires_at <= ?",
+			list, key.EntryType, key.Value, now).
+		Updates(map[string]interface{}{
+			"removed_by": removedBy,
+			"removed_at": gorm.Expr("expires_at"),
+		}).Error
+}
+
+// Remove marks a live entry removed; gorm.ErrRecordNotFound means there was none
+func (r *ListEntryRepository) Remove(db *gorm.DB, entryID int64, removedBy string) error {
+	result := db.Model(&entity.ListEntry{}).
+		Where("entry_id = ? AND removed_at IS NULL", entryID).
+		Updates(map[string]interface{}{
+			"removed_by": removedBy,
+			"removed_at": db.NowFunc(),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
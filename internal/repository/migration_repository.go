@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// MigrationRepository reads the schema_migrations table maintained by golang-migrate
+type MigrationRepository struct {
+	Log *logrus.Logger
+}
+
+func NewMigrationRepository(log *logrus.Logger) *MigrationRepository {
+	return &MigrationRepository{
+		Log: log,
+	}
+}
+
+func (r *MigrationRepository) FindVersion(db *gorm.DB) (version uint, dirty bool, err error) {
+	row := db.Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Row()
+	err = row.Scan(&version, &dirty)
+	return version, dirty, err
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ReconciliationRepository struct {
+	Repository[entity.ReconciliationRun]
+	Log *logrus.Logger
+}
+
+func NewReconciliationRepository(log *logrus.Logger) *ReconciliationRepository {
+	return &ReconciliationRepository{
+		Log: log,
+	}
+}
+
+func (r *ReconciliationRepository) FindByRunID(db *gorm.DB, run *entity.ReconciliationRun, runID string) error {
+	return db.Where("run_id = ?", runID).Take(run).Error
+}
+
+// CreateItems inserts the classified settlement rows of a run with a single statement
+func (r *ReconciliationRepository) CreateItems(db *gorm.DB, items []*entity.ReconciliationItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return db.Create(&items).Error
+}
+
+// CreateUnmatchedItems records every SUCCESS transaction created in [from, to) that no item of
+// the run refers to, and returns how many were recorded
+func (r *ReconciliationRepository) CreateUnmatchedItems(db *gorm.DB, runID string, from time.Time, to time.Time) (int64, error) {
+	result := db.Exec(`
+		INSERT INTO reconciliation_items (run_id, reference, transaction_id, our_amount, our_status, result)
+		SELECT ?, t.transaction_id::text, t.transaction_id, t.amount, t.status, ?
+		FROM transactions t
+		WHERE t.status = ? AND t.created_at >= ? AND t.created_at < ?
+		AND NOT EXISTS (
+			SELECT 1 FROM reconciliation_items i WHERE i.run_id = ? AND i.transaction_id = t.transaction_id
+		)`,
+		runID, entity.ReconciliationUnmatched, entity.TransactionStatusSuccess, from, to, runID,
+	)
+	return result.RowsAffected, result.Error
+}
+
+// FindItems returns a page of a run's items, optionally restricted to one result
+func (r *ReconciliationRepository) FindItems(db *gorm.DB, runID string, result string, offset int, limit int) ([]entity.ReconciliationItem, int64, error) {
+	query := db.Model(&entity.ReconciliationItem{}).Where("run_id = ?", runID)
+	if result != "" {
+		query = query.Where("result = ?", result)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []entity.ReconciliationItem
+	err := query.Order("item_id").Offset(offset).Limit(limit).Find(&items).Error
+	return items, total, err
+}
@@ -1,12 +1,40 @@
 package repository
 
 import (
+	"database/sql"
+	"strings"
+	"time"
+
 	"golang-clean-architecture/internal/entity"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	SalesGroupDay      = "day"
+	SalesGroupTerminal = "terminal"
+	SalesGroupStatus   = "status"
 )
 
+// salesGroupColumns maps a report grouping to the expression it selects and groups by
+var salesGroupColumns = map[string]string{
+	SalesGroupDay:      "DATE(created_at) AS date",
+	SalesGroupTerminal: "terminal_id",
+	SalesGroupStatus:   "status",
+}
+
+// SalesSummaryQuery selects the transactions of a merchant created in [From, To), optionally
+// restricted to one status, aggregated by the GroupBy columns in order
+type SalesSummaryQuery struct {
+	MerchantID string
+	From       time.Time
+	To         time.Time
+	Status     string
+	GroupBy    []string
+}
+
 type TransactionRepository struct {
 	Repository[entity.Transaction]
 	Log *logrus.Logger
@@ -18,12 +46,147 @@ func NewTransactionRepository(log *logrus.Logger) *TransactionRepository {
 	}
 }
 
+// CreateAll inserts several transactions with a single statement
+func (r *TransactionRepository) CreateAll(db *gorm.DB, transactions []*entity.Transaction) error {
+	return db.Create(&transactions).Error
+}
+
 func (r *TransactionRepository) FindByTransactionID(db *gorm.DB, transaction *entity.Transaction, transactionID string) error {
 	return db.Where("transaction_id = ?", transactionID).Take(transaction).Error
 }
 
+// LockByTransactionID loads a transaction with a row lock held until the surrounding transaction ends
+func (r *TransactionRepository) LockByTransactionID(db *gorm.DB, transaction *entity.Transaction, transactionID string) error {
+	return db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("transaction_id = ?", transactionID).
+		Take(transaction).Error
+}
+
+// FindPendingBefore returns the oldest PENDING transactions created before the cutoff
+func (r *TransactionRepository) FindPendingBefore(db *gorm.DB, cutoff time.Time, limit int) ([]entity.Transaction, error) {
+	var transactions []entity.Transaction
+	err := db.Where("status = ? AND created_at < ?", entity.TransactionStatusPending, cutoff).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&transactions).Error
+	return transactions, err
+}
+
 func (r *TransactionRepository) UpdateStatus(db *gorm.DB, transactionID string, status string) error {
 	return db.Model(&entity.Transaction{}).
 		Where("transaction_id = ?", transactionID).
 		Update("status", status).Error
 }
+
+// MarkSwitched records the switch's answer to an off-us payment
+func (r *TransactionRepository) MarkSwitched(db *gorm.DB, transactionID string, status string, switchReference string) error {
+	return db.Model(&entity.Transaction{}).
+		Where("transaction_id = ?", transactionID).
+		Updates(map[string]interface{}{
+			"status":           status,
+			"switch_reference": switchReference,
+		}).Error
+}
+
+// MarkDebited records that the account balance was debited for this transaction
+func (r *TransactionRepository) MarkDebited(db *gorm.DB, transactionID string, status string) error {
+	return db.Model(&entity.Transaction{}).
+		Where("transaction_id = ?", transactionID).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"debited_at": gorm.Expr("NOW()"),
+		}).Error
+}
+
+// SumSpending totals the amount and counts the PENDING, SUCCESS and SWITCH_APPROVED transactions of an account
+// created since the given time, the spending limits are checked against
+func (r *TransactionRepository) SumSpending(db *gorm.DB, accountID string, since time.Time) (float64, int64, error) {
+	var result struct {
+		Amount float64
+		Count  int64
+	}
+	err := db.Model(&entity.Transaction{}).
+		Select("COALESCE(SUM(amount), 0) AS amount, COUNT(*) AS count").
+		Where("account_id = ? AND status IN ? AND created_at >= ?", accountID,
+			[]string{entity.TransactionStatusPending, entity.TransactionStatusSuccess, entity.TransactionStatusSwitchApproved}, since).
+		Scan(&result).Error
+	return result.Amount, result.Count, err
+}
+
+// AverageAmount averages and counts the SUCCESS transactions of an account created since the
+// given time
+func (r *TransactionRepository) AverageAmount(db *gorm.DB, accountID string, since time.Time) (float64, int64, error) {
+	var result struct {
+		Amount float64
+		Count  int64
+	}
+	err := db.Model(&entity.Transaction{}).
+		Select("COALESCE(AVG(amount), 0) AS amount, COUNT(*) AS count").
+		Where("account_id = ? AND status = ? AND created_at >= ?", accountID, entity.TransactionStatusSuccess, since).
+		Scan(&result).Error
+	return result.Amount, result.Count, err
+}
+
+// DistinctMerchants lists the merchants of the PENDING, SUCCESS and SWITCH_APPROVED transactions of an account
+// created since the given time
+func (r *TransactionRepository) DistinctMerchants(db *gorm.DB, accountID string, since time.Time) ([]string, error) {
+	var merchants []string
+	err := db.Model(&entity.Transaction{}).
+		Distinct("merchant_id").
+		Where("account_id = ? AND status IN ? AND created_at >= ?", accountID,
+			[]string{entity.TransactionStatusPending, entity.TransactionStatusSuccess, entity.TransactionStatusSwitchApproved}, since).
+		Pluck("merchant_id", &merchants).Error
+	return merchants, err
+}
+
+// HasDevice reports whether an account has a SUCCESS transaction made from the device
+func (r *TransactionRepository) HasDevice(db *gorm.DB, accountID string, deviceID string) (bool, error) {
+	var count int64
+	err := db.Model(&entity.Transaction{}).
+		Where("account_id = ? AND device_id = ? AND status = ?", accountID, deviceID, entity.TransactionStatusSuccess).
+		Limit(1).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SummarizeSales opens a cursor over the aggregated rows so large reports can be streamed;
+// scan each row with ScanSalesSummary and close the rows when done
+func (r *TransactionRepository) SummarizeSales(db *gorm.DB, query SalesSummaryQuery) (*sql.Rows, error) {
+	columns := make([]string, 0, len(query.GroupBy)+3)
+	groups := make([]string, 0, len(query.GroupBy))
+	for _, group := range query.GroupBy {
+		column := salesGroupColumns[group]
+		columns = append(columns, column)
+		name, alias, found := strings.Cut(column, " AS ")
+		if found {
+			name = alias
+		}
+		groups = append(groups, name)
+	}
+	columns = append(columns, "COUNT(*) AS count", "COALESCE(SUM(amount), 0) AS gross", "COALESCE(SUM(fee), 0) AS fees")
+
+	tx := db.Model(&entity.Transaction{}).
+		Select(strings.Join(columns, ", ")).
+		Where("merchant_id = ? AND created_at >= ? AND created_at < ?", query.MerchantID, query.From, query.To)
+
+	if query.Status != "" {
+		tx = tx.Where("status = ?", query.Status)
+	}
+	if len(groups) > 0 {
+		tx = tx.Group(strings.Join(groups, ", ")).Order(strings.Join(groups, ", "))
+	}
+
+	return tx.Rows()
+}
+
+func (r *TransactionRepository) ScanSalesSummary(db *gorm.DB, rows *sql.Rows, summary *entity.SalesSummary) error {
+	return db.ScanRows(rows, summary)
+}
+
+// FindByReferences returns the transactions whose ID or trace ID is one of the references
+func (r *TransactionRepository) FindByReferences(db *gorm.DB, references []string) ([]entity.Transaction, error) {
+	var transactions []entity.Transaction
+	err := db.Where("transaction_id::text IN ? OR trace_id IN ?", references, references).
+		Find(&transactions).Error
+	return transactions, err
+}
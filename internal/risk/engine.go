@@ -0,0 +1,55 @@
+package risk
+
+import (
+	"context"
+	"time"
+)
+
+// Action is what screening decides for a payment
+type Action string
+
+const (
+	ActionApprove Action = "approve"
+	ActionStepUp  Action = "step_up"
+	ActionDecline Action = "decline"
+)
+
+// severity orders actions so the strictest one wins
+var severity = map[Action]int{
+	ActionApprove: 0,
+	ActionStepUp:  1,
+	ActionDecline: 2,
+}
+
+// Payment is what a payment is screened on. Amount is in the account currency.
+type Payment struct {
+	AccountID  string
+	MerchantID string
+	Amount     float64
+	DeviceID   string
+	// At is in database wall clock time, the history windows are compared with created_at
+	At time.Time
+}
+
+// Decision is the outcome of screening a payment with the total score and the names of the
+// rules that matched
+type Decision struct {
+	Action Action
+	Score  int
+	Rules  []string
+}
+
+// Engine screens payments before the debit
+type Engine interface {
+	Evaluate(ctx context.Context, payment Payment) (*Decision, error)
+}
+
+// History is the past activity of an account the rules compare a payment with
+type History interface {
+	// AmountStats averages and counts the successful payments of an account since the given time
+	AmountStats(ctx context.Context, accountID string, since time.Time) (float64, int64, error)
+	// Merchants lists the distinct merchants an account paid, or is paying, since the given time
+	Merchants(ctx context.Context, accountID string, since time.Time) ([]string, error)
+	// KnownDevice reports whether the account has paid from the device before
+	KnownDevice(ctx context.Context, accountID string, deviceID string) (bool, error)
+}
@@ -0,0 +1,125 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	// RuleNewDevice matches payments from a device the account never paid from
+	RuleNewDevice = "new_device"
+	// RuleUnusualAmount matches payments of more than Multiplier times the account's average
+	RuleUnusualAmount = "unusual_amount"
+	// RuleMerchantVelocity matches payments to more than MaxMerchants distinct merchants within Window
+	RuleMerchantVelocity = "merchant_velocity"
+	// RuleBlacklist matches payments from Accounts or to Merchants
+	RuleBlacklist = "blacklist"
+)
+
+// Rule is one screening rule. A matching rule adds Score to the decision and, when Action is
+// set, forces at least that action regardless of the total score.
+type Rule struct {
+	Name   string
+	Type   string
+	Score  int
+	Action Action
+
+	// unusual_amount: compared with the average over Lookback once MinHistory payments exist
+	Multiplier float64
+	Lookback   time.Duration
+	MinHistory int64
+
+	// merchant_velocity
+	Window       time.Duration
+	MaxMerchants int
+
+	// blacklist
+	Accounts  []string
+	Merchants []string
+}
+
+// Config is the rule set and the total scores at which payments need a step-up or are declined;
+// zero leaves a threshold off
+type Config struct {
+	Rules        []Rule
+	StepUpScore  int
+	DeclineScore int
+}
+
+// RuleEngine scores payments with the rules loaded from configuration
+type RuleEngine struct {
+	Config  Config
+	History History
+}
+
+func NewRuleEngine(config Config, history History) *RuleEngine {
+	return &RuleEngine{
+		Config:  config,
+		History: history,
+	}
+}
+
+func (e *RuleEngine) Evaluate(ctx context.Context, payment Payment) (*Decision, error) {
+	decision := &Decision{Action: ActionApprove}
+	for _, rule := range e.Config.Rules {
+		matched, err := e.match(ctx, rule, payment)
+		if err != nil {
+			return nil, fmt.Errorf("risk: rule %s: %w", rule.Name, err)
+		}
+		if !matched {
+			continue
+		}
+
+		decision.Score += rule.Score
+		decision.Rules = append(decision.Rules, rule.Name)
+		decision.escalate(rule.Action)
+	}
+
+	if e.Config.StepUpScore > 0 && decision.Score >= e.Config.StepUpScore {
+		decision.escalate(ActionStepUp)
+	}
+	if e.Config.DeclineScore > 0 && decision.Score >= e.Config.DeclineScore {
+		decision.escalate(ActionDecline)
+	}
+	return decision, nil
+}
+
+func (e *RuleEngine) match(ctx context.Context, rule Rule, payment Payment) (bool, error) {
+	switch rule.Type {
+	case RuleNewDevice:
+		// Payments without a device cannot be told apart and are left to the other rules
+		if payment.DeviceID == "" {
+			return false, nil
+		}
+		known, err := e.History.KnownDevice(ctx, payment.AccountID, payment.DeviceID)
+		return !known, err
+	case RuleUnusualAmount:
+		average, count, err := e.History.AmountStats(ctx, payment.AccountID, payment.At.Add(-rule.Lookback))
+		if err != nil || count < rule.MinHistory {
+			return false, err
+		}
+		return payment.Amount > average*rule.Multiplier, nil
+	case RuleMerchantVelocity:
+		merchants, err := e.History.Merchants(ctx, payment.AccountID, payment.At.Add(-rule.Window))
+		if err != nil {
+			return false, err
+		}
+		if !slices.Contains(merchants, payment.MerchantID) {
+			merchants = append(merchants, payment.MerchantID)
+		}
+		return len(merchants) > rule.MaxMerchants, nil
+	case RuleBlacklist:
+		return slices.Contains(rule.Accounts, payment.AccountID) || slices.Contains(rule.Merchants, payment.MerchantID), nil
+	default:
+		return false, fmt.Errorf("unknown rule type %q", rule.Type)
+	}
+}
+
+// escalate raises the decision to action if it is stricter
+func (d *Decision) escalate(action Action) {
+	if severity[action] > severity[d.Action] {
+		d.Action = action
+	}
+}
@@ -0,0 +1,168 @@
+package settlement
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatCSV        = "csv"
+	FormatFixedWidth = "fixed_width"
+)
+
+// Field locates a value in a settlement row: Column is the zero-based CSV column, Start and
+// Length the zero-based byte offset and width in a fixed-width line
+type Field struct {
+	Column int
+	Start  int
+	Length int
+}
+
+// Config describes the layout of the settlement files sent by the switch
+type Config struct {
+	Format     string
+	Delimiter  rune
+	SkipHeader bool
+	// AmountDivisor scales amounts sent in minor units, e.g. 100 for "000000001500000" = 15000.00
+	AmountDivisor float64
+	Reference     Field
+	Amount        Field
+}
+
+// Record is one settled payment as reported by the switch
+type Record struct {
+	Line      int
+	Reference string
+	Amount    float64
+}
+
+// Parser reads settlement files row by row so files of any size can be reconciled
+type Parser struct {
+	Config Config
+}
+
+func NewParser(config Config) *Parser {
+	if config.AmountDivisor == 0 {
+		config.AmountDivisor = 1
+	}
+	return &Parser{Config: config}
+}
+
+// Records yields every row of the file; the sequence stops at the first malformed row
+func (p *Parser) Records(reader io.Reader) iter.Seq2[*Record, error] {
+	if p.Config.Format == FormatFixedWidth {
+		return p.fixedWidthRecords(reader)
+	}
+	return p.csvRecords(reader)
+}
+
+func (p *Parser) csvRecords(reader io.Reader) iter.Seq2[*Record, error] {
+	return func(yield func(*Record, error) bool) {
+		csvReader := csv.NewReader(reader)
+		csvReader.Comma = p.Config.Delimiter
+		csvReader.FieldsPerRecord = -1
+		csvReader.TrimLeadingSpace = true
+		csvReader.ReuseRecord = true
+
+		for header := p.Config.SkipHeader; ; header = false {
+			columns, err := csvReader.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if header {
+				continue
+			}
+
+			line, _ := csvReader.FieldPos(0)
+
+			record, err := p.csvRecord(line, columns)
+			if err != nil {
+				yield(nil, fmt.Errorf("line %d: %w", line, err))
+				return
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (p *Parser) fixedWidthRecords(reader io.Reader) iter.Seq2[*Record, error] {
+	return func(yield func(*Record, error) bool) {
+		scanner := bufio.NewScanner(reader)
+
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimRight(scanner.Text(), "\r")
+			if (line == 1 && p.Config.SkipHeader) || strings.TrimSpace(text) == "" {
+				continue
+			}
+
+			record, err := p.fixedWidthRecord(line, text)
+			if err != nil {
+				yield(nil, fmt.Errorf("line %d: %w", line, err))
+				return
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+func (p *Parser) csvRecord(line int, columns []string) (*Record, error) {
+	if index := max(p.Config.Reference.Column, p.Config.Amount.Column); index >= len(columns) {
+		return nil, fmt.Errorf("missing column %d", index)
+	}
+	return p.record(line, columns[p.Config.Reference.Column], columns[p.Config.Amount.Column])
+}
+
+func (p *Parser) fixedWidthRecord(line int, text string) (*Record, error) {
+	reference, err := slice(text, p.Config.Reference)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := slice(text, p.Config.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return p.record(line, reference, amount)
+}
+
+func (p *Parser) record(line int, reference string, amount string) (*Record, error) {
+	reference = strings.TrimSpace(reference)
+	if reference == "" {
+		return nil, errors.New("empty reference")
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(amount), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+
+	return &Record{
+		Line:      line,
+		Reference: reference,
+		Amount:    value / p.Config.AmountDivisor,
+	}, nil
+}
+
+func slice(text string, field Field) (string, error) {
+	if field.Start+field.Length > len(text) {
+		return "", fmt.Errorf("line shorter than field at %d+%d", field.Start, field.Length)
+	}
+	return text[field.Start : field.Start+field.Length], nil
+}
@@ -0,0 +1,323 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/otp"
+	"golang-clean-architecture/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	ApiClientStatusActive   = "ACTIVE"
+	ApiClientStatusDisabled = "DISABLED"
+)
+
+// apiClientSecretBytes is the entropy of a generated HMAC secret (hex encoded to 64 characters)
+const apiClientSecretBytes = 32
+
+// AdminUseCase backs the operator tooling: provisioning API clients, accounts and merchants
+type AdminUseCase struct {
+	DB                  *gorm.DB
+	Log                 *logrus.Logger
+	Validate            *validator.Validate
+	ApiClientRepository *repository.ApiClientRepository
+	AccountRepository   *repository.AccountRepository
+	MerchantRepository  *repository.MerchantRepository
+	DeviceRepository    *repository.DeviceRepository
+	Audit               *AuditUseCase
+}
+
+func NewAdminUseCase(
+	db *gorm.DB,
+	log *logrus.Logger,
+	validate *validator.Validate,
+	apiClientRepo *repository.ApiClientRepository,
+	accountRepo *repository.AccountRepository,
+	merchantRepo *repository.MerchantRepository,
+	deviceRepo *repository.DeviceRepository,
+	audit *AuditUseCase,
+) *AdminUseCase {
+	return &AdminUseCase{
+		DB:                  db,
+		Log:                 log,
+		Validate:            validate,
+		ApiClientRepository: apiClientRepo,
+		AccountRepository:   accountRepo,
+		MerchantRepository:  merchantRepo,
+		DeviceRepository:    deviceRepo,
+		Audit:               audit,
+	}
+}
+
+// CreateApiClient registers an API client with a freshly generated secret. The secret is only
+// ever returned here, callers must hand it over to the client immediately.
+func (u *AdminUseCase) CreateApiClient(ctx context.Context, request *model.CreateApiClientRequest) (*model.ApiClientResponse, error) {
+	if err := u.Validate.Struct(request); err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid api client request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	secret := make([]byte, apiClientSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to generate client secret: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	client := &entity.ApiClient{
+		ClientID:     request.ClientID,
+		ClientSecret: hex.EncodeToString(secret),
+		Status:       ApiClientStatusActive,
+	}
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := u.ApiClientRepository.Create(tx, client); err != nil {
+		return nil, u.createError(ctx, "api client", err)
+	}
+
+	response := &model.ApiClientResponse{
+		ClientID:  client.ClientID,
+		Status:    client.Status,
+		CreatedAt: client.CreatedAt.Format(time.RFC3339),
+	}
+	event := u.Audit.Event(ctx, entity.AuditApiClientCreated, entity.AuditTargetApiClient, client.ClientID, nil, response)
+	if err := u.commit(ctx, tx, event); err != nil {
+		return nil, err
+	}
+	u.Log.WithContext(ctx).Infof("Created api client: %s", client.ClientID)
+
+	// The secret is handed out here and never audited
+	response.ClientSecret = client.ClientSecret
+	return response, nil
+}
+
+// DisableApiClient stops a client from authenticating; its secret is kept for auditing
+func (u *AdminUseCase) DisableApiClient(ctx context.Context, clientID string) (*model.ApiClientResponse, error) {
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	err := u.ApiClientRepository.UpdateStatus(tx, clientID, ApiClientStatusDisabled)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.NewError(model.ErrCodeApiClientNotFound)
+	}
+	if err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to disable api client: %s, error: %+v", clientID, err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	response := &model.ApiClientResponse{
+		ClientID: clientID,
+		Status:   ApiClientStatusDisabled,
+	}
+	event := u.Audit.Event(ctx, entity.AuditApiClientDisabled, entity.AuditTargetApiClient, clientID, nil, response)
+	if err := u.commit(ctx, tx, event); err != nil {
+		return nil, err
+	}
+	u.Log.WithContext(ctx).Infof("Disabled api client: %s", clientID)
+	return response, nil
+}
+
+// CreateAccount opens an account with an opening balance; the PIN is stored as a bcrypt hash
+// and the TOTP secret, if requested, is returned once to be enrolled in an authenticator app
+func (u *AdminUseCase) CreateAccount(ctx context.Context, request *model.CreateAccountRequest) (*model.AccountResponse, error) {
+	if err := u.Validate.Struct(request); err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid account request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	pinHash, err := bcrypt.GenerateFromPassword([]byte(request.Pin), bcrypt.DefaultCost)
+	if err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to hash pin: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	var totpSecret string
+	if request.Totp {
+		totpSecret, err = otp.GenerateSecret()
+		if err != nil {
+			u.Log.WithContext(ctx).Errorf("Failed to generate totp secret: %+v", err)
+			return nil, model.NewError(model.ErrCodeInternal)
+		}
+	}
+
+	account := &entity.Account{
+		AccountID:  request.AccountID,
+		Balance:    request.Balance,
+		Currency:   request.Currency,
+		PinHash:    string(pinHash),
+		LimitTier:  request.LimitTier,
+		TotpSecret: totpSecret,
+	}
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := u.AccountRepository.Create(tx, account); err != nil {
+		return nil, u.createError(ctx, "account", err)
+	}
+
+	response := &model.AccountResponse{
+		AccountID: account.AccountID,
+		Balance:   account.Balance,
+		Currency:  account.Currency,
+		LimitTier: account.LimitTier,
+	}
+	event := u.Audit.Event(ctx, entity.AuditAccountCreated, entity.AuditTargetAccount, account.AccountID, nil, response)
+	if err := u.commit(ctx, tx, event); err != nil {
+		return nil, err
+	}
+	u.Log.WithContext(ctx).Infof("Created account: %s", account.AccountID)
+
+	// The TOTP secret is handed out here and never audited
+	response.TotpSecret = account.TotpSecret
+	return response, nil
+}
+
+// RegisterDevice binds a customer device and its Ed25519 public key to an account. The PIN is
+// no proof of ownership here, every API client handling payments sees it, so devices are only
+// bound by operators once the customer is verified out of band.
+func (u *AdminUseCase) RegisterDevice(ctx context.Context, request *model.RegisterDeviceRequest) (*model.DeviceResponse, error) {
+	if err := u.Validate.Struct(request); err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid device request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(request.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		u.Log.WithContext(ctx).Warnf("Invalid device public key for account: %s", request.AccountID)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	account := new(entity.Account)
+	if err := u.AccountRepository.FindByAccountID(tx, account, request.AccountID); err != nil {
+		u.Log.WithContext(ctx).Warnf("Account not found: %s, error: %+v", request.AccountID, err)
+		return nil, model.NewError(model.ErrCodeAccountNotFound)
+	}
+
+	device := &entity.Device{
+		DeviceID:  request.DeviceID,
+		AccountID: account.AccountID,
+		PublicKey: request.PublicKey,
+		Status:    entity.DeviceStatusActive,
+	}
+	if err := u.DeviceRepository.Create(tx, device); err != nil {
+		return nil, u.createError(ctx, "device", err)
+	}
+
+	response := &model.DeviceResponse{
+		AccountID: device.AccountID,
+		DeviceID:  device.DeviceID,
+		Status:    device.Status,
+		CreatedAt: device.CreatedAt.Format(time.RFC3339),
+	}
+	event := u.Audit.Event(ctx, entity.AuditDeviceRegistered, entity.AuditTargetDevice, device.AccountID+"/"+device.DeviceID, nil, response)
+	if err := u.commit(ctx, tx, event); err != nil {
+		return nil, err
+	}
+	u.Log.WithContext(ctx).Infof("Registered device %s for account: %s", device.DeviceID, device.AccountID)
+	return response, nil
+}
+
+// RevokeDevice unbinds a customer device, e.g. a lost phone; payments it signs are refused
+func (u *AdminUseCase) RevokeDevice(ctx context.Context, request *model.RevokeDeviceRequest) (*model.DeviceResponse, error) {
+	if err := u.Validate.Struct(request); err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid device request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	err := u.DeviceRepository.Revoke(tx, request.AccountID, request.DeviceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.NewError(model.ErrCodeDeviceNotFound)
+	}
+	if err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to revoke device: %s, error: %+v", request.DeviceID, err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	response := &model.DeviceResponse{
+		AccountID: request.AccountID,
+		DeviceID:  request.DeviceID,
+		Status:    entity.DeviceStatusRevoked,
+	}
+	event := u.Audit.Event(ctx, entity.AuditDeviceRevoked, entity.AuditTargetDevice, request.AccountID+"/"+request.DeviceID, nil, response)
+	if err := u.commit(ctx, tx, event); err != nil {
+		return nil, err
+	}
+	u.Log.WithContext(ctx).Infof("Revoked device %s of account: %s", request.DeviceID, request.AccountID)
+	return response, nil
+}
+
+// CreateMerchant registers an active merchant
+func (u *AdminUseCase) CreateMerchant(ctx context.Context, request *model.CreateMerchantRequest) (*model.MerchantResponse, error) {
+	if err := u.Validate.Struct(request); err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid merchant request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	merchant := &entity.Merchant{
+		MerchantID:   request.MerchantID,
+		MerchantName: request.MerchantName,
+		MCC:          request.MCC,
+		City:         request.City,
+		IsActive:     true,
+	}
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	if err := u.MerchantRepository.Create(tx, merchant); err != nil {
+		return nil, u.createError(ctx, "merchant", err)
+	}
+
+	response := &model.MerchantResponse{
+		MerchantID:   merchant.MerchantID,
+		MerchantName: merchant.MerchantName,
+		MCC:          merchant.MCC,
+		City:         merchant.City,
+		IsActive:     merchant.IsActive,
+	}
+	event := u.Audit.Event(ctx, entity.AuditMerchantCreated, entity.AuditTargetMerchant, merchant.MerchantID, nil, response)
+	if err := u.commit(ctx, tx, event); err != nil {
+		return nil, err
+	}
+	u.Log.WithContext(ctx).Infof("Created merchant: %s", merchant.MerchantID)
+	return response, nil
+}
+
+// commit appends the audit event of a change to tx and commits both
+func (u *AdminUseCase) commit(ctx context.Context, tx *gorm.DB, event *entity.AuditEvent) error {
+	if err := u.Audit.Append(ctx, tx, event); err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to audit %s of %s: %+v", event.Action, event.TargetID, err)
+		return model.NewError(model.ErrCodeInternal)
+	}
+	if err := tx.Commit().Error; err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to commit %s of %s: %+v", event.Action, event.TargetID, err)
+		return model.NewError(model.ErrCodeInternal)
+	}
+	return nil
+}
+
+func (u *AdminUseCase) createError(ctx context.Context, kind string, err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return model.NewError(model.ErrCodeAlreadyExists)
+	}
+	u.Log.WithContext(ctx).Errorf("Failed to create %s: %+v", kind, err)
+	return model.NewError(model.ErrCodeInternal)
+}
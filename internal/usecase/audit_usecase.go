@@ -0,0 +1,300 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// auditVerifyBatchSize is how many events Verify loads at a time
+const auditVerifyBatchSize = 1000
+
+// auditGenesisHash is the previous hash of the first event
+var auditGenesisHash = strings.Repeat("0", 64)
+
+// AuditUseCase appends state-changing operations to the hash-chained audit trail and checks
+// that the chains are intact. Events are spread over Chains chains by target, so transactions
+// recording different targets rarely wait on the same chain.
+type AuditUseCase struct {
+	DB                   *gorm.DB
+	Log                  *logrus.Logger
+	Validate             *validator.Validate
+	AuditEventRepository *repository.AuditEventRepository
+	Chains               int
+}
+
+func NewAuditUseCase(
+	db *gorm.DB,
+	log *logrus.Logger,
+	validate *validator.Validate,
+	auditEventRepository *repository.AuditEventRepository,
+	chains int,
+) *AuditUseCase {
+	return &AuditUseCase{
+		DB:                   db,
+		Log:                  log,
+		Validate:             validate,
+		AuditEventRepository: auditEventRepository,
+		Chains:               max(chains, 1),
+	}
+}
+
+// Event describes an operation of the caller of ctx. Before and after are snapshots of the
+// target, nil when there is none, and must not hold secrets.
+func (u *AuditUseCase) Event(ctx context.Context, action string, targetType string, targetID string, before any, after any) *entity.AuditEvent {
+	event := &entity.AuditEvent{
+		Actor:      entity.AuditActorSystem,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     auditSnapshot(before),
+		After:      auditSnapshot(after),
+	}
+	if requestContext, ok := model.RequestContextFrom(ctx); ok {
+		event.RequestID = requestContext.RequestID
+		if requestContext.Actor != "" {
+			event.Actor = requestContext.Actor
+		}
+	}
+	return event
+}
+
+// Append adds the events to tx, so they are committed with the change they record or not at all.
+// The chains of the events stay locked until tx ends: callers append right before committing.
+func (u *AuditUseCase) Append(ctx context.Context, tx *gorm.DB, events ...*entity.AuditEvent) error {
+	ctx, span := tracer.Start(ctx, "AuditUseCase.Append")
+	defer span.End()
+
+	for _, event := range events {
+		event.Chain = auditChain(event, u.Chains)
+	}
+	// Chains are locked in ascending order, so transactions appending to several cannot deadlock
+	slices.SortStableFunc(events, func(a, b *entity.AuditEvent) int {
+		return cmp.Compare(a.Chain, b.Chain)
+	})
+
+	heads := make(map[int16]string)
+	for _, event := range events {
+		prevHash, locked := heads[event.Chain]
+		if !locked {
+			if err := u.AuditEventRepository.LockChain(tx, event.Chain); err != nil {
+				return err
+			}
+
+			head := new(entity.AuditEvent)
+			prevHash = auditGenesisHash
+			if err := u.AuditEventRepository.FindLast(tx, head, event.Chain); err == nil {
+				prevHash = head.Hash
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+
+		// Postgres keeps microseconds; the hash must cover the instant that is read back. Unlike
+		// the other tables created_at is a TIMESTAMPTZ, and audit times are reported in UTC.
+		event.PrevHash = prevHash
+		event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		event.Hash = auditHash(event)
+		if err := u.AuditEventRepository.Create(tx, event); err != nil {
+			return err
+		}
+		heads[event.Chain] = event.Hash
+	}
+
+	return nil
+}
+
+// Record appends an event in a transaction of its own, for operations that change nothing else,
+// such as a wrong PIN. A failure to record is logged.
+func (u *AuditUseCase) Record(ctx context.Context, action string, targetType string, targetID string, before any, after any) {
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	err := u.Append(ctx, tx, u.Event(ctx, action, targetType, targetID, before, after))
+	if err == nil {
+		err = tx.Commit().Error
+	}
+	if err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to record audit event %s of %s %s: %+v", action, targetType, targetID, err)
+	}
+}
+
+// Verify recomputes every chain from its first event and reports the first event that was
+// modified, or whose predecessor in its chain was removed or inserted
+func (u *AuditUseCase) Verify(ctx context.Context) (*model.VerifyAuditResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuditUseCase.Verify")
+	defer span.End()
+
+	db := u.DB.WithContext(ctx)
+	response := &model.VerifyAuditResponse{Valid: true}
+	heads := make(map[int16]*model.AuditChainHead)
+	lastID := int64(0)
+	for {
+		events, err := u.AuditEventRepository.FindAfter(db, lastID, auditVerifyBatchSize)
+		if err != nil {
+			u.Log.WithContext(ctx).Errorf("Failed to load audit events: %+v", err)
+			return nil, model.NewError(model.ErrCodeInternal)
+		}
+
+		for i := range events {
+			event := &events[i]
+			head, ok := heads[event.Chain]
+			if !ok {
+				head = &model.AuditChainHead{Chain: event.Chain, HeadHash: auditGenesisHash}
+				heads[event.Chain] = head
+			}
+
+			switch {
+			case event.PrevHash != head.HeadHash:
+				response.Problem = "previous hash does not match: an event before it was removed or inserted"
+			case auditHash(event) != event.Hash:
+				response.Problem = "hash does not match the event: it was modified"
+			}
+			if response.Problem != "" {
+				response.Valid = false
+				response.BrokenEventID = event.EventID
+				u.Log.WithContext(ctx).Errorf("Audit chain %d broken at event %d: %s", event.Chain, event.EventID, response.Problem)
+				return response, nil
+			}
+
+			response.Events++
+			head.Events++
+			head.HeadHash = event.Hash
+			lastID = event.EventID
+		}
+
+		if len(events) < auditVerifyBatchSize {
+			break
+		}
+	}
+
+	for _, head := range heads {
+		response.Heads = append(response.Heads, head)
+	}
+	slices.SortFunc(response.Heads, func(a, b *model.AuditChainHead) int {
+		return cmp.Compare(a.Chain, b.Chain)
+	})
+	return response, nil
+}
+
+// Search pages through the events, newest first
+func (u *AuditUseCase) Search(ctx context.Context, request *model.SearchAuditEventsRequest) (*model.AuditEventsResponse, error) {
+	ctx, span := tracer.Start(ctx, "AuditUseCase.Search")
+	defer span.End()
+
+	if err := u.Validate.Struct(request); err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid audit search request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	query := repository.AuditEventQuery{
+		Actor:      request.Actor,
+		Action:     request.Action,
+		TargetType: request.TargetType,
+		TargetID:   request.TargetID,
+	}
+	// Both bounds were validated as RFC 3339 above; any offset is accepted and compared in UTC
+	if request.From != "" {
+		from, _ := time.Parse(time.RFC3339, request.From)
+		query.From = from.UTC()
+	}
+	if request.To != "" {
+		to, _ := time.Parse(time.RFC3339, request.To)
+		query.To = to.UTC()
+	}
+
+	events, total, err := u.AuditEventRepository.Search(u.DB.WithContext(ctx), query, (request.Page-1)*request.Size, request.Size)
+	if err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to search audit events: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	response := &model.AuditEventsResponse{
+		Events: make([]*model.AuditEventResponse, len(events)),
+		Paging: &model.PageMetadata{
+			Page:      request.Page,
+			Size:      request.Size,
+			TotalItem: total,
+			TotalPage: (total + int64(request.Size) - 1) / int64(request.Size),
+		},
+	}
+	for i, event := range events {
+		response.Events[i] = toAuditEventResponse(&event)
+	}
+
+	return response, nil
+}
+
+// auditSnapshot serializes a snapshot for the event; its JSON is hashed as stored
+func auditSnapshot(snapshot any) string {
+	if snapshot == nil {
+		return ""
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// auditChain picks the chain of an event by its target, so the history of a target is one chain
+func auditChain(event *entity.AuditEvent, chains int) int16 {
+	hash := fnv.New32a()
+	hash.Write([]byte(event.TargetType + "/" + event.TargetID))
+	return int16(hash.Sum32() % uint32(chains))
+}
+
+// auditHash covers every field of the event but its ID, which the database assigns, and the
+// hash of the event before it
+func auditHash(event *entity.AuditEvent) string {
+	content, _ := json.Marshal([]string{
+		event.PrevHash,
+		event.Actor,
+		event.Action,
+		event.TargetType,
+		event.TargetID,
+		event.Before,
+		event.After,
+		event.RequestID,
+		event.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func toAuditEventResponse(event *entity.AuditEvent) *model.AuditEventResponse {
+	response := &model.AuditEventResponse{
+		EventID:    event.EventID,
+		Actor:      event.Actor,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		RequestID:  event.RequestID,
+		Chain:      event.Chain,
+		PrevHash:   event.PrevHash,
+		Hash:       event.Hash,
+		CreatedAt:  event.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if event.Before != "" {
+		response.Before = json.RawMessage(event.Before)
+	}
+	if event.After != "" {
+		response.After = json.RawMessage(event.After)
+	}
+	return response
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/qris"
+	"golang-clean-architecture/internal/repository"
+)
+
+// checkBlacklist refuses inquiries and payments involving a blacklisted value
+func (u *QrisUseCase) checkBlacklist(ctx context.Context, keys ...repository.ListKey) error {
+	if u.Lists == nil {
+		return nil
+	}
+
+	entryID, err := u.Lists.Match(ctx, entity.ListBlacklist, keys...)
+	if err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to check blacklist: %+v", err)
+		return model.NewError(model.ErrCodeInternal)
+	}
+	if entryID != 0 {
+		u.Log.WithContext(ctx).Warnf("Blocked by blacklist entry: %d", entryID)
+		return model.NewError(model.ErrCodeBlacklisted)
+	}
+	return nil
+}
+
+// whitelisted reports whether the paying account or the merchant is trusted; lookup errors
+// leave the payment to risk screening
+func (u *QrisUseCase) whitelisted(ctx context.Context, transaction *entity.Transaction) bool {
+	if u.Lists == nil {
+		return false
+	}
+
+	entryID, err := u.Lists.Match(ctx, entity.ListWhitelist,
+		repository.ListKey{EntryType: entity.ListEntryAccountID, Value: transaction.AccountID},
+		repository.ListKey{EntryType: entity.ListEntryMerchantID, Value: transaction.MerchantID},
+	)
+	if err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to check whitelist: %+v", err)
+		return false
+	}
+	return entryID != 0
+}
+
+// paymentKeys are the list keys of a payment: the paying account, the merchant and its QR
+func paymentKeys(request *model.PaymentRequest, inquiry map[string]interface{}) []repository.ListKey {
+	merchantID, _ := inquiry["merchant_id"].(string)
+	keys := []repository.ListKey{
+		{EntryType: entity.ListEntryAccountID, Value: request.UserID},
+		{EntryType: entity.ListEntryMerchantID, Value: merchantID},
+	}
+
+	qrisPayload, _ := inquiry["qris_payload"].(string)
+	if payload, err := qris.Parse(qrisPayload); err == nil {
+		keys = append(keys, qrisKeys(qrisPayload, payload)...)
+	}
+	return keys
+}
+
+// qrisKeys are the list keys identifying a QR: the hash of its payload and its national merchant ID
+func qrisKeys(qrisPayload string, payload *qris.Payload) []repository.ListKey {
+	sum := sha256.Sum256([]byte(qrisPayload))
+	keys := []repository.ListKey{{EntryType: entity.ListEntryQRHash, Value: hex.EncodeToString(sum[:])}}
+	if nmid := payload.NMID(); nmid != "" {
+		keys = append(keys, repository.ListKey{EntryType: entity.ListEntryNMID, Value: nmid})
+	}
+	return keys
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/gateway/fx"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/qris"
+)
+
+// FxConfig converts cross-border QRs, priced in the merchant's currency (tag 53), into the
+// currency of the paying account
+type FxConfig struct {
+	Provider fx.Provider
+	// HomeCurrency is assumed for QRs without tag 53 and quoted when the inquiry names no currency
+	HomeCurrency string
+	// LockTTL is how long a quoted rate holds for its inquiry_id
+	LockTTL time.Duration
+}
+
+// quote locks the rate from the QR currency into quoteCurrency. Domestic QRs paid in the same
+// currency need no quote and return nil.
+func (u *QrisUseCase) quote(ctx context.Context, payload *qris.Payload, quoteCurrency string) (*model.FxQuote, error) {
+	currency := u.Fx.HomeCurrency
+	if payload.Currency != "" {
+		code, ok := fx.CurrencyCode(payload.Currency)
+		if !ok {
+			u.Log.WithContext(ctx).Warnf("Unsupported QRIS currency: %s", payload.Currency)
+			return nil, model.NewError(model.ErrCodeCurrencyNotSupported)
+		}
+		currency = code
+	}
+
+	quoteCurrency = strings.ToUpper(quoteCurrency)
+	if quoteCurrency == "" {
+		quoteCurrency = u.Fx.HomeCurrency
+	}
+	if currency == quoteCurrency {
+		return nil, nil
+	}
+
+	rate, err := u.Fx.Provider.Rate(ctx, currency, quoteCurrency)
+	if errors.Is(err, fx.ErrRateNotFound) {
+		u.Log.WithContext(ctx).Warnf("No exchange rate for %s/%s", currency, quoteCurrency)
+		return nil, model.NewError(model.ErrCodeCurrencyNotSupported)
+	}
+	if err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to fetch exchange rate for %s/%s: %+v", currency, quoteCurrency, err)
+		return nil, model.NewError(model.ErrCodeFxRateUnavailable)
+	}
+
+	return &model.FxQuote{
+		Currency:      currency,
+		QuoteCurrency: quoteCurrency,
+		Rate:          rate,
+		QuotedAmount:  convertAmount(payload.Amount, rate),
+		LockedUntil:   time.Now().Add(u.Fx.LockTTL),
+	}, nil
+}
+
+// convert prices the transaction in the account's currency. The transaction amount becomes
+// the debit and, for cross-border payments, the merchant amount is kept with the locked rate.
+func (u *QrisUseCase) convert(ctx context.Context, account *entity.Account, transaction *entity.Transaction, quote *model.FxQuote) error {
+	currency := u.Fx.HomeCurrency
+	if quote != nil {
+		currency = quote.Currency
+	}
+
+	transaction.Currency = account.Currency
+	if account.Currency == currency {
+		return nil
+	}
+
+	if quote == nil || quote.QuoteCurrency != account.Currency {
+		u.Log.WithContext(ctx).Warnf("Account currency %s does not match the quote for user: %s", account.Currency, account.AccountID)
+		return model.NewError(model.ErrCodeCurrencyMismatch)
+	}
+	if time.Now().After(quote.LockedUntil) {
+		u.Log.WithContext(ctx).Warnf("Exchange rate lock expired for user: %s", account.AccountID)
+		return model.NewError(model.ErrCodeFxQuoteExpired)
+	}
+
+	amount := transaction.Amount
+	transaction.OriginalAmount = &amount
+	transaction.OriginalCurrency = &quote.Currency
+	transaction.FxRate = &quote.Rate
+	transaction.Amount = convertAmount(amount, quote.Rate)
+	return nil
+}
+
+// merchantAmount is the amount in the merchant's currency, what the acquirer is paid
+func merchantAmount(transaction *entity.Transaction) float64 {
+	if transaction.OriginalAmount != nil {
+		return *transaction.OriginalAmount
+	}
+	return transaction.Amount
+}
+
+// convertAmount applies a rate, rounded to the cent
+func convertAmount(amount float64, rate float64) float64 {
+	return math.Round(amount*rate*100) / 100
+}
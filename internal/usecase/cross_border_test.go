@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/gateway/fx"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/qris"
+
+	"github.com/sirupsen/logrus"
+)
+
+// unavailableProvider fails like a rate service that is down
+type unavailableProvider struct{}
+
+func (unavailableProvider) Rate(ctx context.Context, base string, quote string) (float64, error) {
+	return 0, errors.New("rate service unavailable")
+}
+
+func newCrossBorderUseCase(t *testing.T, provider fx.Provider) *QrisUseCase {
+	t.Helper()
+	log := logrus.New()
+	log.SetOutput(testWriter{t})
+
+	return &QrisUseCase{
+		Log: log,
+		Fx:  FxConfig{Provider: provider, HomeCurrency: "IDR", LockTTL: 5 * time.Minute},
+	}
+}
+
+func TestQuote(t *testing.T) {
+	rates := &fx.StaticProvider{Rates: map[string]float64{"MYR/IDR": 3550.25, "SGD/IDR": 12150.5}}
+
+	tests := []struct {
+		name          string
+		provider      fx.Provider
+		currency      string
+		amount        float64
+		quoteCurrency string
+		want          *model.FxQuote
+		err           model.ErrorCode
+	}{
+		{name: "domestic", provider: rates, amount: 25000},
+		{name: "domestic with tag 53", provider: rates, currency: "360", amount: 25000, quoteCurrency: "idr"},
+		{
+			name:     "cross-border",
+			provider: rates,
+			currency: "458",
+			amount:   12.34,
+			want:     &model.FxQuote{Currency: "MYR", QuoteCurrency: "IDR", Rate: 3550.25, QuotedAmount: 43810.09},
+		},
+		{
+			name:          "inverse pair",
+			provider:      rates,
+			currency:      "360",
+			amount:        100000,
+			quoteCurrency: "SGD",
+			want:          &model.FxQuote{Currency: "IDR", QuoteCurrency: "SGD", Rate: 1 / 12150.5, QuotedAmount: 8.23},
+		},
+		{name: "unknown currency code", provider: rates, currency: "999", amount: 10, err: model.ErrCodeCurrencyNotSupported},
+		{name: "no rate", provider: rates, currency: "840", amount: 10, err: model.ErrCodeCurrencyNotSupported},
+		{name: "provider down", provider: unavailableProvider{}, currency: "458", amount: 10, err: model.ErrCodeFxRateUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newCrossBorderUseCase(t, tt.provider)
+
+			before := time.Now()
+			quote, err := u.quote(context.Background(), &qris.Payload{Currency: tt.currency, Amount: tt.amount}, tt.quoteCurrency)
+			if code := errorCode(err); code != tt.err {
+				t.Fatalf("quote error = %v, want %q", err, tt.err)
+			}
+			if tt.want == nil {
+				if quote != nil {
+					t.Fatalf("quote = %+v, want none", quote)
+				}
+				return
+			}
+
+			// The rate holds for LockTTL from the inquiry
+			if quote.LockedUntil.Before(before.Add(u.Fx.LockTTL)) || quote.LockedUntil.After(time.Now().Add(u.Fx.LockTTL)) {
+				t.Fatalf("locked until %v, want %v from now", quote.LockedUntil, u.Fx.LockTTL)
+			}
+			quote.LockedUntil = time.Time{}
+			if *quote != *tt.want {
+				t.Fatalf("quote = %+v, want %+v", quote, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	locked := time.Now().Add(time.Minute)
+	expired := time.Now().Add(-time.Second)
+	myr := &model.FxQuote{Currency: "MYR", QuoteCurrency: "IDR", Rate: 3550.25, LockedUntil: locked}
+
+	tests := []struct {
+		name     string
+		currency string
+		amount   float64
+		quote    *model.FxQuote
+		debit    float64
+		original float64
+		err      model.ErrorCode
+	}{
+		{name: "domestic", currency: "IDR", amount: 25000, debit: 25000},
+		{name: "cross-border", currency: "IDR", amount: 12.34, quote: myr, debit: 43810.09, original: 12.34},
+		// 0.01 MYR is 35.5025 IDR: the debit is rounded to the cent
+		{name: "rounds to the cent", currency: "IDR", amount: 0.01, quote: myr, debit: 35.5, original: 0.01},
+		{name: "rounds half up", currency: "IDR", amount: 1, quote: &model.FxQuote{Currency: "MYR", QuoteCurrency: "IDR", Rate: 0.125, LockedUntil: locked}, debit: 0.13, original: 1},
+		{name: "lock expired", currency: "IDR", amount: 12.34, quote: &model.FxQuote{Currency: "MYR", QuoteCurrency: "IDR", Rate: 3550.25, LockedUntil: expired}, err: model.ErrCodeFxQuoteExpired},
+		{name: "quoted for another currency", currency: "SGD", amount: 12.34, quote: myr, err: model.ErrCodeCurrencyMismatch},
+		{name: "foreign account without quote", currency: "SGD", amount: 25000, err: model.ErrCodeCurrencyMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newCrossBorderUseCase(t, nil)
+			account := &entity.Account{AccountID: "user_123", Currency: tt.currency}
+			transaction := &entity.Transaction{Amount: tt.amount}
+
+			err := u.convert(context.Background(), account, transaction, tt.quote)
+			if code := errorCode(err); code != tt.err {
+				t.Fatalf("convert error = %v, want %q", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+
+			if transaction.Amount != tt.debit || transaction.Currency != tt.currency {
+				t.Fatalf("debit %.2f %s, want %.2f %s", transaction.Amount, transaction.Currency, tt.debit, tt.currency)
+			}
+			if tt.quote == nil {
+				if transaction.OriginalAmount != nil || transaction.FxRate != nil {
+					t.Fatalf("domestic payment kept an fx conversion")
+				}
+				return
+			}
+			if *transaction.OriginalAmount != tt.original || *transaction.OriginalCurrency != tt.quote.Currency || *transaction.FxRate != tt.quote.Rate {
+				t.Fatalf("kept %.2f %s at %v, want %.2f %s at %v",
+					*transaction.OriginalAmount, *transaction.OriginalCurrency, *transaction.FxRate, tt.original, tt.quote.Currency, tt.quote.Rate)
+			}
+			if merchantAmount(transaction) != tt.original {
+				t.Fatalf("merchant amount %.2f, want %.2f", merchantAmount(transaction), tt.original)
+			}
+		})
+	}
+}
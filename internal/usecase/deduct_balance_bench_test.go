@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// benchDSNEnv names the database the lock strategy benchmark runs against, e.g.
+//
+//	BENCH_DATABASE_DSN="host=localhost user=postgres password=postgres dbname=qris_bench sslmode=disable" \
+//	  go test -run '^$' -bench DeductBalance -cpu 1,8,32 ./internal/usecase
+//
+// The schema must be migrated. Every run debits an account of its own and removes it afterwards.
+const benchDSNEnv = "BENCH_DATABASE_DSN"
+
+// BenchmarkDeductBalance debits one account from parallel goroutines under each
+// payment.lock.strategy, the contention the k6 contention script creates through the API.
+// Besides ns/op it reports how many debits failed with a conflict.
+func BenchmarkDeductBalance(b *testing.B) {
+	dsn := os.Getenv(benchDSNEnv)
+	if dsn == "" {
+		b.Skipf("%s is not set", benchDSNEnv)
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger:         logger.Discard,
+		TranslateError: true,
+	})
+	if err != nil {
+		b.Fatalf("connect database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		b.Fatalf("connect database: %v", err)
+	}
+	defer sqlDB.Close()
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	strategies := []PaymentLockConfig{
+		{Strategy: LockStrategyOptimistic, MaxRetries: 3, RetryBackoff: 5 * time.Millisecond},
+		{Strategy: LockStrategyPessimistic},
+	}
+	for _, lockConfig := range strategies {
+		b.Run(lockConfig.Strategy, func(b *testing.B) {
+			u := &QrisUseCase{
+				DB:                db,
+				Log:               log,
+				AccountRepository: repository.NewAccountRepository(log),
+				LockConfig:        lockConfig,
+			}
+
+			account := &entity.Account{
+				AccountID: "bench_" + uuid.NewString(),
+				Balance:   float64(b.N) + 1,
+				Currency:  "IDR",
+				PinHash:   "-",
+			}
+			if err := u.AccountRepository.Create(db, account); err != nil {
+				b.Fatalf("create account: %v", err)
+			}
+			defer u.AccountRepository.Delete(db, account)
+
+			var conflicts atomic.Int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				ctx := context.Background()
+				for pb.Next() {
+					err := debitOnce(ctx, u, account.AccountID, 1)
+					var appErr *model.Error
+					if errors.As(err, &appErr) && appErr.Code == model.ErrCodeTransactionConflict {
+						conflicts.Add(1)
+					} else if err != nil {
+						b.Errorf("debit: %v", err)
+						return
+					}
+				}
+			})
+			b.StopTimer()
+			b.ReportMetric(float64(conflicts.Load())/float64(b.N), "conflicts/op")
+		})
+	}
+}
+
+// debitOnce is the balance part of payFromAccount: read or lock the account as the strategy
+// requires, debit it and commit
+func debitOnce(ctx context.Context, u *QrisUseCase, accountID string, amount float64) error {
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	account := new(entity.Account)
+	if err := u.findAccount(tx, account, accountID); err != nil {
+		return err
+	}
+	if err := u.deductBalance(ctx, tx, account, amount); err != nil {
+		return err
+	}
+	return tx.Commit().Error
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// DeviceUseCase verifies that payment requests are signed by a device bound to the paying
+// account, so an API client cannot move a customer's money on its own. Devices are bound by
+// operators through AdminUseCase.
+type DeviceUseCase struct {
+	DB               *gorm.DB
+	Log              *logrus.Logger
+	DeviceRepository *repository.DeviceRepository
+}
+
+func NewDeviceUseCase(db *gorm.DB, log *logrus.Logger, deviceRepo *repository.DeviceRepository) *DeviceUseCase {
+	return &DeviceUseCase{
+		DB:               db,
+		Log:              log,
+		DeviceRepository: deviceRepo,
+	}
+}
+
+// Verify checks that the payment request is signed by an active device of the paying account.
+// The signature covers the one-time inquiry ID, so it cannot be replayed for another payment.
+func (u *DeviceUseCase) Verify(ctx context.Context, request *model.PaymentRequest) error {
+	ctx, span := tracer.Start(ctx, "DeviceUseCase.Verify")
+	defer span.End()
+
+	if request.DeviceID == "" {
+		u.Log.WithContext(ctx).Warnf("Payment request without device for user: %s", request.UserID)
+		return model.NewError(model.ErrCodeDeviceNotRegistered)
+	}
+
+	device := new(entity.Device)
+	if err := u.DeviceRepository.FindActive(u.DB.WithContext(ctx), device, request.UserID, request.DeviceID); err != nil {
+		u.Log.WithContext(ctx).Warnf("Device %s not registered for user: %s, error: %+v", request.DeviceID, request.UserID, err)
+		return model.NewError(model.ErrCodeDeviceNotRegistered)
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(device.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		u.Log.WithContext(ctx).Errorf("Stored public key of device %s is invalid", device.DeviceID)
+		return model.NewError(model.ErrCodeInternal)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(request.Signature)
+	if err != nil || !ed25519.Verify(publicKey, request.SigningMessage(), signature) {
+		u.Log.WithContext(ctx).Warnf("Invalid device signature from device %s for user: %s", request.DeviceID, request.UserID)
+		return model.NewError(model.ErrCodeInvalidDeviceSig)
+	}
+
+	return nil
+}
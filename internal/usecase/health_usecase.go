@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/repository"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	HealthStatusUp          = "up"
+	HealthStatusDown        = "down"
+	ReadinessStatusReady    = "ready"
+	ReadinessStatusNotReady = "not_ready"
+	ReadinessStatusDraining = "shutting_down"
+)
+
+const (
+	checkDatabase   = "database"
+	checkRedis      = "redis"
+	checkMigrations = "migrations"
+)
+
+type HealthUseCase struct {
+	DB                  *gorm.DB
+	Log                 *logrus.Logger
+	RedisClient         *redis.Client
+	MigrationRepository *repository.MigrationRepository
+	MigrationVersion    uint
+	Timeout             time.Duration
+
+	shuttingDown atomic.Bool
+}
+
+func NewHealthUseCase(
+	db *gorm.DB,
+	log *logrus.Logger,
+	redisClient *redis.Client,
+	migrationRepo *repository.MigrationRepository,
+	migrationVersion uint,
+	timeout time.Duration,
+) *HealthUseCase {
+	return &HealthUseCase{
+		DB:                  db,
+		Log:                 log,
+		RedisClient:         redisClient,
+		MigrationRepository: migrationRepo,
+		MigrationVersion:    migrationVersion,
+		Timeout:             timeout,
+	}
+}
+
+// MarkShuttingDown makes readiness fail so load balancers stop routing new requests here
+func (u *HealthUseCase) MarkShuttingDown() {
+	u.shuttingDown.Store(true)
+}
+
+// Readiness probes every dependency concurrently, each bounded by Timeout
+func (u *HealthUseCase) Readiness(ctx context.Context) *model.ReadinessResponse {
+	checks := map[string]func(ctx context.Context) (string, error){
+		checkDatabase:   u.checkDatabase,
+		checkRedis:      u.checkRedis,
+		checkMigrations: u.checkMigrations,
+	}
+
+	response := &model.ReadinessResponse{
+		Status: ReadinessStatusReady,
+		Checks: make(map[string]*model.DependencyStatus, len(checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, u.Timeout)
+			defer cancel()
+
+			start := time.Now()
+			message, err := check(checkCtx)
+			status := &model.DependencyStatus{
+				Status:    HealthStatusUp,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+				Message:   message,
+			}
+			if err != nil {
+				status.Status = HealthStatusDown
+				status.Error = err.Error()
+				u.Log.WithContext(ctx).Warnf("Readiness check %s failed: %+v", name, err)
+			}
+
+			mu.Lock()
+			response.Checks[name] = status
+			if err != nil {
+				response.Status = ReadinessStatusNotReady
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if u.shuttingDown.Load() {
+		response.Status = ReadinessStatusDraining
+	}
+
+	return response
+}
+
+func (u *HealthUseCase) checkDatabase(ctx context.Context) (string, error) {
+	sqlDB, err := u.DB.DB()
+	if err != nil {
+		return "", err
+	}
+	return "", sqlDB.PingContext(ctx)
+}
+
+func (u *HealthUseCase) checkRedis(ctx context.Context) (string, error) {
+	return "", u.RedisClient.Ping(ctx).Err()
+}
+
+func (u *HealthUseCase) checkMigrations(ctx context.Context) (string, error) {
+	version, dirty, err := u.MigrationRepository.FindVersion(u.DB.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+
+	message := fmt.Sprintf("version %d, expected %d", version, u.MigrationVersion)
+	if dirty {
+		return message, fmt.Errorf("schema is dirty at version %d", version)
+	}
+	if version != u.MigrationVersion {
+		return message, fmt.Errorf("schema version %d does not match expected %d", version, u.MigrationVersion)
+	}
+	return message, nil
+}
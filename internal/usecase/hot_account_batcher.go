@@ -0,0 +1,291 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// HotAccountBatcher serializes debits on high-contention accounts through one goroutine per
+// account and applies them in micro-batches: a single locked balance update and many
+// transaction rows per database transaction. Debits that would overdraw the balance held
+// under the row lock are rejected individually, so the no-overdraft guarantee still holds.
+type HotAccountBatcher struct {
+	DB                    *gorm.DB
+	Log                   *logrus.Logger
+	AccountRepository     *repository.AccountRepository
+	TransactionRepository *repository.TransactionRepository
+	Audit                 *AuditUseCase
+	Accounts              map[string]struct{}
+	MaxBatchSize          int
+	MaxWait               time.Duration
+
+	mu      sync.Mutex
+	queues  map[string]chan *hotDebit
+	stopped bool
+	// sending is held by Debit while it queues, so Stop never closes a queue under a sender
+	sending sync.RWMutex
+	running sync.WaitGroup
+}
+
+type hotDebit struct {
+	ctx         context.Context
+	transaction *entity.Transaction
+	result      chan error
+}
+
+func NewHotAccountBatcher(
+	db *gorm.DB,
+	log *logrus.Logger,
+	accountRepo *repository.AccountRepository,
+	transactionRepo *repository.TransactionRepository,
+	audit *AuditUseCase,
+	accounts []string,
+	maxBatchSize int,
+	maxWait time.Duration,
+) *HotAccountBatcher {
+	hotAccounts := make(map[string]struct{}, len(accounts))
+	for _, accountID := range accounts {
+		hotAccounts[accountID] = struct{}{}
+	}
+
+	return &HotAccountBatcher{
+		DB:                    db,
+		Log:                   log,
+		AccountRepository:     accountRepo,
+		TransactionRepository: transactionRepo,
+		Audit:                 audit,
+		Accounts:              hotAccounts,
+		MaxBatchSize:          maxBatchSize,
+		MaxWait:               maxWait,
+		queues:                make(map[string]chan *hotDebit),
+	}
+}
+
+// IsHot reports whether debits on the account should go through the batcher
+func (b *HotAccountBatcher) IsHot(accountID string) bool {
+	_, ok := b.Accounts[accountID]
+	return ok
+}
+
+// Debit queues the transaction and blocks until the batch containing it is committed or rejected
+func (b *HotAccountBatcher) Debit(ctx context.Context, transaction *entity.Transaction) error {
+	ctx, span := tracer.Start(ctx, "HotAccountBatcher.Debit")
+	defer span.End()
+
+	debit := &hotDebit{
+		ctx:         ctx,
+		transaction: transaction,
+		result:      make(chan error, 1),
+	}
+
+	b.sending.RLock()
+	queue := b.queue(transaction.AccountID)
+	if queue == nil {
+		b.sending.RUnlock()
+		return model.NewError(model.ErrCodeServiceUnavailable)
+	}
+	select {
+	case queue <- debit:
+	case <-ctx.Done():
+		b.sending.RUnlock()
+		return model.NewError(model.ErrCodeTransactionConflict)
+	}
+	b.sending.RUnlock()
+
+	// Once queued the debit may be applied, so wait for the outcome regardless of ctx
+	return <-debit.result
+}
+
+// queue returns the queue of the account, starting its goroutine, or nil once stopped
+func (b *HotAccountBatcher) queue(accountID string) chan *hotDebit {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stopped {
+		return nil
+	}
+	queue, ok := b.queues[accountID]
+	if !ok {
+		queue = make(chan *hotDebit, b.MaxBatchSize*4)
+		b.queues[accountID] = queue
+		b.running.Add(1)
+		go b.run(accountID, queue)
+	}
+	return queue
+}
+
+// Stop refuses new debits and waits until the queued ones are applied. Call it once the
+// HTTP server has drained, while the database is still open.
+func (b *HotAccountBatcher) Stop() {
+	b.sending.Lock()
+	b.mu.Lock()
+	if !b.stopped {
+		b.stopped = true
+		for _, queue := range b.queues {
+			close(queue)
+		}
+	}
+	b.mu.Unlock()
+	b.sending.Unlock()
+
+	b.running.Wait()
+}
+
+// run collects debits until the batch is full or MaxWait has passed since the first one
+func (b *HotAccountBatcher) run(accountID string, queue chan *hotDebit) {
+	defer b.running.Done()
+
+	for first := range queue {
+		batch := []*hotDebit{first}
+		timer := time.NewTimer(b.MaxWait)
+
+	collect:
+		for len(batch) < b.MaxBatchSize {
+			select {
+			case debit, ok := <-queue:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, debit)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+
+		results := b.apply(accountID, batch)
+		for i, debit := range batch {
+			debit.result <- results[i]
+		}
+	}
+}
+
+func (b *HotAccountBatcher) apply(accountID string, batch []*hotDebit) []error {
+	results := make([]error, len(batch))
+	fail := func(err error) []error {
+		for i := range results {
+			if results[i] == nil {
+				results[i] = err
+			}
+		}
+		return results
+	}
+
+	tx := b.DB.Begin()
+	defer tx.Rollback()
+
+	account := new(entity.Account)
+	if err := b.AccountRepository.LockByAccountID(tx, account, accountID); err != nil {
+		b.Log.Warnf("Account not found for hot account batch: %s, error: %+v", accountID, err)
+		return fail(model.NewError(model.ErrCodeAccountNotFound))
+	}
+
+	// The rows are copies: the callers' transactions only become SUCCESS once the batch commits
+	now := tx.NowFunc()
+	remaining := account.Balance
+	rows := make([]*entity.Transaction, len(batch))
+	var accepted []int
+	for i, debit := range batch {
+		if debit.transaction.Amount > remaining {
+			b.Log.WithContext(debit.ctx).Warnf("Insufficient balance for user: %s", accountID)
+			results[i] = model.NewError(model.ErrCodeInsufficientBalance)
+			continue
+		}
+
+		remaining -= debit.transaction.Amount
+		row := *debit.transaction
+		row.Status = entity.TransactionStatusSuccess
+		row.DebitedAt = &now
+		rows[i] = &row
+		accepted = append(accepted, i)
+	}
+
+	if len(accepted) == 0 {
+		return results
+	}
+
+	accepted, err := b.createTransactions(tx, batch, rows, accepted, results)
+	if err != nil {
+		b.Log.Warnf("Failed to create batched transactions: %+v", err)
+		return fail(model.NewError(model.ErrCodeInternal))
+	}
+	if len(accepted) == 0 {
+		return results
+	}
+
+	var total float64
+	events := make([]*entity.AuditEvent, 0, len(accepted))
+	for _, i := range accepted {
+		total += rows[i].Amount
+		events = append(events, b.Audit.Event(batch[i].ctx, entity.AuditPaymentCreated, entity.AuditTargetTransaction, rows[i].TransactionID, nil, toTransactionResponse(rows[i])))
+	}
+
+	// The row lock guarantees the version is current, the balance guard is a last line of defence
+	if err := b.AccountRepository.DeductBalance(tx, accountID, total, account.Version); err != nil {
+		b.Log.Warnf("Failed to deduct batched balance: %+v", err)
+		return fail(model.NewError(model.ErrCodeInternal))
+	}
+
+	if err := b.Audit.Append(context.Background(), tx, events...); err != nil {
+		b.Log.Warnf("Failed to audit hot account batch: %+v", err)
+		return fail(model.NewError(model.ErrCodeInternal))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		b.Log.Warnf("Failed to commit hot account batch: %+v", err)
+		return fail(model.NewError(model.ErrCodeInternal))
+	}
+	for _, i := range accepted {
+		*batch[i].transaction = *rows[i]
+	}
+
+	b.Log.WithField("account_id", accountID).Debugf("Applied hot account batch: %d of %d debits, total %.2f", len(accepted), len(batch), total)
+	return results
+}
+
+// createTransactions inserts the rows of the accepted debits in one statement. When that fails,
+// e.g. on a duplicate transaction ID, it inserts them one by one so a bad row only fails its own
+// debit, and returns the debits that were inserted. Savepoints keep tx usable after a failed insert.
+func (b *HotAccountBatcher) createTransactions(tx *gorm.DB, batch []*hotDebit, rows []*entity.Transaction, accepted []int, results []error) ([]int, error) {
+	transactions := make([]*entity.Transaction, 0, len(accepted))
+	for _, i := range accepted {
+		transactions = append(transactions, rows[i])
+	}
+
+	if err := tx.SavePoint("batch").Error; err != nil {
+		return nil, err
+	}
+	err := b.TransactionRepository.CreateAll(tx, transactions)
+	if err == nil {
+		return accepted, nil
+	}
+	b.Log.Warnf("Failed to create %d batched transactions, inserting them one by one: %+v", len(transactions), err)
+	if err := tx.RollbackTo("batch").Error; err != nil {
+		return nil, err
+	}
+
+	inserted := accepted[:0]
+	for _, i := range accepted {
+		if err := tx.SavePoint("debit").Error; err != nil {
+			return nil, err
+		}
+		if err := b.TransactionRepository.Create(tx, rows[i]); err != nil {
+			b.Log.WithContext(batch[i].ctx).Warnf("Failed to create batched transaction %s: %+v", rows[i].TransactionID, err)
+			results[i] = model.NewError(model.ErrCodeInternal)
+			if err := tx.RollbackTo("debit").Error; err != nil {
+				return nil, err
+			}
+			continue
+		}
+		inserted = append(inserted, i)
+	}
+	return inserted, nil
+}
@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// TestHotAccountBatcherStop queues debits behind a long MaxWait and stops the batcher: the
+// queued debits are still applied, and debits arriving afterwards are refused. The database
+// runs dry, so no account is found and each applied debit fails with ACCOUNT_NOT_FOUND.
+func TestHotAccountBatcherStop(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry run database: %v", err)
+	}
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	batcher := NewHotAccountBatcher(
+		db,
+		log,
+		repository.NewAccountRepository(log),
+		repository.NewTransactionRepository(log),
+		nil,
+		[]string{"hot_1", "hot_2"},
+		100,
+		time.Hour,
+	)
+
+	// Queued the way Debit does, so all of them are in before Stop
+	var debits []*hotDebit
+	for i := range 6 {
+		debit := &hotDebit{
+			ctx:         context.Background(),
+			transaction: &entity.Transaction{AccountID: []string{"hot_1", "hot_2"}[i%2], Amount: 1000},
+			result:      make(chan error, 1),
+		}
+		batcher.queue(debit.transaction.AccountID) <- debit
+		debits = append(debits, debit)
+	}
+
+	// Without Stop the debits would wait for MaxWait
+	stopped := make(chan struct{})
+	go func() {
+		batcher.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Stop did not return")
+	}
+
+	for i, debit := range debits {
+		select {
+		case err := <-debit.result:
+			var appErr *model.Error
+			if !errors.As(err, &appErr) || appErr.Code != model.ErrCodeAccountNotFound {
+				t.Fatalf("queued debit %d got %v, want it applied", i, err)
+			}
+		default:
+			t.Fatalf("queued debit %d not applied when Stop returned", i)
+		}
+	}
+
+	err = batcher.Debit(context.Background(), &entity.Transaction{AccountID: "hot_1", Amount: 1000})
+	var appErr *model.Error
+	if !errors.As(err, &appErr) || appErr.Code != model.ErrCodeServiceUnavailable {
+		t.Fatalf("debit after Stop got %v, want %s", err, model.ErrCodeServiceUnavailable)
+	}
+
+	// Stopping twice is harmless
+	batcher.Stop()
+}
+
+// dryRunConn stands in for a connection in dry run tests, which never send a statement
+type dryRunConn struct{}
+
+func (dryRunConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errDryRun
+}
+
+func (dryRunConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, errDryRun
+}
+
+func (dryRunConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errDryRun
+}
+
+func (dryRunConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+// dryRunPool lets dry run transactions begin and commit without a server; their Commit fails
+// with commitErr when set
+type dryRunPool struct {
+	dryRunConn
+	commitErr error
+}
+
+func (p *dryRunPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return &dryRunTx{commitErr: p.commitErr}, nil
+}
+
+type dryRunTx struct {
+	dryRunConn
+	commitErr error
+}
+
+func (tx *dryRunTx) Commit() error {
+	return tx.commitErr
+}
+
+func (tx *dryRunTx) Rollback() error {
+	return nil
+}
+
+var errDryRun = errors.New("dry run")
+
+// TestHotAccountBatcherApply checks what the callers see of their transactions: SUCCESS once
+// the batch commits, and untouched when the batch fails or the debit would overdraw
+func TestHotAccountBatcherApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		deducted  bool
+		commitErr error
+		want      []model.ErrorCode
+	}{
+		{name: "committed", deducted: true, want: []model.ErrorCode{"", "", model.ErrCodeInsufficientBalance}},
+		{name: "balance update failed", deducted: false, want: []model.ErrorCode{model.ErrCodeInternal, model.ErrCodeInternal, model.ErrCodeInsufficientBalance}},
+		{name: "commit failed", deducted: true, commitErr: errors.New("connection reset"), want: []model.ErrorCode{model.ErrCodeInternal, model.ErrCodeInternal, model.ErrCodeInsufficientBalance}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := gorm.Open(postgres.New(postgres.Config{Conn: &dryRunPool{commitErr: tt.commitErr}}), &gorm.Config{
+				DryRun:               true,
+				DisableAutomaticPing: true,
+			})
+			if err != nil {
+				t.Fatalf("open dry run database: %v", err)
+			}
+			// The locked account holds 2500, enough for the first two debits of 1000
+			db.Callback().Query().Replace("gorm:query", func(db *gorm.DB) {
+				if account, ok := db.Statement.Dest.(*entity.Account); ok {
+					*account = entity.Account{AccountID: "hot_1", Balance: 2500, Version: 1}
+				}
+			})
+			if tt.deducted {
+				db.Callback().Update().Replace("gorm:update", func(db *gorm.DB) {
+					db.RowsAffected = 1
+				})
+			}
+			log := logrus.New()
+			log.SetOutput(io.Discard)
+
+			batcher := NewHotAccountBatcher(
+				db,
+				log,
+				repository.NewAccountRepository(log),
+				repository.NewTransactionRepository(log),
+				NewAuditUseCase(db, log, nil, repository.NewAuditEventRepository(log), 1),
+				[]string{"hot_1"},
+				100,
+				time.Hour,
+			)
+
+			var batch []*hotDebit
+			for i := range 3 {
+				batch = append(batch, &hotDebit{
+					ctx: context.Background(),
+					transaction: &entity.Transaction{
+						TransactionID: fmt.Sprintf("txn_%d", i),
+						AccountID:     "hot_1",
+						Amount:        1000,
+						Status:        entity.TransactionStatusPending,
+					},
+				})
+			}
+
+			results := batcher.apply("hot_1", batch)
+			for i, debit := range batch {
+				if code := errorCode(results[i]); code != tt.want[i] {
+					t.Fatalf("debit %d got %v, want %q", i, results[i], tt.want[i])
+				}
+
+				debited := results[i] == nil
+				if debited != (debit.transaction.Status == entity.TransactionStatusSuccess) || debited != (debit.transaction.DebitedAt != nil) {
+					t.Fatalf("debit %d left as %s, debited at %v, after %v", i, debit.transaction.Status, debit.transaction.DebitedAt, results[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// LimitTier caps what an account may spend, in its own currency. Zero means unlimited.
+type LimitTier struct {
+	SingleMax   float64
+	DailyMax    float64
+	MonthlyMax  float64
+	HourlyCount int
+}
+
+// reserveScript checks every window and counts the payment in all of them, or in none. Amounts
+// are kept in cents. It returns -1 when a counter is missing and must be seeded from the database,
+// 0 when the payment fits and the number of the exceeded window (1 daily, 2 monthly, 3 hourly).
+var reserveScript = redis.NewScript(`
+for i = 1, 3 do
+	if redis.call('EXISTS', KEYS[i]) == 0 then
+		return -1
+	end
+end
+local amount = tonumber(ARGV[1])
+for i = 1, 3 do
+	local limit = tonumber(ARGV[i + 1])
+	local increment = amount
+	if i == 3 then
+		increment = 1
+	end
+	if limit > 0 and tonumber(redis.call('GET', KEYS[i])) + increment > limit then
+		return i
+	end
+end
+redis.call('INCRBY', KEYS[1], amount)
+redis.call('INCRBY', KEYS[2], amount)
+redis.call('INCR', KEYS[3])
+redis.call('SET', KEYS[4], ARGV[1], 'EX', ARGV[5])
+return 0
+`)
+
+// releaseScript takes a reservation back once, when its marker still exists
+var releaseScript = redis.NewScript(`
+local amount = redis.call('GET', KEYS[4])
+if amount == false then
+	return 0
+end
+redis.call('DEL', KEYS[4])
+for i = 1, 3 do
+	if redis.call('EXISTS', KEYS[i]) == 1 then
+		if i == 3 then
+			redis.call('DECR', KEYS[i])
+		else
+			redis.call('DECRBY', KEYS[i], amount)
+		end
+	end
+end
+return 1
+`)
+
+// reservationTTL outlives any payment; a reservation not released by then stays counted
+const reservationTTL = time.Hour
+
+// LimitUseCase enforces per-account spending tiers: a single transaction maximum, daily and
+// monthly cumulative amounts and a count of transactions per clock hour. Counters live in Redis
+// and are seeded from the transactions table; when Redis is down the table is checked directly.
+type LimitUseCase struct {
+	DB                    *gorm.DB
+	Log                   *logrus.Logger
+	Validate              *validator.Validate
+	RedisClient           *redis.Client
+	AccountRepository     *repository.AccountRepository
+	TransactionRepository *repository.TransactionRepository
+	Tiers                 map[string]LimitTier
+	DefaultTier           string
+	Location              *time.Location
+}
+
+func NewLimitUseCase(
+	db *gorm.DB,
+	log *logrus.Logger,
+	validate *validator.Validate,
+	redisClient *redis.Client,
+	accountRepo *repository.AccountRepository,
+	transactionRepo *repository.TransactionRepository,
+	tiers map[string]LimitTier,
+	defaultTier string,
+	location *time.Location,
+) *LimitUseCase {
+	return &LimitUseCase{
+		DB:                    db,
+		Log:                   log,
+		Validate:              validate,
+		RedisClient:           redisClient,
+		AccountRepository:     accountRepo,
+		TransactionRepository: transactionRepo,
+		Tiers:                 tiers,
+		DefaultTier:           defaultTier,
+		Location:              location,
+	}
+}
+
+// limitWindow is one counter: its Redis key, when it started and how long the key is kept
+type limitWindow struct {
+	key   string
+	start time.Time
+	ttl   time.Duration
+}
+
+// Reserve counts the transaction against the account's limits, or rejects it when it does not
+// fit. A reservation is taken back with Release if the payment fails.
+func (u *LimitUseCase) Reserve(ctx context.Context, account *entity.Account, transaction *entity.Transaction) error {
+	ctx, span := tracer.Start(ctx, "LimitUseCase.Reserve")
+	defer span.End()
+
+	name, tier := u.tier(account)
+	if tier.SingleMax > 0 && transaction.Amount > tier.SingleMax {
+		u.Log.WithContext(ctx).Warnf("Transaction limit exceeded for user: %s, tier: %s", account.AccountID, name)
+		return model.NewError(model.ErrCodeTxnLimitExceeded)
+	}
+
+	// Release finds the windows again from the creation time, which the row keeps. It is
+	// stored as database wall clock time, the same as SumSpending compares against.
+	if transaction.CreatedAt.IsZero() {
+		transaction.CreatedAt = u.DB.NowFunc()
+	}
+	windows := u.windows(account.AccountID, transaction.CreatedAt)
+	keys := []string{windows[0].key, windows[1].key, windows[2].key, u.reservationKey(transaction)}
+	args := []interface{}{cents(transaction.Amount), cents(tier.DailyMax), cents(tier.MonthlyMax), tier.HourlyCount, int(reservationTTL.Seconds())}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		result, err := reserveScript.Run(ctx, u.RedisClient, keys, args...).Int()
+		if err != nil {
+			u.Log.WithContext(ctx).Warnf("Limit counters unavailable, checking the database: %+v", err)
+			return u.checkDatabase(ctx, account.AccountID, name, tier, transaction.Amount, windows)
+		}
+
+		switch result {
+		case -1:
+			if err := u.seed(ctx, account.AccountID, windows); err != nil {
+				u.Log.WithContext(ctx).Warnf("Failed to seed limit counters: %+v", err)
+				return u.checkDatabase(ctx, account.AccountID, name, tier, transaction.Amount, windows)
+			}
+		case 0:
+			return nil
+		default:
+			return u.exceeded(ctx, account.AccountID, name, result)
+		}
+	}
+
+	return u.checkDatabase(ctx, account.AccountID, name, tier, transaction.Amount, windows)
+}
+
+// Release takes back the reservation of a payment that failed. It is a no-op when the
+// transaction was never reserved or was already released.
+func (u *LimitUseCase) Release(ctx context.Context, transaction *entity.Transaction) {
+	// The reservation was counted in the windows of the payment, not of the release
+	windows := u.windows(transaction.AccountID, transaction.CreatedAt)
+	keys := []string{windows[0].key, windows[1].key, windows[2].key, u.reservationKey(transaction)}
+
+	if err := releaseScript.Run(context.WithoutCancel(ctx), u.RedisClient, keys).Err(); err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to release limit reservation for transaction: %s, error: %+v", transaction.TransactionID, err)
+	}
+}
+
+// Remaining reports the account's limits and how much of each is left
+func (u *LimitUseCase) Remaining(ctx context.Context, request *model.AccountLimitsRequest) (*model.AccountLimitsResponse, error) {
+	ctx, span := tracer.Start(ctx, "LimitUseCase.Remaining")
+	defer span.End()
+
+	if err := u.Validate.Struct(request); err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid account limits request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	account := new(entity.Account)
+	if err := u.AccountRepository.FindByAccountID(u.DB.WithContext(ctx), account, request.AccountID); err != nil {
+		u.Log.WithContext(ctx).Warnf("Account not found: %s, error: %+v", request.AccountID, err)
+		return nil, model.NewError(model.ErrCodeAccountNotFound)
+	}
+
+	name, tier := u.tier(account)
+	windows := u.windows(account.AccountID, time.Now())
+	used, err := u.usage(ctx, account.AccountID, windows)
+	if err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to read limit usage: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	return &model.AccountLimitsResponse{
+		AccountID:            account.AccountID,
+		Tier:                 name,
+		Currency:             account.Currency,
+		SingleTransactionMax: optionalLimit(tier.SingleMax),
+		Daily:                limitUsage(tier.DailyMax, float64(used[0])/100),
+		Monthly:              limitUsage(tier.MonthlyMax, float64(used[1])/100),
+		HourlyCount:          limitUsage(float64(tier.HourlyCount), float64(used[2])),
+	}, nil
+}
+
+// usage reads the counters, seeding missing ones, or sums the database when Redis is down
+func (u *LimitUseCase) usage(ctx context.Context, accountID string, windows []limitWindow) ([]int64, error) {
+	values, err := u.RedisClient.MGet(ctx, windows[0].key, windows[1].key, windows[2].key).Result()
+	if err == nil {
+		used := make([]int64, len(values))
+		for i, value := range values {
+			text, ok := value.(string)
+			if !ok {
+				return u.seedUsage(ctx, accountID, windows)
+			}
+			if _, err := fmt.Sscan(text, &used[i]); err != nil {
+				return nil, err
+			}
+		}
+		return used, nil
+	}
+
+	u.Log.WithContext(ctx).Warnf("Limit counters unavailable, reading the database: %+v", err)
+	return u.databaseUsage(ctx, accountID, windows)
+}
+
+func (u *LimitUseCase) seedUsage(ctx context.Context, accountID string, windows []limitWindow) ([]int64, error) {
+	if err := u.seed(ctx, accountID, windows); err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to seed limit counters: %+v", err)
+	}
+	return u.databaseUsage(ctx, accountID, windows)
+}
+
+// seed initializes missing counters from the transactions table. SET NX keeps counters that
+// a concurrent payment created in the meantime.
+func (u *LimitUseCase) seed(ctx context.Context, accountID string, windows []limitWindow) error {
+	used, err := u.databaseUsage(ctx, accountID, windows)
+	if err != nil {
+		return err
+	}
+
+	pipe := u.RedisClient.Pipeline()
+	for i, window := range windows {
+		pipe.SetNX(ctx, window.key, used[i], window.ttl)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// databaseUsage is the spent amount of the day and month in cents and the hour's transaction count
+func (u *LimitUseCase) databaseUsage(ctx context.Context, accountID string, windows []limitWindow) ([]int64, error) {
+	db := u.DB.WithContext(ctx)
+
+	daily, _, err := u.TransactionRepository.SumSpending(db, accountID, windows[0].start)
+	if err != nil {
+		return nil, err
+	}
+	monthly, _, err := u.TransactionRepository.SumSpending(db, accountID, windows[1].start)
+	if err != nil {
+		return nil, err
+	}
+	_, hourly, err := u.TransactionRepository.SumSpending(db, accountID, windows[2].start)
+	if err != nil {
+		return nil, err
+	}
+
+	return []int64{cents(daily), cents(monthly), hourly}, nil
+}
+
+// checkDatabase enforces the limits from the transactions table. Without the atomic counters
+// concurrent payments of one account may overshoot a limit; this only runs while Redis is down.
+func (u *LimitUseCase) checkDatabase(ctx context.Context, accountID string, name string, tier LimitTier, amount float64, windows []limitWindow) error {
+	used, err := u.databaseUsage(ctx, accountID, windows)
+	if err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to read limit usage: %+v", err)
+		return model.NewError(model.ErrCodeInternal)
+	}
+
+	switch {
+	case tier.DailyMax > 0 && used[0]+cents(amount) > cents(tier.DailyMax):
+		return u.exceeded(ctx, accountID, name, 1)
+	case tier.MonthlyMax > 0 && used[1]+cents(amount) > cents(tier.MonthlyMax):
+		return u.exceeded(ctx, accountID, name, 2)
+	case tier.HourlyCount > 0 && used[2]+1 > int64(tier.HourlyCount):
+		return u.exceeded(ctx, accountID, name, 3)
+	}
+	return nil
+}
+
+func (u *LimitUseCase) exceeded(ctx context.Context, accountID string, name string, window int) error {
+	code := map[int]model.ErrorCode{
+		1: model.ErrCodeDailyLimitExceeded,
+		2: model.ErrCodeMonthlyLimitExceeded,
+		3: model.ErrCodeVelocityLimitExceeded,
+	}[window]
+
+	u.Log.WithContext(ctx).Warnf("%s for user: %s, tier: %s", code, accountID, name)
+	return model.NewError(code)
+}
+
+// tier returns the account's tier, or the default tier for accounts without a known one
+func (u *LimitUseCase) tier(account *entity.Account) (string, LimitTier) {
+	if tier, ok := u.Tiers[account.LimitTier]; ok {
+		return account.LimitTier, tier
+	}
+	return u.DefaultTier, u.Tiers[u.DefaultTier]
+}
+
+// windows returns the day, month and clock hour containing at, in the database time zone.
+// Keys share the account as hash tag so the scripts also run on Redis Cluster.
+func (u *LimitUseCase) windows(accountID string, at time.Time) []limitWindow {
+	at = at.In(u.Location)
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, u.Location)
+	month := time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, u.Location)
+	hour := at.Truncate(time.Hour)
+
+	return []limitWindow{
+		{fmt.Sprintf("limits:{%s}:daily:%s", accountID, day.Format("20060102")), day, 48 * time.Hour},
+		{fmt.Sprintf("limits:{%s}:monthly:%s", accountID, month.Format("200601")), month, 32 * 24 * time.Hour},
+		{fmt.Sprintf("limits:{%s}:hourly:%s", accountID, hour.Format("2006010215")), hour, 2 * time.Hour},
+	}
+}
+
+func (u *LimitUseCase) reservationKey(transaction *entity.Transaction) string {
+	return fmt.Sprintf("limits:{%s}:reservation:%s", transaction.AccountID, transaction.TransactionID)
+}
+
+func cents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func optionalLimit(limit float64) *float64 {
+	if limit <= 0 {
+		return nil
+	}
+	return &limit
+}
+
+// limitUsage reports a window; Limit and Remaining are left out when the window is unlimited
+func limitUsage(limit float64, used float64) *model.LimitUsage {
+	usage := &model.LimitUsage{Used: used}
+	if limit > 0 {
+		remaining := math.Max(math.Round((limit-used)*100)/100, 0)
+		usage.Limit = &limit
+		usage.Remaining = &remaining
+	}
+	return usage
+}
@@ -0,0 +1,453 @@
+package usecase
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// listVersionKey is bumped with every change written through to the list cache
+const listVersionKey = "lists:version"
+
+// cacheLookupsScript caches database lookups unless a change was written through since they were
+// read. KEYS[1] is the version key, ARGV[1] the version read before the lookup ("" for none);
+// KEYS[i] for i > 1 gets the entry ID ARGV[2i-2] for ARGV[2i-1] milliseconds.
+var cacheLookupsScript = redis.NewScript(`
+if (redis.call('GET', KEYS[1]) or '') ~= ARGV[1] then
+	return 0
+end
+for i = 2, #KEYS do
+	redis.call('SET', KEYS[i], ARGV[2 * i - 2], 'PX', ARGV[2 * i - 1])
+end
+return 1
+`)
+
+// ListUseCase manages the blacklist and the whitelist. Lookups are cached in Redis per value and
+// every change is written through to the cache, so new entries take effect at once.
+type ListUseCase struct {
+	DB                  *gorm.DB
+	Log                 *logrus.Logger
+	Validate            *validator.Validate
+	RedisClient         *redis.Client
+	ListEntryRepository *repository.ListEntryRepository
+	CacheTTL            time.Duration
+	Audit               *AuditUseCase
+	// Location is the database time zone expires_at is stored in
+	Location *time.Location
+}
+
+func NewListUseCase(
+	db *gorm.DB,
+	log *logrus.Logger,
+	validate *validator.Validate,
+	redisClient *redis.Client,
+	listEntryRepository *repository.ListEntryRepository,
+	cacheTTL time.Duration,
+	audit *AuditUseCase,
+	location *time.Location,
+) *ListUseCase {
+	return &ListUseCase{
+		DB:                  db,
+		Log:                 log,
+		Validate:            validate,
+		RedisClient:         redisClient,
+		ListEntryRepository: listEntryRepository,
+		CacheTTL:            cacheTTL,
+		Audit:               audit,
+		Location:            location,
+	}
+}
+
+// Create adds an entry on behalf of request.Actor. An expired entry of the same value is taken
+// off the list first, it would still hold the value's live slot.
+func (u *ListUseCase) Create(ctx context.Context, request *model.CreateListEntryRequest) (*model.ListEntryResponse, error) {
+	ctx, span := tracer.Start(ctx, "ListUseCase.Create")
+	defer span.End()
+
+	if err := u.Validate.Struct(request); err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid list entry request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	value := request.Value
+	if request.EntryType == entity.ListEntryQRHash {
+		value = strings.ToLower(value)
+		if decoded, err := hex.DecodeString(value); err != nil || len(decoded) != 32 {
+			u.Log.WithContext(ctx).Warnf("Invalid QR hash: %s", request.Value)
+			return nil, model.NewError(model.ErrCodeValidationFailed)
+		}
+	}
+
+	expiresAt, err := u.expiry(ctx, request.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &entity.ListEntry{
+		List:      request.List,
+		EntryType: request.EntryType,
+		Value:     value,
+		Reason:    request.Reason,
+		ExpiresAt: expiresAt,
+		CreatedBy: request.Actor,
+	}
+
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	key := repository.ListKey{EntryType: entry.EntryType, Value: entry.Value}
+	if err := u.ListEntryRepository.RemoveExpired(tx, entry.List, key, request.Actor, tx.NowFunc()); err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to remove expired list entries: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	if err := u.ListEntryRepository.Create(tx, entry); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, model.NewError(model.ErrCodeAlreadyExists)
+		}
+		u.Log.WithContext(ctx).Errorf("Failed to create list entry: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	response := toListEntryResponse(entry)
+	event := u.Audit.Event(ctx, entity.AuditListEntryCreated, entity.AuditTargetListEntry, strconv.FormatInt(entry.EntryID, 10), nil, response)
+	if err := u.Audit.Append(ctx, tx, event); err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to audit list entry: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to commit list entry: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+	u.refreshCache(ctx, entry)
+
+	u.Log.WithContext(ctx).WithFields(listEntryFields(entry)).Infof("List entry added by %s: %s", entry.CreatedBy, entry.Reason)
+	return response, nil
+}
+
+// Get returns an entry, removed or not
+func (u *ListUseCase) Get(ctx context.Context, entryID int64) (*model.ListEntryResponse, error) {
+	ctx, span := tracer.Start(ctx, "ListUseCase.Get")
+	defer span.End()
+
+	entry := new(entity.ListEntry)
+	if err := u.ListEntryRepository.FindByEntryID(u.DB.WithContext(ctx), entry, entryID); err != nil {
+		u.Log.WithContext(ctx).Warnf("List entry not found: %d, error: %+v", entryID, err)
+		return nil, model.NewError(model.ErrCodeListEntryNotFound)
+	}
+
+	return toListEntryResponse(entry), nil
+}
+
+// Update changes the reason and the expiry of a live entry on behalf of request.Actor
+func (u *ListUseCase) Update(ctx context.Context, request *model.UpdateListEntryRequest) (*model.ListEntryResponse, error) {
+	ctx, span := tracer.Start(ctx, "ListUseCase.Update")
+	defer span.End()
+
+	if err := u.Validate.Struct(request); err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid list entry request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	expiresAt, err := u.expiry(ctx, request.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	entry := new(entity.ListEntry)
+	if err := u.ListEntryRepository.FindByEntryID(tx, entry, request.EntryID); err != nil || entry.RemovedAt != nil {
+		u.Log.WithContext(ctx).Warnf("List entry not found: %d, error: %+v", request.EntryID, err)
+		return nil, model.NewError(model.ErrCodeListEntryNotFound)
+	}
+
+	before := toListEntryResponse(entry)
+	now := tx.NowFunc()
+	entry.Reason = request.Reason
+	entry.ExpiresAt = expiresAt
+	entry.UpdatedBy = &request.Actor
+	entry.UpdatedAt = &now
+	if err := u.ListEntryRepository.Update(tx, entry); err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to update list entry: %d, error: %+v", entry.EntryID, err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	response := toListEntryResponse(entry)
+	event := u.Audit.Event(ctx, entity.AuditListEntryUpdated, entity.AuditTargetListEntry, strconv.FormatInt(entry.EntryID, 10), before, response)
+	if err := u.Audit.Append(ctx, tx, event); err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to audit list entry: %d, error: %+v", entry.EntryID, err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to commit list entry: %d, error: %+v", entry.EntryID, err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+	u.refreshCache(ctx, entry)
+
+	u.Log.WithContext(ctx).Infof("List entry %d updated by %s: %s", entry.EntryID, request.Actor, entry.Reason)
+	return response, nil
+}
+
+// Remove takes an entry off its list on behalf of request.Actor; the entry is kept for auditing
+func (u *ListUseCase) Remove(ctx context.Context, request *model.RemoveListEntryRequest) (*model.ListEntryResponse, error) {
+	ctx, span := tracer.Start(ctx, "ListUseCase.Remove")
+	defer span.End()
+
+	if err := u.Validate.Struct(request); err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid list entry request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	err := u.ListEntryRepository.Remove(tx, request.EntryID, request.Actor)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.NewError(model.ErrCodeListEntryNotFound)
+	}
+	if err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to remove list entry: %d, error: %+v", request.EntryID, err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	entry := new(entity.ListEntry)
+	if err := u.ListEntryRepository.FindByEntryID(tx, entry, request.EntryID); err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to reload list entry: %d, error: %+v", request.EntryID, err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	response := toListEntryResponse(entry)
+	event := u.Audit.Event(ctx, entity.AuditListEntryRemoved, entity.AuditTargetListEntry, strconv.FormatInt(entry.EntryID, 10), nil, response)
+	if err := u.Audit.Append(ctx, tx, event); err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to audit list entry: %d, error: %+v", request.EntryID, err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to commit list entry: %d, error: %+v", request.EntryID, err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+	u.refreshCache(ctx, entry)
+
+	u.Log.WithContext(ctx).WithFields(listEntryFields(entry)).Infof("List entry removed by %s", request.Actor)
+	return response, nil
+}
+
+// Search pages through the entries, newest first
+func (u *ListUseCase) Search(ctx context.Context, request *model.SearchListEntriesRequest) (*model.ListEntriesResponse, error) {
+	ctx, span := tracer.Start(ctx, "ListUseCase.Search")
+	defer span.End()
+
+	if err := u.Validate.Struct(request); err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid list search request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	query := repository.ListEntryQuery{
+		List:           request.List,
+		EntryType:      request.EntryType,
+		Value:          request.Value,
+		IncludeRemoved: request.IncludeRemoved,
+	}
+	entries, total, err := u.ListEntryRepository.Search(u.DB.WithContext(ctx), query, (request.Page-1)*request.Size, request.Size)
+	if err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to search list entries: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	response := &model.ListEntriesResponse{
+		Entries: make([]*model.ListEntryResponse, len(entries)),
+		Paging: &model.PageMetadata{
+			Page:      request.Page,
+			Size:      request.Size,
+			TotalItem: total,
+			TotalPage: (total + int64(request.Size) - 1) / int64(request.Size),
+		},
+	}
+	for i, entry := range entries {
+		response.Entries[i] = toListEntryResponse(&entry)
+	}
+
+	return response, nil
+}
+
+// Match returns the ID of a live entry of the list matching any of the keys, zero when none
+// does. Values missing from the cache are looked up in the database, which is also used on its
+// own while Redis is unavailable.
+func (u *ListUseCase) Match(ctx context.Context, list string, keys ...repository.ListKey) (int64, error) {
+	cacheKeys := make([]string, len(keys)+1)
+	cacheKeys[0] = listVersionKey
+	for i, key := range keys {
+		cacheKeys[i+1] = listCacheKey(list, key)
+	}
+
+	cached, err := u.RedisClient.MGet(ctx, cacheKeys...).Result()
+	if err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to read list cache, checking the database: %+v", err)
+		cached = make([]interface{}, len(cacheKeys))
+	}
+	version, _ := cached[0].(string)
+
+	var missing []repository.ListKey
+	for i, value := range cached[1:] {
+		value, ok := value.(string)
+		if !ok {
+			missing = append(missing, keys[i])
+			continue
+		}
+		if entryID, _ := strconv.ParseInt(value, 10, 64); entryID > 0 {
+			return entryID, nil
+		}
+	}
+	if len(missing) == 0 {
+		return 0, nil
+	}
+
+	db := u.DB.WithContext(ctx)
+	now := db.NowFunc()
+	entries, err := u.ListEntryRepository.FindLive(db, list, missing, now)
+	if err != nil {
+		return 0, err
+	}
+
+	found := make(map[repository.ListKey]*entity.ListEntry, len(entries))
+	for i := range entries {
+		found[repository.ListKey{EntryType: entries[i].EntryType, Value: entries[i].Value}] = &entries[i]
+	}
+
+	// Misses are cached too so clean values do not hit the database on every payment. They are
+	// only cached if no entry changed since the version was read, or a lookup racing with a new
+	// entry could cache a miss over it.
+	var matched int64
+	cacheKeys = []string{listVersionKey}
+	args := []interface{}{version}
+	for _, key := range missing {
+		entryID, ttl := int64(0), u.CacheTTL
+		if entry, ok := found[key]; ok {
+			entryID = entry.EntryID
+			if entry.ExpiresAt != nil {
+				ttl = min(ttl, untilExpiry(*entry.ExpiresAt, now))
+			}
+			if matched == 0 {
+				matched = entryID
+			}
+		}
+		cacheKeys = append(cacheKeys, listCacheKey(list, key))
+		args = append(args, entryID, max(ttl.Milliseconds(), 1))
+	}
+	if err := cacheLookupsScript.Run(ctx, u.RedisClient, cacheKeys, args...).Err(); err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to cache list lookups: %+v", err)
+	}
+
+	return matched, nil
+}
+
+// expiry parses an optional RFC 3339 expiry, which must lie in the future. The column keeps wall
+// clock time without an offset, so the expiry is converted to the database time zone first.
+func (u *ListUseCase) expiry(ctx context.Context, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, value)
+	if err != nil || !expiresAt.After(time.Now()) {
+		u.Log.WithContext(ctx).Warnf("Invalid list entry expiry: %s", value)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+	expiresAt = expiresAt.In(u.Location)
+	return &expiresAt, nil
+}
+
+// untilExpiry is the time left before expiresAt, now being database time. Expiries read back
+// from the database are wall clock times labelled UTC, so the wall clocks are compared.
+func untilExpiry(expiresAt time.Time, now time.Time) time.Duration {
+	wall := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), expiresAt.Location())
+	return expiresAt.Sub(wall)
+}
+
+// refreshCache writes the entry through to the lookup cache of its value, or drops the cached
+// lookup once the entry is removed. Bumping the version stops lookups read before the change
+// from caching over it.
+func (u *ListUseCase) refreshCache(ctx context.Context, entry *entity.ListEntry) {
+	key := listCacheKey(entry.List, repository.ListKey{EntryType: entry.EntryType, Value: entry.Value})
+
+	pipe := u.RedisClient.TxPipeline()
+	pipe.Incr(ctx, listVersionKey)
+	if entry.RemovedAt != nil {
+		pipe.Del(ctx, key)
+	} else {
+		ttl := u.CacheTTL
+		if entry.ExpiresAt != nil {
+			ttl = min(ttl, untilExpiry(*entry.ExpiresAt, u.DB.NowFunc()))
+		}
+		pipe.Set(ctx, key, entry.EntryID, ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to refresh list cache for entry %d: %+v", entry.EntryID, err)
+	}
+}
+
+// listEntryFields describes an entry for the logs; account numbers go under account_id, which
+// the logger masks
+func listEntryFields(entry *entity.ListEntry) logrus.Fields {
+	fields := logrus.Fields{
+		"entry_id":   entry.EntryID,
+		"list":       entry.List,
+		"entry_type": entry.EntryType,
+	}
+	if entry.EntryType == entity.ListEntryAccountID {
+		fields["account_id"] = entry.Value
+	} else {
+		fields["value"] = entry.Value
+	}
+	return fields
+}
+
+func listCacheKey(list string, key repository.ListKey) string {
+	return fmt.Sprintf("lists:%s:%s:%s", list, key.EntryType, key.Value)
+}
+
+func toListEntryResponse(entry *entity.ListEntry) *model.ListEntryResponse {
+	response := &model.ListEntryResponse{
+		EntryID:   entry.EntryID,
+		List:      entry.List,
+		EntryType: entry.EntryType,
+		Value:     entry.Value,
+		Reason:    entry.Reason,
+		CreatedBy: entry.CreatedBy,
+		CreatedAt: entry.CreatedAt.Format(time.RFC3339),
+	}
+	if entry.ExpiresAt != nil {
+		response.ExpiresAt = entry.ExpiresAt.Format(time.RFC3339)
+	}
+	if entry.UpdatedBy != nil {
+		response.UpdatedBy = *entry.UpdatedBy
+	}
+	if entry.UpdatedAt != nil {
+		response.UpdatedAt = entry.UpdatedAt.Format(time.RFC3339)
+	}
+	if entry.RemovedBy != nil {
+		response.RemovedBy = *entry.RemovedBy
+	}
+	if entry.RemovedAt != nil {
+		response.RemovedAt = entry.RemovedAt.Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/repository"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// TestCacheLookupsAfterChange replays a lookup racing with a new entry: the lookup reads the
+// version, misses in the database, and only caches its miss after the entry is written through
+func TestCacheLookupsAfterChange(t *testing.T) {
+	server := miniredis.RunT(t)
+	u := &ListUseCase{
+		Log:         logrus.New(),
+		RedisClient: redis.NewClient(&redis.Options{Addr: server.Addr()}),
+		CacheTTL:    5 * time.Minute,
+	}
+	ctx := context.Background()
+	key := repository.ListKey{EntryType: entity.ListEntryMerchantID, Value: "M001"}
+	cacheKey := listCacheKey(entity.ListBlacklist, key)
+
+	tests := []struct {
+		name   string
+		change bool
+		want   string
+	}{
+		{name: "no change", change: false, want: "0"},
+		{name: "entry added meanwhile", change: true, want: "42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.FlushAll()
+			version, _ := server.Get(listVersionKey)
+
+			if tt.change {
+				u.refreshCache(ctx, &entity.ListEntry{EntryID: 42, List: entity.ListBlacklist, EntryType: key.EntryType, Value: key.Value})
+			}
+
+			err := cacheLookupsScript.Run(ctx, u.RedisClient, []string{listVersionKey, cacheKey}, version, 0, u.CacheTTL.Milliseconds()).Err()
+			if err != nil {
+				t.Fatalf("cache lookups: %v", err)
+			}
+
+			got, err := server.Get(cacheKey)
+			if err != nil {
+				t.Fatalf("lookup not cached: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("cached %q, want %q", got, tt.want)
+			}
+			if ttl := server.TTL(cacheKey); ttl <= 0 || ttl > u.CacheTTL {
+				t.Fatalf("cached for %v, want at most %v", ttl, u.CacheTTL)
+			}
+		})
+	}
+}
+
+// TestListEntryExpiry stores expiries sent with any offset as database wall clock time and
+// reads the time left the same way whether the expiry was just parsed or read back
+func TestListEntryExpiry(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	u := &ListUseCase{Log: logrus.New(), Location: jakarta}
+
+	in := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "utc", value: in.UTC().Format(time.RFC3339)},
+		{name: "jakarta", value: in.In(jakarta).Format(time.RFC3339)},
+		{name: "new york", value: in.In(time.FixedZone("EST", -5*60*60)).Format(time.RFC3339)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expiresAt, err := u.expiry(context.Background(), tt.value)
+			if err != nil {
+				t.Fatalf("expiry(%s): %v", tt.value, err)
+			}
+			if want := in.In(jakarta).Format(time.DateTime); expiresAt.Format(time.DateTime) != want {
+				t.Fatalf("stored wall clock %s, want %s", expiresAt.Format(time.DateTime), want)
+			}
+
+			now := time.Now().In(jakarta)
+			// The driver returns the stored wall clock labelled UTC
+			readBack := time.Date(expiresAt.Year(), expiresAt.Month(), expiresAt.Day(), expiresAt.Hour(), expiresAt.Minute(), expiresAt.Second(), 0, time.UTC)
+			for _, at := range []time.Time{*expiresAt, readBack} {
+				if left := untilExpiry(at, now); left < 47*time.Hour || left > 48*time.Hour {
+					t.Fatalf("untilExpiry(%s) = %v, want about 48h", at, left)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/gateway/switching"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/qris"
+	"golang-clean-architecture/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// OffUsConfig routes QRs acquired by other institutions through the interbank switch
+type OffUsConfig struct {
+	// Client is nil when off-us routing is disabled and every QR is treated as on-us
+	Client switching.Client
+	// OnUsAcquirers are our own merchant account GUIDs, e.g. ID.CO.QRISPAY.WWW
+	OnUsAcquirers []string
+	// ReversalRetries is how many times a reversal is resent before the sweeper takes over
+	ReversalRetries int
+}
+
+func (u *QrisUseCase) isOffUs(acquirer *qris.MerchantAccount) bool {
+	return u.OffUs.Client != nil && acquirer != nil && !slices.Contains(u.OffUs.OnUsAcquirers, acquirer.GUID)
+}
+
+// inquiryOffUs asks the acquirer to confirm the merchant and stores the inquiry session with
+// everything Payment needs to forward the payment
+func (u *QrisUseCase) inquiryOffUs(ctx context.Context, qrisPayload string, payload *qris.Payload, acquirer *qris.MerchantAccount, quote *model.FxQuote) (*model.InquiryResponse, error) {
+	inquiryID := fmt.Sprintf("inq_%s", uuid.New().String()[:6])
+
+	response, err := u.OffUs.Client.Inquiry(ctx, &model.SwitchInquiryRequest{
+		Reference:   inquiryID,
+		AcquirerID:  acquirer.GUID,
+		MerchantPAN: acquirer.PAN,
+		MerchantID:  acquirer.MerchantID,
+		TerminalID:  payload.TerminalLabel,
+		Amount:      payload.Amount,
+		Currency:    payload.Currency,
+		QrisPayload: qrisPayload,
+	})
+	if errors.Is(err, switching.ErrTimeout) {
+		u.Log.WithContext(ctx).Warnf("Switch inquiry timed out for acquirer: %s", acquirer.GUID)
+		return nil, model.NewError(model.ErrCodeSwitchTimeout)
+	}
+	if err != nil {
+		u.Log.WithContext(ctx).Warnf("Switch inquiry failed for acquirer: %s, error: %+v", acquirer.GUID, err)
+		return nil, model.NewError(model.ErrCodeSwitchUnavailable)
+	}
+	if response.ResponseCode != model.SwitchResponseApproved {
+		u.Log.WithContext(ctx).Warnf("Switch inquiry rejected by acquirer: %s, response code: %s", acquirer.GUID, response.ResponseCode)
+		return nil, model.NewError(model.ErrCodeMerchantNotFound)
+	}
+
+	if err := u.checkBlacklist(ctx, repository.ListKey{EntryType: entity.ListEntryMerchantID, Value: response.MerchantID}); err != nil {
+		return nil, err
+	}
+
+	// Store inquiry session in Redis (valid for 5 minutes, one-time use)
+	inquiryData, _ := json.Marshal(map[string]interface{}{
+		"merchant_id":   response.MerchantID,
+		"merchant_name": response.MerchantName,
+		"terminal_id":   response.TerminalID,
+		"qris_payload":  qrisPayload,
+		"acquirer_id":   acquirer.GUID,
+		"merchant_pan":  acquirer.PAN,
+		"currency":      payload.Currency,
+		"fx_quote":      quote,
+	})
+	u.RedisClient.Set(ctx, fmt.Sprintf("inquiry:%s", inquiryID), inquiryData, 5*time.Minute)
+
+	return &model.InquiryResponse{
+		MerchantID:   response.MerchantID,
+		MerchantName: response.MerchantName,
+		TerminalID:   response.TerminalID,
+		City:         response.City,
+		FixedAmount:  payload.Amount,
+		InquiryID:    inquiryID,
+		AcquirerID:   acquirer.GUID,
+		FxQuote:      quote,
+	}, nil
+}
+
+// payOffUs debits the customer, then forwards the payment to the switch. A declined payment
+// is refunded at once. When the switch times out or fails the outcome is unknown, so the payment
+// is reversed and only refunded once the switch acknowledges the reversal. An approval that
+// cannot be marked SUCCESS is flagged SWITCH_APPROVED, which is never refunded automatically.
+func (u *QrisUseCase) payOffUs(ctx context.Context, request *model.PaymentRequest, verified bool, transaction *entity.Transaction, inquiry map[string]interface{}, quote *model.FxQuote) error {
+	if err := u.holdFunds(ctx, request, verified, transaction, quote); err != nil {
+		return err
+	}
+
+	merchantPAN, _ := inquiry["merchant_pan"].(string)
+	currency, _ := inquiry["currency"].(string)
+
+	response, err := u.OffUs.Client.Payment(ctx, &model.SwitchPaymentRequest{
+		Reference:   transaction.TransactionID,
+		AcquirerID:  transaction.AcquirerID,
+		MerchantPAN: merchantPAN,
+		MerchantID:  transaction.MerchantID,
+		TerminalID:  transaction.TerminalID,
+		Amount:      merchantAmount(transaction),
+		Currency:    currency,
+	})
+	if err != nil {
+		u.Log.WithContext(ctx).Warnf("Switch payment failed for transaction: %s, error: %+v", transaction.TransactionID, err)
+		u.reverse(ctx, transaction)
+		if errors.Is(err, switching.ErrTimeout) {
+			return model.NewError(model.ErrCodeSwitchTimeout)
+		}
+		return model.NewError(model.ErrCodeSwitchUnavailable)
+	}
+
+	if response.ResponseCode != model.SwitchResponseApproved {
+		u.Log.WithContext(ctx).Warnf("Switch declined transaction: %s, response code: %s", transaction.TransactionID, response.ResponseCode)
+		if err := u.refund(ctx, transaction.TransactionID); err != nil {
+			u.Log.WithContext(ctx).Errorf("Failed to refund declined transaction: %s, error: %+v", transaction.TransactionID, err)
+		}
+		return model.NewError(model.ErrCodeSwitchDeclined)
+	}
+
+	// The acquirer has the money: the approval is recorded even when the caller has gone away
+	recordCtx := context.WithoutCancel(ctx)
+	err = u.TransactionRepository.MarkSwitched(u.DB.WithContext(recordCtx), transaction.TransactionID, entity.TransactionStatusSuccess, response.SwitchReference)
+	if err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to record approved off-us transaction: %s, error: %+v", transaction.TransactionID, err)
+		// Left PENDING, the sweeper would reverse a payment the merchant was told succeeded
+		err = u.TransactionRepository.MarkSwitched(u.DB.WithContext(recordCtx), transaction.TransactionID, entity.TransactionStatusSwitchApproved, response.SwitchReference)
+		if err != nil {
+			u.Log.WithContext(ctx).Errorf("Failed to flag approved off-us transaction: %s, error: %+v", transaction.TransactionID, err)
+		}
+		return model.NewError(model.ErrCodeInternal)
+	}
+
+	return nil
+}
+
+// holdFunds verifies the account and debits it, leaving the transaction PENDING until the
+// switch answers
+func (u *QrisUseCase) holdFunds(ctx context.Context, request *model.PaymentRequest, verified bool, transaction *entity.Transaction, quote *model.FxQuote) error {
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	account := new(entity.Account)
+	if err := u.findAccount(tx, account, request.UserID); err != nil {
+		u.Log.WithContext(ctx).Warnf("Account not found for user: %s, error: %+v", request.UserID, err)
+		return model.NewError(model.ErrCodeAccountNotFound)
+	}
+
+	if err := u.convert(ctx, account, transaction, quote); err != nil {
+		return err
+	}
+
+	if err := u.verifyAccount(ctx, account, request, verified, transaction.Amount); err != nil {
+		return err
+	}
+
+	if err := u.screen(ctx, account, request, verified, transaction); err != nil {
+		return err
+	}
+
+	if err := u.reserveLimits(ctx, account, transaction); err != nil {
+		return err
+	}
+
+	if err := u.TransactionRepository.Create(tx, transaction); err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to create transaction: %+v", err)
+		return model.NewError(model.ErrCodeInternal)
+	}
+
+	if err := u.deductBalance(ctx, tx, account, transaction.Amount); err != nil {
+		return err
+	}
+
+	if err := u.TransactionRepository.MarkDebited(tx, transaction.TransactionID, entity.TransactionStatusPending); err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to update transaction status: %+v", err)
+		return model.NewError(model.ErrCodeInternal)
+	}
+
+	if err := u.auditTransaction(ctx, tx, entity.AuditPaymentCreated, transaction.TransactionID); err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to audit transaction: %+v", err)
+		return model.NewError(model.ErrCodeInternal)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to commit transaction: %+v", err)
+		return model.NewError(model.ErrCodeInternal)
+	}
+
+	return nil
+}
+
+// reverse cancels a payment whose outcome is unknown. If the switch never acknowledges the
+// reversal the transaction stays PENDING and the sweeper resends the reversal once it is stale.
+func (u *QrisUseCase) reverse(ctx context.Context, transaction *entity.Transaction) {
+	// The caller's deadline may be what expired; the reversal must still go out
+	ctx = context.WithoutCancel(ctx)
+
+	request := &model.SwitchReversalRequest{
+		Reference:  transaction.TransactionID,
+		AcquirerID: transaction.AcquirerID,
+		Amount:     merchantAmount(transaction),
+	}
+	for attempt := 0; attempt <= u.OffUs.ReversalRetries; attempt++ {
+		response, err := u.OffUs.Client.Reversal(ctx, request)
+		if err == nil && response.ResponseCode == model.SwitchResponseApproved {
+			if err := u.refund(ctx, transaction.TransactionID); err != nil {
+				u.Log.WithContext(ctx).Errorf("Failed to refund reversed transaction: %s, error: %+v", transaction.TransactionID, err)
+			}
+			u.Log.WithContext(ctx).Infof("Reversed off-us transaction: %s", transaction.TransactionID)
+			return
+		}
+		u.Log.WithContext(ctx).Warnf("Reversal attempt %d failed for transaction: %s, error: %+v", attempt+1, transaction.TransactionID, err)
+	}
+
+	u.Log.WithContext(ctx).Errorf("Reversal not acknowledged for transaction: %s, leaving it pending", transaction.TransactionID)
+}
+
+// refund credits back a debited PENDING transaction and marks it FAILED
+func (u *QrisUseCase) refund(ctx context.Context, transactionID string) error {
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	transaction := new(entity.Transaction)
+	if err := u.TransactionRepository.LockByTransactionID(tx, transaction, transactionID); err != nil {
+		return err
+	}
+	if transaction.Status != entity.TransactionStatusPending || transaction.DebitedAt == nil {
+		return nil
+	}
+
+	before := toTransactionResponse(transaction)
+	if err := u.AccountRepository.CreditBalance(tx, transaction.AccountID, transaction.Amount); err != nil {
+		return err
+	}
+	if err := u.TransactionRepository.UpdateStatus(tx, transactionID, entity.TransactionStatusFailed); err != nil {
+		return err
+	}
+
+	transaction.Status = entity.TransactionStatusFailed
+	event := u.Audit.Event(ctx, entity.AuditPaymentReversed, entity.AuditTargetTransaction, transactionID, before, toTransactionResponse(transaction))
+	if err := u.Audit.Append(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"golang-clean-architecture/internal/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PinLockoutConfig locks an account's PIN after MaxAttempts wrong PINs, until Duration has passed
+// without another wrong PIN. Lockout is off when MaxAttempts is zero.
+type PinLockoutConfig struct {
+	MaxAttempts int
+	Duration    time.Duration
+}
+
+func pinFailuresKey(accountID string) string {
+	return "pin_failures:" + accountID
+}
+
+// pinAttemptScript counts an attempt before the PIN is checked, so concurrent attempts cannot
+// all pass the limit check. The expiry is only pushed back by attempts within the limit: the
+// lockout runs from the wrong PIN that set it, not from the attempts refused while locked.
+var pinAttemptScript = redis.NewScript(`
+local attempts = redis.call('INCR', KEYS[1])
+if attempts <= tonumber(ARGV[1]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return attempts
+`)
+
+// checkPin verifies the PIN of an account that is not locked, counting wrong PINs toward the
+// lockout. Every attempt is counted before the PIN is checked and a correct PIN clears the
+// count. The wrong PIN that locks the account is still reported as INVALID_PIN, the attempts
+// after it as PIN_LOCKED without the PIN being checked.
+func (u *QrisUseCase) checkPin(ctx context.Context, accountID string, verify func() error) error {
+	if u.PinLockout.MaxAttempts == 0 {
+		if err := verify(); err != nil {
+			return model.NewError(model.ErrCodeInvalidPIN)
+		}
+		return nil
+	}
+
+	key := pinFailuresKey(accountID)
+	attempts, err := pinAttemptScript.Run(ctx, u.RedisClient, []string{key}, u.PinLockout.MaxAttempts, u.PinLockout.Duration.Milliseconds()).Int()
+	if err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to count pin attempt of user: %s, error: %+v", accountID, err)
+		return model.NewError(model.ErrCodeInternal)
+	}
+	if attempts > u.PinLockout.MaxAttempts {
+		u.Log.WithContext(ctx).Warnf("PIN locked for user: %s", accountID)
+		return model.NewError(model.ErrCodePINLocked)
+	}
+
+	if err := verify(); err != nil {
+		if attempts == u.PinLockout.MaxAttempts {
+			u.Log.WithContext(ctx).Warnf("Locked PIN of user: %s after %d wrong PINs", accountID, attempts)
+		}
+		return model.NewError(model.ErrCodeInvalidPIN)
+	}
+
+	// A correct PIN starts the count over
+	if err := u.RedisClient.Del(ctx, key).Err(); err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to reset pin failures of user: %s, error: %+v", accountID, err)
+	}
+	return nil
+}
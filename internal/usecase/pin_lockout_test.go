@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-clean-architecture/internal/model"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+func newPinLockoutUseCase(t *testing.T, maxAttempts int) (*QrisUseCase, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	log := logrus.New()
+	log.SetOutput(testWriter{t})
+
+	return &QrisUseCase{
+		Log:         log,
+		RedisClient: redis.NewClient(&redis.Options{Addr: server.Addr()}),
+		PinLockout:  PinLockoutConfig{MaxAttempts: maxAttempts, Duration: 15 * time.Minute},
+	}, server
+}
+
+func errorCode(err error) model.ErrorCode {
+	var appErr *model.Error
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return ""
+}
+
+// TestCheckPinConcurrentWrongPins guesses in parallel: only MaxAttempts guesses reach the PIN
+// check, however many are in flight at once
+func TestCheckPinConcurrentWrongPins(t *testing.T) {
+	u, server := newPinLockoutUseCase(t, 3)
+
+	var checked atomic.Int64
+	wrongPin := func() error {
+		checked.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return errors.New("wrong pin")
+	}
+
+	const guesses = 20
+	codes := make(chan model.ErrorCode, guesses)
+	var wg sync.WaitGroup
+	for range guesses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- errorCode(u.checkPin(context.Background(), "user_123", wrongPin))
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	counts := map[model.ErrorCode]int{}
+	for code := range codes {
+		counts[code]++
+	}
+	if counts[model.ErrCodeInvalidPIN] != 3 || counts[model.ErrCodePINLocked] != guesses-3 {
+		t.Fatalf("got %v, want 3 %s and %d %s", counts, model.ErrCodeInvalidPIN, guesses-3, model.ErrCodePINLocked)
+	}
+	if checked.Load() != 3 {
+		t.Fatalf("checked %d PINs, want 3", checked.Load())
+	}
+
+	// Attempts refused while locked do not push the lockout back
+	if ttl := server.TTL(pinFailuresKey("user_123")); ttl <= 0 || ttl > 15*time.Minute {
+		t.Fatalf("lockout ttl %v", ttl)
+	}
+	server.FastForward(15 * time.Minute)
+	if err := u.checkPin(context.Background(), "user_123", func() error { return nil }); err != nil {
+		t.Fatalf("checkPin after the lockout: %v", err)
+	}
+}
+
+func TestCheckPin(t *testing.T) {
+	wrong := func() error { return errors.New("wrong pin") }
+	right := func() error { return nil }
+
+	tests := []struct {
+		name     string
+		attempts []func() error
+		want     model.ErrorCode
+		failures string
+	}{
+		{name: "correct pin", attempts: []func() error{right}, want: ""},
+		{name: "wrong pin", attempts: []func() error{wrong}, want: model.ErrCodeInvalidPIN, failures: "1"},
+		{name: "locking pin", attempts: []func() error{wrong, wrong, wrong}, want: model.ErrCodeInvalidPIN, failures: "3"},
+		{name: "locked", attempts: []func() error{wrong, wrong, wrong, right}, want: model.ErrCodePINLocked, failures: "4"},
+		{name: "correct pin starts over", attempts: []func() error{wrong, wrong, right, wrong, wrong}, want: model.ErrCodeInvalidPIN, failures: "2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, server := newPinLockoutUseCase(t, 3)
+
+			var err error
+			for _, attempt := range tt.attempts {
+				err = u.checkPin(context.Background(), "user_123", attempt)
+			}
+			if code := errorCode(err); code != tt.want {
+				t.Fatalf("last attempt got %q, want %q", code, tt.want)
+			}
+
+			failures, _ := server.Get(pinFailuresKey("user_123"))
+			if failures != tt.failures {
+				t.Fatalf("failure count %q, want %q", failures, tt.failures)
+			}
+		})
+	}
+}
@@ -3,22 +3,41 @@ package usecase
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand/v2"
 	"time"
 
 	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/metrics"
 	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/qris"
 	"golang-clean-architecture/internal/repository"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+const (
+	// LockStrategyOptimistic deducts with a version check and retries on conflict
+	LockStrategyOptimistic = "optimistic"
+	// LockStrategyPessimistic locks the account row with SELECT ... FOR UPDATE before deducting
+	LockStrategyPessimistic = "pessimistic"
+)
+
+// PaymentLockConfig controls how concurrent debits on the same account are serialized
+type PaymentLockConfig struct {
+	Strategy     string
+	MaxRetries   int
+	RetryBackoff time.Duration
+}
+
 type QrisUseCase struct {
 	DB                    *gorm.DB
 	Log                   *logrus.Logger
@@ -27,6 +46,19 @@ type QrisUseCase struct {
 	MerchantRepository    *repository.MerchantRepository
 	AccountRepository     *repository.AccountRepository
 	TransactionRepository *repository.TransactionRepository
+	LockConfig            PaymentLockConfig
+	MDRPercent            float64
+	HotAccountBatcher     *HotAccountBatcher
+	OffUs                 OffUsConfig
+	Fx                    FxConfig
+	Limits                *LimitUseCase
+	Risk                  RiskConfig
+	StepUp                StepUpConfig
+	Devices               *DeviceUseCase
+	PinLockout            PinLockoutConfig
+	Lists                 *ListUseCase
+	Audit                 *AuditUseCase
+	Metrics               *metrics.Metrics
 }
 
 func NewQrisUseCase(
@@ -37,6 +69,19 @@ func NewQrisUseCase(
 	merchantRepo *repository.MerchantRepository,
 	accountRepo *repository.AccountRepository,
 	transactionRepo *repository.TransactionRepository,
+	lockConfig PaymentLockConfig,
+	mdrPercent float64,
+	hotAccountBatcher *HotAccountBatcher,
+	offUs OffUsConfig,
+	fxConfig FxConfig,
+	limits *LimitUseCase,
+	riskConfig RiskConfig,
+	stepUp StepUpConfig,
+	devices *DeviceUseCase,
+	pinLockout PinLockoutConfig,
+	lists *ListUseCase,
+	audit *AuditUseCase,
+	metrics *metrics.Metrics,
 ) *QrisUseCase {
 	return &QrisUseCase{
 		DB:                    db,
@@ -46,14 +91,66 @@ func NewQrisUseCase(
 		MerchantRepository:    merchantRepo,
 		AccountRepository:     accountRepo,
 		TransactionRepository: transactionRepo,
+		LockConfig:            lockConfig,
+		MDRPercent:            mdrPercent,
+		HotAccountBatcher:     hotAccountBatcher,
+		OffUs:                 offUs,
+		Fx:                    fxConfig,
+		Limits:                limits,
+		Risk:                  riskConfig,
+		StepUp:                stepUp,
+		Devices:               devices,
+		PinLockout:            pinLockout,
+		Lists:                 lists,
+		Audit:                 audit,
+		Metrics:               metrics,
 	}
 }
 
-// Inquiry processes a QRIS payload and returns merchant information
-func (u *QrisUseCase) Inquiry(ctx context.Context, qrisPayload string) (*model.InquiryResponse, *model.Metadata, error) {
+// Inquiry processes a QRIS payload and returns merchant information. QRs priced in another
+// currency than quoteCurrency (the home currency when empty) carry a locked FX quote.
+func (u *QrisUseCase) Inquiry(ctx context.Context, qrisPayload string, quoteCurrency string) (*model.InquiryResponse, *model.Metadata, error) {
+	ctx, span := tracer.Start(ctx, "QrisUseCase.Inquiry")
+	defer span.End()
+
 	start := time.Now()
 	source := "database"
 
+	payload, err := qris.Parse(qrisPayload)
+	if errors.Is(err, qris.ErrInvalidCRC) {
+		u.Log.WithContext(ctx).Warnf("Invalid QRIS CRC: %+v", err)
+		return nil, nil, model.NewError(model.ErrCodeQrisInvalidCRC)
+	}
+	if err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid QRIS payload: %+v", err)
+		return nil, nil, model.NewError(model.ErrCodeQrisInvalidFormat)
+	}
+
+	// Blacklisted QRs are refused before any acquirer is asked
+	if err := u.checkBlacklist(ctx, qrisKeys(qrisPayload, payload)...); err != nil {
+		return nil, nil, err
+	}
+
+	quote, err := u.quote(ctx, payload, quoteCurrency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// QRs acquired by other institutions are resolved by their acquirer through the switch
+	if acquirer := payload.Acquirer(); u.isOffUs(acquirer) {
+		response, err := u.inquiryOffUs(ctx, qrisPayload, payload, acquirer, quote)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		span.SetAttributes(attribute.String("qris.inquiry.source", "switch"))
+		u.Metrics.ObserveInquiry("switch")
+		return response, &model.Metadata{
+			LatencyMs: time.Since(start).Milliseconds(),
+			Source:    "switch",
+		}, nil
+	}
+
 	var merchantID, merchantName, city string
 
 	// Try to find merchant data from Redis cache first
@@ -68,7 +165,7 @@ func (u *QrisUseCase) Inquiry(ctx context.Context, qrisPayload string) (*model.I
 			merchantName = merchantCache["merchant_name"]
 			city = merchantCache["city"]
 			source = "cache"
-			u.Log.Infof("Cache hit for QRIS merchant data")
+			u.Log.WithContext(ctx).Infof("Cache hit for QRIS merchant data")
 		}
 	}
 
@@ -77,8 +174,8 @@ func (u *QrisUseCase) Inquiry(ctx context.Context, qrisPayload string) (*model.I
 		tx := u.DB.WithContext(ctx)
 		merchants, err := u.MerchantRepository.FindAll(tx)
 		if err != nil || len(merchants) == 0 {
-			u.Log.Warnf("No active merchants found: %+v", err)
-			return nil, nil, fiber.NewError(fiber.StatusNotFound, "Merchant not found")
+			u.Log.WithContext(ctx).Warnf("No active merchants found: %+v", err)
+			return nil, nil, model.NewError(model.ErrCodeMerchantNotFound)
 		}
 
 		merchant := merchants[0]
@@ -95,26 +192,40 @@ func (u *QrisUseCase) Inquiry(ctx context.Context, qrisPayload string) (*model.I
 		u.RedisClient.Set(ctx, cacheKey, merchantCache, 5*time.Minute)
 	}
 
+	if err := u.checkBlacklist(ctx, repository.ListKey{EntryType: entity.ListEntryMerchantID, Value: merchantID}); err != nil {
+		return nil, nil, err
+	}
+
 	// Always generate a FRESH inquiry_id (never cached)
 	inquiryID := fmt.Sprintf("inq_%s", uuid.New().String()[:6])
+	terminalID := "T001"
+	if payload.TerminalLabel != "" {
+		terminalID = payload.TerminalLabel
+	}
 
 	// Store inquiry session in Redis (valid for 5 minutes, one-time use)
 	inquiryData, _ := json.Marshal(map[string]interface{}{
 		"merchant_id":   merchantID,
 		"merchant_name": merchantName,
+		"terminal_id":   terminalID,
 		"qris_payload":  qrisPayload,
+		"fx_quote":      quote,
 	})
 	u.RedisClient.Set(ctx, fmt.Sprintf("inquiry:%s", inquiryID), inquiryData, 5*time.Minute)
 
 	response := &model.InquiryResponse{
 		MerchantID:   merchantID,
 		MerchantName: merchantName,
-		TerminalID:   "T001",
+		TerminalID:   terminalID,
 		City:         city,
-		FixedAmount:  0,
+		FixedAmount:  payload.Amount,
 		InquiryID:    inquiryID,
+		FxQuote:      quote,
 	}
 
+	span.SetAttributes(attribute.String("qris.inquiry.source", source))
+	u.Metrics.ObserveInquiry(source)
+
 	latency := time.Since(start).Milliseconds()
 	return response, &model.Metadata{
 		LatencyMs: latency,
@@ -122,95 +233,324 @@ func (u *QrisUseCase) Inquiry(ctx context.Context, qrisPayload string) (*model.I
 	}, nil
 }
 
-// Payment processes a QRIS payment
-func (u *QrisUseCase) Payment(ctx context.Context, request *model.PaymentRequest) (*model.PaymentResponse, error) {
+// Payment processes a QRIS payment. Payments needing step-up authentication return a
+// challenge_required response instead and are completed by VerifyChallenge.
+func (u *QrisUseCase) Payment(ctx context.Context, request *model.PaymentRequest) (response *model.PaymentResponse, err error) {
+	ctx, span := tracer.Start(ctx, "QrisUseCase.Payment")
+	defer span.End()
+	defer func() { u.Metrics.ObservePayment(response, err) }()
+
 	// Validate request
 	if err := u.Validate.Struct(request); err != nil {
-		u.Log.Warnf("Invalid payment request: %+v", err)
-		return nil, fiber.ErrBadRequest
+		u.Log.WithContext(ctx).Warnf("Invalid payment request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	// Only a device bound to the account can pay from it, whichever API client relays the request
+	if u.Devices != nil {
+		if err := u.Devices.Verify(ctx, request); err != nil {
+			return nil, err
+		}
 	}
 
+	return u.pay(ctx, request, false)
+}
+
+// pay debits the account for a validated request against its inquiry. verified is only set by
+// VerifyChallenge: the PIN was checked when the challenge was issued and the step-up is done.
+func (u *QrisUseCase) pay(ctx context.Context, request *model.PaymentRequest, verified bool) (*model.PaymentResponse, error) {
 	// Validate inquiry_id from Redis
 	inquiryKey := fmt.Sprintf("inquiry:%s", request.InquiryID)
 	inquiryData, err := u.RedisClient.Get(ctx, inquiryKey).Result()
 	if err != nil {
-		u.Log.Warnf("Invalid or expired inquiry_id: %s", request.InquiryID)
-		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid or expired inquiry ID")
+		u.Log.WithContext(ctx).Warnf("Invalid or expired inquiry_id: %s", request.InquiryID)
+		return nil, model.NewError(model.ErrCodeInquiryExpired)
 	}
 
 	// Parse inquiry data to get merchant_id
 	var inquiry map[string]interface{}
 	if err := json.Unmarshal([]byte(inquiryData), &inquiry); err != nil {
-		u.Log.Warnf("Failed to parse inquiry data: %+v", err)
-		return nil, fiber.ErrInternalServerError
+		u.Log.WithContext(ctx).Warnf("Failed to parse inquiry data: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
 	}
 
-	merchantID, _ := inquiry["merchant_id"].(string)
-
-	// Start transaction
-	tx := u.DB.WithContext(ctx).Begin()
-	defer tx.Rollback()
-
-	// Find account by user_id
-	account := new(entity.Account)
-	if err := u.AccountRepository.FindByAccountID(tx, account, request.UserID); err != nil {
-		u.Log.Warnf("Account not found for user: %s, error: %+v", request.UserID, err)
-		return nil, fiber.NewError(fiber.StatusNotFound, "Account not found")
+	// The locked quote is read back typed rather than through the generic map
+	var session struct {
+		FxQuote *model.FxQuote `json:"fx_quote"`
+	}
+	if err := json.Unmarshal([]byte(inquiryData), &session); err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to parse inquiry quote: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
 	}
 
-	// Verify PIN
-	if err := bcrypt.CompareHashAndPassword([]byte(account.PinHash), []byte(request.Pincode)); err != nil {
-		u.Log.Warnf("Invalid PIN for user: %s", request.UserID)
-		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid PIN")
+	merchantID, _ := inquiry["merchant_id"].(string)
+	terminalID, _ := inquiry["terminal_id"].(string)
+	acquirerID, _ := inquiry["acquirer_id"].(string)
+
+	// Lists may have changed since the inquiry; entries take effect at once
+	if err := u.checkBlacklist(ctx, paymentKeys(request, inquiry)...); err != nil {
+		return nil, err
 	}
 
-	// Check sufficient balance
-	if account.Balance < request.Amount {
-		u.Log.Warnf("Insufficient balance for user: %s", request.UserID)
-		return nil, fiber.NewError(fiber.StatusBadRequest, "Insufficient balance")
+	// The MDR of off-us payments is charged by the merchant's acquirer, not by us
+	fee := merchantFee(request.Amount, u.MDRPercent)
+	if acquirerID != "" {
+		fee = 0
 	}
 
-	// Create transaction record
-	traceID := uuid.New().String()
+	// Build transaction record
 	transactionID := uuid.New().String()
 	transaction := &entity.Transaction{
 		TransactionID: transactionID,
-		TraceID:       traceID,
+		TraceID:       traceID(ctx),
 		AccountID:     request.UserID,
 		MerchantID:    merchantID,
+		TerminalID:    terminalID,
+		AcquirerID:    acquirerID,
+		DeviceID:      request.DeviceID,
 		Amount:        request.Amount,
-		Status:        "PENDING",
+		Fee:           fee,
+		Status:        entity.TransactionStatusPending,
 	}
 
-	if err := u.TransactionRepository.Create(tx, transaction); err != nil {
-		u.Log.Warnf("Failed to create transaction: %+v", err)
-		return nil, fiber.ErrInternalServerError
-	}
-
-	// Deduct balance with optimistic locking
-	if err := u.AccountRepository.DeductBalance(tx, request.UserID, request.Amount, account.Version); err != nil {
-		u.Log.Warnf("Failed to deduct balance (optimistic lock conflict): %+v", err)
-		return nil, fiber.NewError(fiber.StatusConflict, "Transaction conflict, please retry")
+	// Off-us payments are debited here and forwarded to the acquirer through the switch;
+	// hot accounts are debited in micro-batches instead of one row update per payment
+	if acquirerID != "" {
+		err = u.payOffUs(ctx, request, verified, transaction, inquiry, session.FxQuote)
+	} else if u.HotAccountBatcher != nil && u.HotAccountBatcher.IsHot(request.UserID) {
+		err = u.payFromHotAccount(ctx, request, verified, transaction, session.FxQuote)
+	} else {
+		err = u.payFromAccount(ctx, request, verified, transaction, session.FxQuote)
 	}
-
-	// Update transaction status to SUCCESS
-	if err := u.TransactionRepository.UpdateStatus(tx, transactionID, "SUCCESS"); err != nil {
-		u.Log.Warnf("Failed to update transaction status: %+v", err)
-		return nil, fiber.ErrInternalServerError
+	var challenge *challengeRequired
+	if errors.As(err, &challenge) {
+		return u.issueChallenge(ctx, request, challenge.method)
 	}
-
-	if err := tx.Commit().Error; err != nil {
-		u.Log.Warnf("Failed to commit transaction: %+v", err)
-		return nil, fiber.ErrInternalServerError
+	if err != nil {
+		if u.Limits != nil {
+			u.Limits.Release(ctx, transaction)
+		}
+		u.recordRejected(ctx, transaction, err)
+		u.auditPinFailure(ctx, request, err)
+		return nil, err
 	}
 
 	// Delete the inquiry from Redis (one-time use)
 	u.RedisClient.Del(ctx, inquiryKey)
 
 	return &model.PaymentResponse{
-		Status:              "processing",
+		Status:              model.PaymentStatusProcessing,
 		TransactionID:       transactionID,
 		Message:             "Transaksi sedang diproses",
 		EstimatedCompletion: "200ms",
 	}, nil
 }
+
+// payFromAccount verifies the account and debits it in its own database transaction
+func (u *QrisUseCase) payFromAccount(ctx context.Context, request *model.PaymentRequest, verified bool, transaction *entity.Transaction, quote *model.FxQuote) error {
+	// Start transaction
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	// Find account by user_id, holding a row lock under the pessimistic strategy
+	account := new(entity.Account)
+	if err := u.findAccount(tx, account, request.UserID); err != nil {
+		u.Log.WithContext(ctx).Warnf("Account not found for user: %s, error: %+v", request.UserID, err)
+		return model.NewError(model.ErrCodeAccountNotFound)
+	}
+
+	if err := u.convert(ctx, account, transaction, quote); err != nil {
+		return err
+	}
+
+	if err := u.verifyAccount(ctx, account, request, verified, transaction.Amount); err != nil {
+		return err
+	}
+
+	if err := u.screen(ctx, account, request, verified, transaction); err != nil {
+		return err
+	}
+
+	if err := u.reserveLimits(ctx, account, transaction); err != nil {
+		return err
+	}
+
+	if err := u.TransactionRepository.Create(tx, transaction); err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to create transaction: %+v", err)
+		return model.NewError(model.ErrCodeInternal)
+	}
+
+	// Deduct balance, retrying optimistic lock conflicts
+	if err := u.deductBalance(ctx, tx, account, transaction.Amount); err != nil {
+		return err
+	}
+
+	// Update transaction status to SUCCESS and record the debit
+	if err := u.TransactionRepository.MarkDebited(tx, transaction.TransactionID, entity.TransactionStatusSuccess); err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to update transaction status: %+v", err)
+		return model.NewError(model.ErrCodeInternal)
+	}
+
+	if err := u.auditTransaction(ctx, tx, entity.AuditPaymentCreated, transaction.TransactionID); err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to audit transaction: %+v", err)
+		return model.NewError(model.ErrCodeInternal)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to commit transaction: %+v", err)
+		return model.NewError(model.ErrCodeInternal)
+	}
+
+	return nil
+}
+
+// payFromHotAccount verifies the account outside any lock and hands the debit to the batcher,
+// which re-checks the balance under a row lock when the batch is applied
+func (u *QrisUseCase) payFromHotAccount(ctx context.Context, request *model.PaymentRequest, verified bool, transaction *entity.Transaction, quote *model.FxQuote) error {
+	account := new(entity.Account)
+	if err := u.AccountRepository.FindByAccountID(u.DB.WithContext(ctx), account, request.UserID); err != nil {
+		u.Log.WithContext(ctx).Warnf("Account not found for user: %s, error: %+v", request.UserID, err)
+		return model.NewError(model.ErrCodeAccountNotFound)
+	}
+
+	if err := u.convert(ctx, account, transaction, quote); err != nil {
+		return err
+	}
+
+	if err := u.verifyAccount(ctx, account, request, verified, transaction.Amount); err != nil {
+		return err
+	}
+
+	if err := u.screen(ctx, account, request, verified, transaction); err != nil {
+		return err
+	}
+
+	if err := u.reserveLimits(ctx, account, transaction); err != nil {
+		return err
+	}
+
+	return u.HotAccountBatcher.Debit(ctx, transaction)
+}
+
+// verifyAccount checks the PIN and that the balance covers the amount, in the account currency
+func (u *QrisUseCase) verifyAccount(ctx context.Context, account *entity.Account, request *model.PaymentRequest, verified bool, amount float64) error {
+	// Verify PIN, unless a verified step-up challenge completes the payment
+	if !verified {
+		err := u.checkPin(ctx, request.UserID, func() error {
+			_, bcryptSpan := tracer.Start(ctx, "bcrypt.CompareHashAndPassword")
+			defer bcryptSpan.End()
+			return bcrypt.CompareHashAndPassword([]byte(account.PinHash), []byte(request.Pincode))
+		})
+		if err != nil {
+			u.Log.WithContext(ctx).Warnf("PIN rejected for user: %s, error: %+v", request.UserID, err)
+			return err
+		}
+	}
+
+	// Check sufficient balance
+	if account.Balance < amount {
+		u.Log.WithContext(ctx).Warnf("Insufficient balance for user: %s", request.UserID)
+		return model.NewError(model.ErrCodeInsufficientBalance)
+	}
+
+	return nil
+}
+
+// reserveLimits counts the payment against the account's spending limits before the debit
+func (u *QrisUseCase) reserveLimits(ctx context.Context, account *entity.Account, transaction *entity.Transaction) error {
+	if u.Limits == nil {
+		return nil
+	}
+	return u.Limits.Reserve(ctx, account, transaction)
+}
+
+// auditTransaction appends a payment event to tx with the transaction as stored in it
+func (u *QrisUseCase) auditTransaction(ctx context.Context, tx *gorm.DB, action string, transactionID string) error {
+	transaction := new(entity.Transaction)
+	if err := u.TransactionRepository.FindByTransactionID(tx, transaction, transactionID); err != nil {
+		return err
+	}
+	event := u.Audit.Event(ctx, action, entity.AuditTargetTransaction, transactionID, nil, toTransactionResponse(transaction))
+	return u.Audit.Append(ctx, tx, event)
+}
+
+// auditPinFailure records a wrong PIN once the payment's database transaction has ended
+func (u *QrisUseCase) auditPinFailure(ctx context.Context, request *model.PaymentRequest, err error) {
+	var appErr *model.Error
+	if errors.As(err, &appErr) && appErr.Code == model.ErrCodeInvalidPIN {
+		u.Audit.Record(ctx, entity.AuditPinFailed, entity.AuditTargetAccount, request.UserID, nil, nil)
+	}
+}
+
+func (u *QrisUseCase) findAccount(tx *gorm.DB, account *entity.Account, accountID string) error {
+	if u.LockConfig.Strategy == LockStrategyPessimistic {
+		return u.AccountRepository.LockByAccountID(tx, account, accountID)
+	}
+	return u.AccountRepository.FindByAccountID(tx, account, accountID)
+}
+
+// deductBalance debits the account. When the version check loses the race against a
+// concurrent payment it re-reads the account and retries with jittered backoff.
+func (u *QrisUseCase) deductBalance(ctx context.Context, tx *gorm.DB, account *entity.Account, amount float64) error {
+	for attempt := 0; ; attempt++ {
+		err := u.AccountRepository.DeductBalance(tx, account.AccountID, amount, account.Version)
+		if err == nil {
+			return nil
+		}
+
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			u.Log.WithContext(ctx).Warnf("Failed to deduct balance: %+v", err)
+			return model.NewError(model.ErrCodeInternal)
+		}
+
+		if u.LockConfig.Strategy == LockStrategyPessimistic || attempt >= u.LockConfig.MaxRetries {
+			u.Log.WithContext(ctx).Warnf("Failed to deduct balance (optimistic lock conflict) after %d attempts for user: %s", attempt+1, account.AccountID)
+			return model.NewError(model.ErrCodeTransactionConflict)
+		}
+
+		if err := sleepWithJitter(ctx, u.LockConfig.RetryBackoff, attempt); err != nil {
+			return model.NewError(model.ErrCodeTransactionConflict)
+		}
+
+		if err := u.AccountRepository.FindByAccountID(tx, account, account.AccountID); err != nil {
+			u.Log.WithContext(ctx).Warnf("Failed to re-read account: %s, error: %+v", account.AccountID, err)
+			return model.NewError(model.ErrCodeInternal)
+		}
+
+		if account.Balance < amount {
+			u.Log.WithContext(ctx).Warnf("Insufficient balance for user: %s", account.AccountID)
+			return model.NewError(model.ErrCodeInsufficientBalance)
+		}
+	}
+}
+
+// sleepWithJitter waits for an exponentially growing backoff with full jitter
+func sleepWithJitter(ctx context.Context, base time.Duration, attempt int) error {
+	if base <= 0 {
+		return nil
+	}
+
+	backoff := base << attempt
+	timer := time.NewTimer(rand.N(backoff) + 1)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// merchantFee is the merchant discount rate charged on a payment, rounded to the cent
+func merchantFee(amount float64, mdrPercent float64) float64 {
+	return math.Round(amount*mdrPercent) / 100
+}
+
+// traceID correlates the transaction with the request that created it
+func traceID(ctx context.Context) string {
+	if requestContext, ok := model.RequestContextFrom(ctx); ok && requestContext.TraceID != "" {
+		return requestContext.TraceID
+	}
+	return uuid.New().String()
+}
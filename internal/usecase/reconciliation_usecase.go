@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"math"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/repository"
+	"golang-clean-architecture/internal/settlement"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ReconciliationUseCase struct {
+	DB                       *gorm.DB
+	Log                      *logrus.Logger
+	Validate                 *validator.Validate
+	TransactionRepository    *repository.TransactionRepository
+	ReconciliationRepository *repository.ReconciliationRepository
+	Parser                   *settlement.Parser
+	Location                 *time.Location
+	BatchSize                int
+}
+
+func NewReconciliationUseCase(
+	db *gorm.DB,
+	log *logrus.Logger,
+	validate *validator.Validate,
+	transactionRepo *repository.TransactionRepository,
+	reconciliationRepo *repository.ReconciliationRepository,
+	parser *settlement.Parser,
+	location *time.Location,
+	batchSize int,
+) *ReconciliationUseCase {
+	return &ReconciliationUseCase{
+		DB:                       db,
+		Log:                      log,
+		Validate:                 validate,
+		TransactionRepository:    transactionRepo,
+		ReconciliationRepository: reconciliationRepo,
+		Parser:                   parser,
+		Location:                 location,
+		BatchSize:                batchSize,
+	}
+}
+
+// Reconcile matches a settlement file against our transactions and persists the outcome as a
+// run. Each row is classified by its reference (our transaction ID or trace ID) and amount;
+// successful transactions of the settlement day that the file does not mention are recorded as
+// UNMATCHED. The whole run is stored atomically, so a malformed file leaves nothing behind.
+func (u *ReconciliationUseCase) Reconcile(ctx context.Context, request *model.ReconcileRequest, file io.Reader) (*model.ReconciliationRunResponse, error) {
+	ctx, span := tracer.Start(ctx, "ReconciliationUseCase.Reconcile")
+	defer span.End()
+
+	if err := u.Validate.Struct(request); err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid reconcile request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+	settlementDate, _ := time.ParseInLocation(reportDateLayout, request.SettlementDate, u.Location)
+
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	run := &entity.ReconciliationRun{
+		SettlementDate: settlementDate,
+		FileName:       request.FileName,
+	}
+	if err := u.ReconciliationRepository.Create(tx, run); err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to create reconciliation run: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	seen := make(map[string]bool)
+	batch := make([]*settlement.Record, 0, u.BatchSize)
+	for record, err := range u.Parser.Records(file) {
+		if err != nil {
+			u.Log.WithContext(ctx).Warnf("Invalid settlement file %s: %+v", request.FileName, err)
+			return nil, model.NewError(model.ErrCodeInvalidSettlement)
+		}
+
+		batch = append(batch, record)
+		if len(batch) == u.BatchSize {
+			if err := u.reconcileBatch(tx, run, batch, seen); err != nil {
+				u.Log.WithContext(ctx).Errorf("Failed to reconcile settlement rows: %+v", err)
+				return nil, model.NewError(model.ErrCodeInternal)
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := u.reconcileBatch(tx, run, batch, seen); err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to reconcile settlement rows: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	unmatched, err := u.ReconciliationRepository.CreateUnmatchedItems(tx, run.RunID, settlementDate, settlementDate.AddDate(0, 0, 1))
+	if err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to record unmatched transactions: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+	run.Unmatched = int(unmatched)
+
+	if err := u.ReconciliationRepository.Update(tx, run); err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to update reconciliation run: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to commit reconciliation run: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	u.Log.WithContext(ctx).Infof("Reconciled %s: %d rows, %d matched, %d amount mismatch, %d missing, %d duplicate, %d unmatched",
+		request.FileName, run.TotalRows, run.Matched, run.AmountMismatch, run.MissingOnOurSide, run.Duplicate, run.Unmatched)
+	return toReconciliationRunResponse(run), nil
+}
+
+// reconcileBatch classifies settlement rows with one lookup and stores them with one insert
+func (u *ReconciliationUseCase) reconcileBatch(tx *gorm.DB, run *entity.ReconciliationRun, records []*settlement.Record, seen map[string]bool) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	references := make([]string, len(records))
+	for i, record := range records {
+		references[i] = record.Reference
+	}
+
+	transactions, err := u.TransactionRepository.FindByReferences(tx, references)
+	if err != nil {
+		return err
+	}
+
+	byReference := make(map[string]*entity.Transaction, len(transactions)*2)
+	for i := range transactions {
+		byReference[transactions[i].TransactionID] = &transactions[i]
+		if transactions[i].TraceID != "" {
+			byReference[transactions[i].TraceID] = &transactions[i]
+		}
+	}
+
+	items := make([]*entity.ReconciliationItem, len(records))
+	for i, record := range records {
+		item := &entity.ReconciliationItem{
+			RunID:            run.RunID,
+			LineNumber:       record.Line,
+			Reference:        record.Reference,
+			SettlementAmount: &record.Amount,
+		}
+
+		transaction, found := byReference[record.Reference]
+		if found {
+			item.TransactionID = &transaction.TransactionID
+			item.OurAmount = &transaction.Amount
+			item.OurStatus = &transaction.Status
+		}
+
+		switch {
+		case seen[record.Reference]:
+			item.Result = entity.ReconciliationDuplicate
+			run.Duplicate++
+		case !found || transaction.Status != entity.TransactionStatusSuccess:
+			item.Result = entity.ReconciliationMissingOnOurSide
+			run.MissingOnOurSide++
+		case math.Abs(transaction.Amount-record.Amount) >= 0.005:
+			item.Result = entity.ReconciliationAmountMismatch
+			run.AmountMismatch++
+		default:
+			item.Result = entity.ReconciliationMatched
+			run.Matched++
+		}
+
+		seen[record.Reference] = true
+		items[i] = item
+	}
+	run.TotalRows += len(records)
+
+	return u.ReconciliationRepository.CreateItems(tx, items)
+}
+
+// Report returns a run's summary with one page of its items
+func (u *ReconciliationUseCase) Report(ctx context.Context, request *model.ReconciliationReportRequest) (*model.ReconciliationReportResponse, error) {
+	ctx, span := tracer.Start(ctx, "ReconciliationUseCase.Report")
+	defer span.End()
+
+	if err := u.Validate.Struct(request); err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid reconciliation report request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	db := u.DB.WithContext(ctx)
+
+	run := new(entity.ReconciliationRun)
+	if err := u.ReconciliationRepository.FindByRunID(db, run, request.RunID); err != nil {
+		u.Log.WithContext(ctx).Warnf("Reconciliation run not found: %s, error: %+v", request.RunID, err)
+		return nil, model.NewError(model.ErrCodeReconRunNotFound)
+	}
+
+	items, total, err := u.ReconciliationRepository.FindItems(db, run.RunID, request.Result, (request.Page-1)*request.Size, request.Size)
+	if err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to find reconciliation items: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	response := &model.ReconciliationReportResponse{
+		Run:   toReconciliationRunResponse(run),
+		Items: make([]*model.ReconciliationItemResponse, len(items)),
+		Paging: &model.PageMetadata{
+			Page:      request.Page,
+			Size:      request.Size,
+			TotalItem: total,
+			TotalPage: (total + int64(request.Size) - 1) / int64(request.Size),
+		},
+	}
+	for i, item := range items {
+		response.Items[i] = toReconciliationItemResponse(&item)
+	}
+
+	return response, nil
+}
+
+func toReconciliationRunResponse(run *entity.ReconciliationRun) *model.ReconciliationRunResponse {
+	return &model.ReconciliationRunResponse{
+		RunID:            run.RunID,
+		SettlementDate:   run.SettlementDate.Format(reportDateLayout),
+		FileName:         run.FileName,
+		TotalRows:        run.TotalRows,
+		Matched:          run.Matched,
+		AmountMismatch:   run.AmountMismatch,
+		MissingOnOurSide: run.MissingOnOurSide,
+		Duplicate:        run.Duplicate,
+		Unmatched:        run.Unmatched,
+		CreatedAt:        run.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toReconciliationItemResponse(item *entity.ReconciliationItem) *model.ReconciliationItemResponse {
+	response := &model.ReconciliationItemResponse{
+		LineNumber:       item.LineNumber,
+		Reference:        item.Reference,
+		SettlementAmount: item.SettlementAmount,
+		OurAmount:        item.OurAmount,
+		Result:           item.Result,
+	}
+	if item.TransactionID != nil {
+		response.TransactionID = *item.TransactionID
+	}
+	if item.OurStatus != nil {
+		response.OurStatus = *item.OurStatus
+	}
+	return response
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"iter"
+	"math"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const reportDateLayout = "2006-01-02"
+
+type ReportUseCase struct {
+	DB                    *gorm.DB
+	Log                   *logrus.Logger
+	Validate              *validator.Validate
+	MerchantRepository    *repository.MerchantRepository
+	TransactionRepository *repository.TransactionRepository
+	Location              *time.Location
+	MaxRangeDays          int
+}
+
+func NewReportUseCase(
+	db *gorm.DB,
+	log *logrus.Logger,
+	validate *validator.Validate,
+	merchantRepo *repository.MerchantRepository,
+	transactionRepo *repository.TransactionRepository,
+	location *time.Location,
+	maxRangeDays int,
+) *ReportUseCase {
+	return &ReportUseCase{
+		DB:                    db,
+		Log:                   log,
+		Validate:              validate,
+		MerchantRepository:    merchantRepo,
+		TransactionRepository: transactionRepo,
+		Location:              location,
+		MaxRangeDays:          maxRangeDays,
+	}
+}
+
+// SalesReport aggregates a merchant's transactions and returns every row with their total
+func (u *ReportUseCase) SalesReport(ctx context.Context, request *model.SalesReportRequest) (*model.SalesReportResponse, error) {
+	rows, err := u.StreamSalesReport(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.SalesReportResponse{
+		MerchantID: request.MerchantID,
+		From:       request.From,
+		To:         request.To,
+		Timezone:   u.Location.String(),
+		GroupBy:    request.GroupBy,
+		Rows:       []*model.SalesReportRow{},
+	}
+	for row, err := range rows {
+		if err != nil {
+			u.Log.WithContext(ctx).Errorf("Failed to read sales report: %+v", err)
+			return nil, model.NewError(model.ErrCodeInternal)
+		}
+		response.Rows = append(response.Rows, row)
+		response.Total.Add(row)
+	}
+
+	return response, nil
+}
+
+// StreamSalesReport validates the request and opens the report query. The returned sequence
+// reads one aggregated row at a time and must be ranged over to release the connection.
+//
+// Days are calendar days in the database time zone, matching how created_at is stored. Unless
+// the report is grouped by status, only SUCCESS transactions are counted as sales.
+func (u *ReportUseCase) StreamSalesReport(ctx context.Context, request *model.SalesReportRequest) (iter.Seq2[*model.SalesReportRow, error], error) {
+	ctx, span := tracer.Start(ctx, "ReportUseCase.StreamSalesReport")
+	defer span.End()
+
+	if len(request.GroupBy) == 0 {
+		request.GroupBy = []string{repository.SalesGroupDay}
+	}
+
+	if err := u.Validate.Struct(request); err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid sales report request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	from, _ := time.ParseInLocation(reportDateLayout, request.From, u.Location)
+	to, _ := time.ParseInLocation(reportDateLayout, request.To, u.Location)
+	if to.Before(from) || to.Sub(from) >= time.Duration(u.MaxRangeDays)*24*time.Hour {
+		return nil, model.NewError(model.ErrCodeInvalidReportRange)
+	}
+
+	db := u.DB.WithContext(ctx)
+
+	merchant := new(entity.Merchant)
+	if err := u.MerchantRepository.FindByMerchantID(db, merchant, request.MerchantID); err != nil {
+		u.Log.WithContext(ctx).Warnf("Merchant not found: %s, error: %+v", request.MerchantID, err)
+		return nil, model.NewError(model.ErrCodeMerchantNotFound)
+	}
+
+	query := repository.SalesSummaryQuery{
+		MerchantID: request.MerchantID,
+		From:       from,
+		To:         to.AddDate(0, 0, 1),
+		Status:     entity.TransactionStatusSuccess,
+		GroupBy:    request.GroupBy,
+	}
+	for _, group := range request.GroupBy {
+		if group == repository.SalesGroupStatus {
+			query.Status = ""
+		}
+	}
+
+	rows, err := u.TransactionRepository.SummarizeSales(db, query)
+	if err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to query sales report: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	return func(yield func(*model.SalesReportRow, error) bool) {
+		defer rows.Close()
+
+		for rows.Next() {
+			summary := new(entity.SalesSummary)
+			if err := u.TransactionRepository.ScanSalesSummary(db, rows, summary); err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(toSalesReportRow(summary), nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(nil, err)
+		}
+	}, nil
+}
+
+func toSalesReportRow(summary *entity.SalesSummary) *model.SalesReportRow {
+	row := &model.SalesReportRow{
+		TerminalID: summary.TerminalID,
+		Status:     summary.Status,
+		Count:      summary.Count,
+		Gross:      summary.Gross,
+		Fees:       summary.Fees,
+		Net:        math.Round((summary.Gross-summary.Fees)*100) / 100,
+	}
+	if summary.Date != nil {
+		row.Date = summary.Date.Format(reportDateLayout)
+	}
+	return row
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/repository"
+	"golang-clean-architecture/internal/risk"
+
+	"gorm.io/gorm"
+)
+
+// RiskConfig screens payments with a risk engine between the PIN check and the debit
+type RiskConfig struct {
+	Engine risk.Engine
+	// Shadow records and logs decisions without enforcing them
+	Shadow bool
+}
+
+// RiskHistory answers the rule engine from the transactions table
+type RiskHistory struct {
+	DB                    *gorm.DB
+	TransactionRepository *repository.TransactionRepository
+}
+
+func NewRiskHistory(db *gorm.DB, transactionRepository *repository.TransactionRepository) *RiskHistory {
+	return &RiskHistory{
+		DB:                    db,
+		TransactionRepository: transactionRepository,
+	}
+}
+
+func (h *RiskHistory) AmountStats(ctx context.Context, accountID string, since time.Time) (float64, int64, error) {
+	return h.TransactionRepository.AverageAmount(h.DB.WithContext(ctx), accountID, since)
+}
+
+func (h *RiskHistory) Merchants(ctx context.Context, accountID string, since time.Time) ([]string, error) {
+	return h.TransactionRepository.DistinctMerchants(h.DB.WithContext(ctx), accountID, since)
+}
+
+func (h *RiskHistory) KnownDevice(ctx context.Context, accountID string, deviceID string) (bool, error) {
+	return h.TransactionRepository.HasDevice(h.DB.WithContext(ctx), accountID, deviceID)
+}
+
+// screen evaluates the payment with the risk engine and stops payments that need a step-up
+// challenge before the debit
+func (u *QrisUseCase) screen(ctx context.Context, account *entity.Account, request *model.PaymentRequest, verified bool, transaction *entity.Transaction) error {
+	flagged, err := u.assessRisk(ctx, transaction)
+	if err != nil {
+		return err
+	}
+	return u.requireStepUp(account, verified, transaction, flagged)
+}
+
+// assessRisk keeps the risk decision on the transaction and reports whether it asks for a
+// step-up. Whitelisted accounts and merchants are not screened. Screening fails open: payments
+// are approved when the engine errors.
+func (u *QrisUseCase) assessRisk(ctx context.Context, transaction *entity.Transaction) (bool, error) {
+	if u.Risk.Engine == nil || u.whitelisted(ctx, transaction) {
+		return false, nil
+	}
+
+	ctx, span := tracer.Start(ctx, "QrisUseCase.assessRisk")
+	defer span.End()
+
+	decision, err := u.Risk.Engine.Evaluate(ctx, risk.Payment{
+		AccountID:  transaction.AccountID,
+		MerchantID: transaction.MerchantID,
+		Amount:     transaction.Amount,
+		DeviceID:   transaction.DeviceID,
+		At:         u.DB.NowFunc(),
+	})
+	if err != nil {
+		u.Log.WithContext(ctx).Warnf("Risk screening failed, approving payment for user: %s, error: %+v", transaction.AccountID, err)
+		return false, nil
+	}
+
+	action := string(decision.Action)
+	rules := strings.Join(decision.Rules, ",")
+	transaction.RiskAction = &action
+	transaction.RiskScore = &decision.Score
+	transaction.RiskRules = &rules
+	u.Metrics.ObserveRiskDecision(action, u.Risk.Shadow)
+
+	if decision.Action == risk.ActionApprove {
+		return false, nil
+	}
+	if u.Risk.Shadow {
+		u.Log.WithContext(ctx).Infof("Shadow risk decision %s for user: %s, score: %d, rules: %s", action, transaction.AccountID, decision.Score, rules)
+		return false, nil
+	}
+
+	u.Log.WithContext(ctx).Warnf("Risk decision %s for user: %s, score: %d, rules: %s", action, transaction.AccountID, decision.Score, rules)
+	if decision.Action == risk.ActionDecline {
+		return false, model.NewError(model.ErrCodeRiskDeclined)
+	}
+	// Without step-up challenges the payment can only be refused
+	if u.StepUp.Sender == nil {
+		return false, model.NewError(model.ErrCodeStepUpRequired)
+	}
+	return true, nil
+}
+
+// recordRejected keeps payments stopped by screening as FAILED transactions with their decision,
+// outside the rolled back debit, so they can be reviewed
+func (u *QrisUseCase) recordRejected(ctx context.Context, transaction *entity.Transaction, err error) {
+	var appErr *model.Error
+	if !errors.As(err, &appErr) || (appErr.Code != model.ErrCodeRiskDeclined && appErr.Code != model.ErrCodeStepUpRequired) {
+		return
+	}
+
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	transaction.Status = entity.TransactionStatusFailed
+	if err := u.TransactionRepository.Create(tx, transaction); err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to record rejected transaction: %+v", err)
+		return
+	}
+
+	err = u.auditTransaction(ctx, tx, entity.AuditPaymentRejected, transaction.TransactionID)
+	if err == nil {
+		err = tx.Commit().Error
+	}
+	if err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to record rejected transaction: %+v", err)
+	}
+}
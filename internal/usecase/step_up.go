@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/otp"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// ChallengeMethodTOTP is answered with the authenticator app of accounts with a TOTP secret
+	ChallengeMethodTOTP = "totp"
+	// ChallengeMethodOTP is answered with a code delivered by the OTP sender
+	ChallengeMethodOTP = "otp"
+)
+
+// StepUpConfig requires a TOTP or an OTP on top of the PIN for payments above Threshold, in the
+// account currency, and for payments risk screening asks to step up
+type StepUpConfig struct {
+	// Sender delivers OTPs to accounts without a TOTP secret; nil turns step-up off
+	Sender      otp.Sender
+	Threshold   float64
+	MaxAttempts int
+}
+
+// stepUpChallenge is a payment waiting for its challenge to be answered. It lives in Redis no
+// longer than its inquiry and never holds the PIN.
+type stepUpChallenge struct {
+	Request  model.PaymentRequest `json:"request"`
+	Method   string               `json:"method"`
+	CodeHash string               `json:"code_hash,omitempty"`
+}
+
+// challengeRequired stops a payment path before the debit when the payment needs a step-up
+type challengeRequired struct {
+	method string
+}
+
+func (e *challengeRequired) Error() string {
+	return "step-up challenge required"
+}
+
+// requireStepUp stops payments above the threshold, or flagged by risk screening, until a
+// challenge is verified
+func (u *QrisUseCase) requireStepUp(account *entity.Account, verified bool, transaction *entity.Transaction, flagged bool) error {
+	if u.StepUp.Sender == nil || verified {
+		return nil
+	}
+	if !flagged && (u.StepUp.Threshold <= 0 || transaction.Amount <= u.StepUp.Threshold) {
+		return nil
+	}
+
+	if account.TotpSecret != "" {
+		return &challengeRequired{method: ChallengeMethodTOTP}
+	}
+	return &challengeRequired{method: ChallengeMethodOTP}
+}
+
+// issueChallenge stores the payment under a new challenge, expiring with its inquiry, and
+// sends the OTP when the account has no TOTP secret
+func (u *QrisUseCase) issueChallenge(ctx context.Context, request *model.PaymentRequest, method string) (*model.PaymentResponse, error) {
+	ttl, err := u.RedisClient.TTL(ctx, fmt.Sprintf("inquiry:%s", request.InquiryID)).Result()
+	if err != nil || ttl <= 0 {
+		u.Log.WithContext(ctx).Warnf("Invalid or expired inquiry_id: %s", request.InquiryID)
+		return nil, model.NewError(model.ErrCodeInquiryExpired)
+	}
+
+	challengeID := uuid.New().String()
+	challenge := stepUpChallenge{
+		Request: *request,
+		Method:  method,
+	}
+	challenge.Request.Pincode = ""
+
+	var code string
+	if method == ChallengeMethodOTP {
+		code, err = otp.GenerateCode()
+		if err != nil {
+			u.Log.WithContext(ctx).Errorf("Failed to generate OTP: %+v", err)
+			return nil, model.NewError(model.ErrCodeInternal)
+		}
+		challenge.CodeHash = hashCode(challengeID, code)
+	}
+
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to marshal challenge: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+	if err := u.RedisClient.Set(ctx, challengeKey(challengeID), data, ttl).Err(); err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to store challenge: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	if method == ChallengeMethodOTP {
+		if err := u.StepUp.Sender.Send(ctx, request.UserID, code); err != nil {
+			u.Log.WithContext(ctx).Warnf("Failed to send OTP for user: %s, error: %+v", request.UserID, err)
+			u.RedisClient.Del(ctx, challengeKey(challengeID))
+			return nil, model.NewError(model.ErrCodeOTPDeliveryFailed)
+		}
+	}
+
+	u.Log.WithContext(ctx).Infof("Step-up challenge %s (%s) issued for user: %s", challengeID, method, request.UserID)
+	expiresAt := time.Now().Add(ttl)
+	return &model.PaymentResponse{
+		Status:             model.PaymentStatusChallengeRequired,
+		Message:            "Verifikasi tambahan diperlukan",
+		ChallengeID:        challengeID,
+		ChallengeMethod:    method,
+		ChallengeExpiresAt: &expiresAt,
+	}, nil
+}
+
+// VerifyChallenge checks the code answering a step-up challenge and completes the original
+// payment. The challenge is cancelled after StepUp.MaxAttempts wrong codes.
+func (u *QrisUseCase) VerifyChallenge(ctx context.Context, request *model.VerifyChallengeRequest) (response *model.PaymentResponse, err error) {
+	ctx, span := tracer.Start(ctx, "QrisUseCase.VerifyChallenge")
+	defer span.End()
+	defer func() { u.Metrics.ObservePayment(response, err) }()
+
+	if err := u.Validate.Struct(request); err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid challenge request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	key := challengeKey(request.ChallengeID)
+	data, err := u.RedisClient.Get(ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			u.Log.WithContext(ctx).Warnf("Failed to get challenge: %+v", err)
+		}
+		return nil, model.NewError(model.ErrCodeChallengeExpired)
+	}
+
+	challenge := new(stepUpChallenge)
+	if err := json.Unmarshal(data, challenge); err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to parse challenge: %+v", err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	valid, err := u.checkCode(ctx, request, challenge)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, u.failAttempt(ctx, request.ChallengeID, challenge.Request.UserID)
+	}
+
+	// Deleting the challenge claims it, so concurrent verifications complete the payment once
+	deleted, err := u.RedisClient.Del(ctx, key).Result()
+	if err != nil || deleted == 0 {
+		return nil, model.NewError(model.ErrCodeChallengeExpired)
+	}
+	u.RedisClient.Del(ctx, key+":attempts")
+
+	return u.pay(ctx, &challenge.Request, true)
+}
+
+// checkCode verifies the code against the OTP sent or the account's TOTP secret; a TOTP is
+// accepted once
+func (u *QrisUseCase) checkCode(ctx context.Context, request *model.VerifyChallengeRequest, challenge *stepUpChallenge) (bool, error) {
+	if challenge.Method == ChallengeMethodOTP {
+		expected := []byte(challenge.CodeHash)
+		return subtle.ConstantTimeCompare(expected, []byte(hashCode(request.ChallengeID, request.Code))) == 1, nil
+	}
+
+	account := new(entity.Account)
+	if err := u.AccountRepository.FindByAccountID(u.DB.WithContext(ctx), account, challenge.Request.UserID); err != nil {
+		u.Log.WithContext(ctx).Warnf("Account not found for user: %s, error: %+v", challenge.Request.UserID, err)
+		return false, model.NewError(model.ErrCodeAccountNotFound)
+	}
+
+	step, valid := otp.ValidateTOTP(account.TotpSecret, request.Code, time.Now())
+	if !valid {
+		return false, nil
+	}
+
+	usedKey := fmt.Sprintf("totp:%s:%d", account.AccountID, step)
+	fresh, err := u.RedisClient.SetNX(ctx, usedKey, 1, (2*otp.Skew+1)*otp.Period).Result()
+	if err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to record TOTP use: %+v", err)
+		return false, model.NewError(model.ErrCodeInternal)
+	}
+	return fresh, nil
+}
+
+// failAttempt counts a wrong code and cancels the challenge once the attempts run out
+func (u *QrisUseCase) failAttempt(ctx context.Context, challengeID string, userID string) error {
+	key := challengeKey(challengeID)
+	u.Audit.Record(ctx, entity.AuditStepUpFailed, entity.AuditTargetAccount, userID, nil, nil)
+
+	attempts, err := u.RedisClient.Incr(ctx, key+":attempts").Result()
+	if err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to count challenge attempt: %+v", err)
+		return model.NewError(model.ErrCodeInternal)
+	}
+	u.RedisClient.Expire(ctx, key+":attempts", 5*time.Minute)
+
+	if int(attempts) >= u.StepUp.MaxAttempts {
+		u.Log.WithContext(ctx).Warnf("Step-up challenge %s cancelled after %d failed attempts for user: %s", challengeID, attempts, userID)
+		u.RedisClient.Del(ctx, key, key+":attempts")
+		return model.NewError(model.ErrCodeOTPAttemptsExceeded)
+	}
+
+	u.Log.WithContext(ctx).Warnf("Invalid step-up code for user: %s", userID)
+	return model.NewError(model.ErrCodeInvalidOTP)
+}
+
+func challengeKey(challengeID string) string {
+	return fmt.Sprintf("challenge:%s", challengeID)
+}
+
+// hashCode keeps OTPs out of Redis; the challenge ID salts the hash
+func hashCode(challengeID string, code string) string {
+	sum := sha256.Sum256([]byte(challengeID + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/otp"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+type failingSender struct{}
+
+func (failingSender) Send(ctx context.Context, accountID string, code string) error {
+	return errors.New("sms gateway down")
+}
+
+func newStepUpUseCase(t *testing.T, sender otp.Sender) (*QrisUseCase, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	log := logrus.New()
+	log.SetOutput(testWriter{t})
+
+	return &QrisUseCase{
+		Log:         log,
+		RedisClient: redis.NewClient(&redis.Options{Addr: server.Addr()}),
+		StepUp:      StepUpConfig{Sender: sender, Threshold: 1000000, MaxAttempts: 3},
+	}, server
+}
+
+type testWriter struct{ t *testing.T }
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Log(strings.TrimSpace(string(p)))
+	return len(p), nil
+}
+
+func stepUpPayment(server *miniredis.Miniredis) *model.PaymentRequest {
+	server.Set("inquiry:inq_1", "{}")
+	server.SetTTL("inquiry:inq_1", 5*time.Minute)
+	return &model.PaymentRequest{InquiryID: "inq_1", UserID: "user_123", Amount: 2000000, Pincode: "123456"}
+}
+
+func TestIssueChallengeSendsOTP(t *testing.T) {
+	sender := otp.NewFakeSender(logrus.New())
+	u, server := newStepUpUseCase(t, sender)
+
+	response, err := u.issueChallenge(context.Background(), stepUpPayment(server), ChallengeMethodOTP)
+	if err != nil {
+		t.Fatalf("issueChallenge: %v", err)
+	}
+	if response.Status != model.PaymentStatusChallengeRequired || response.ChallengeMethod != ChallengeMethodOTP {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+
+	code := sender.LastCode("user_123")
+	if len(code) != otp.Digits {
+		t.Fatalf("sent code %q, want %d digits", code, otp.Digits)
+	}
+
+	stored, err := server.Get(challengeKey(response.ChallengeID))
+	if err != nil {
+		t.Fatalf("challenge not stored: %v", err)
+	}
+	if strings.Contains(stored, code) || strings.Contains(stored, "123456") {
+		t.Fatalf("challenge holds the code or the PIN: %s", stored)
+	}
+	if ttl := server.TTL(challengeKey(response.ChallengeID)); ttl <= 0 || ttl > 5*time.Minute {
+		t.Fatalf("challenge ttl %v, want the inquiry's", ttl)
+	}
+
+	challenge := new(stepUpChallenge)
+	if err := json.Unmarshal([]byte(stored), challenge); err != nil {
+		t.Fatalf("stored challenge: %v", err)
+	}
+	tests := []struct {
+		name  string
+		code  string
+		valid bool
+	}{
+		{name: "sent code", code: code, valid: true},
+		{name: "other code", code: otherCode(code), valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &model.VerifyChallengeRequest{ChallengeID: response.ChallengeID, Code: tt.code}
+			valid, err := u.checkCode(context.Background(), request, challenge)
+			if err != nil {
+				t.Fatalf("checkCode: %v", err)
+			}
+			if valid != tt.valid {
+				t.Fatalf("checkCode(%q) = %v, want %v", tt.code, valid, tt.valid)
+			}
+		})
+	}
+}
+
+func TestIssueChallengeDeliveryFailure(t *testing.T) {
+	u, server := newStepUpUseCase(t, failingSender{})
+
+	_, err := u.issueChallenge(context.Background(), stepUpPayment(server), ChallengeMethodOTP)
+	var appErr *model.Error
+	if !errors.As(err, &appErr) || appErr.Code != model.ErrCodeOTPDeliveryFailed {
+		t.Fatalf("issueChallenge error = %v, want %s", err, model.ErrCodeOTPDeliveryFailed)
+	}
+	if keys := server.Keys(); len(keys) != 1 || keys[0] != "inquiry:inq_1" {
+		t.Fatalf("undeliverable challenge kept: %v", keys)
+	}
+}
+
+func TestIssueChallengeExpiredInquiry(t *testing.T) {
+	sender := otp.NewFakeSender(logrus.New())
+	u, _ := newStepUpUseCase(t, sender)
+
+	request := &model.PaymentRequest{InquiryID: "inq_gone", UserID: "user_123", Amount: 2000000}
+	_, err := u.issueChallenge(context.Background(), request, ChallengeMethodOTP)
+	var appErr *model.Error
+	if !errors.As(err, &appErr) || appErr.Code != model.ErrCodeInquiryExpired {
+		t.Fatalf("issueChallenge error = %v, want %s", err, model.ErrCodeInquiryExpired)
+	}
+	if code := sender.LastCode("user_123"); code != "" {
+		t.Fatalf("code %q sent for an expired inquiry", code)
+	}
+}
+
+// otherCode returns a code of the same length that differs from code
+func otherCode(code string) string {
+	if code[0] == '9' {
+		return "0" + code[1:]
+	}
+	return string(code[0]+1) + code[1:]
+}
@@ -5,10 +5,11 @@ import (
 	"time"
 
 	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/gateway/switching"
 	"golang-clean-architecture/internal/model"
 	"golang-clean-architecture/internal/repository"
 
-	"github.com/gofiber/fiber/v2"
+	"github.com/go-playground/validator/v10"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
@@ -16,32 +17,44 @@ import (
 type TransactionUseCase struct {
 	DB                    *gorm.DB
 	Log                   *logrus.Logger
+	Validate              *validator.Validate
 	TransactionRepository *repository.TransactionRepository
 	AccountRepository     *repository.AccountRepository
+	Switch                switching.Client
+	Audit                 *AuditUseCase
 }
 
 func NewTransactionUseCase(
 	db *gorm.DB,
 	log *logrus.Logger,
+	validate *validator.Validate,
 	transactionRepo *repository.TransactionRepository,
 	accountRepo *repository.AccountRepository,
+	switchClient switching.Client,
+	audit *AuditUseCase,
 ) *TransactionUseCase {
 	return &TransactionUseCase{
 		DB:                    db,
 		Log:                   log,
+		Validate:              validate,
 		TransactionRepository: transactionRepo,
 		AccountRepository:     accountRepo,
+		Switch:                switchClient,
+		Audit:                 audit,
 	}
 }
 
 // GetStatus returns the status of a transaction
 func (u *TransactionUseCase) GetStatus(ctx context.Context, transactionID string) (*model.TransactionStatusResponse, error) {
+	ctx, span := tracer.Start(ctx, "TransactionUseCase.GetStatus")
+	defer span.End()
+
 	tx := u.DB.WithContext(ctx)
 
 	transaction := new(entity.Transaction)
 	if err := u.TransactionRepository.FindByTransactionID(tx, transaction, transactionID); err != nil {
-		u.Log.Warnf("Transaction not found: %s, error: %+v", transactionID, err)
-		return nil, fiber.NewError(fiber.StatusNotFound, "Transaction not found")
+		u.Log.WithContext(ctx).Warnf("Transaction not found: %s, error: %+v", transactionID, err)
+		return nil, model.NewError(model.ErrCodeTransactionNotFound)
 	}
 
 	// Get current balance
@@ -58,3 +71,211 @@ func (u *TransactionUseCase) GetStatus(ctx context.Context, transactionID string
 		Timestamp:     transaction.CreatedAt.Format(time.RFC3339),
 	}, nil
 }
+
+// ExpirePending resolves PENDING transactions older than ttl. Transactions that never
+// touched the balance are marked EXPIRED; those that did are reversed and marked FAILED.
+// Off-us debits are only refunded once the switch acknowledges their reversal, the acquirer
+// may have been paid already; until then they stay PENDING and are retried on the next run.
+func (u *TransactionUseCase) ExpirePending(ctx context.Context, ttl time.Duration, limit int) (*model.ExpirePendingResult, error) {
+	ctx, span := tracer.Start(ctx, "TransactionUseCase.ExpirePending")
+	defer span.End()
+
+	db := u.DB.WithContext(ctx)
+
+	// created_at holds database wall clock time, which the process clock need not share
+	transactions, err := u.TransactionRepository.FindPendingBefore(db, db.NowFunc().Add(-ttl), limit)
+	if err != nil {
+		u.Log.WithContext(ctx).Warnf("Failed to find pending transactions: %+v", err)
+		return nil, err
+	}
+
+	result := &model.ExpirePendingResult{Scanned: len(transactions)}
+	for _, candidate := range transactions {
+		if candidate.AcquirerID != "" && candidate.DebitedAt != nil && !u.reverseOffUs(ctx, &candidate) {
+			continue
+		}
+
+		status, err := u.expireTransaction(ctx, db, candidate.TransactionID)
+		if err != nil {
+			u.Log.WithContext(ctx).Warnf("Failed to expire transaction: %s, error: %+v", candidate.TransactionID, err)
+			continue
+		}
+
+		switch status {
+		case entity.TransactionStatusExpired:
+			result.Expired++
+		case entity.TransactionStatusFailed:
+			result.Failed++
+		}
+	}
+
+	return result, nil
+}
+
+// expireTransaction re-checks a single transaction under a row lock and resolves it.
+// It returns an empty status when the transaction was resolved by someone else meanwhile.
+func (u *TransactionUseCase) expireTransaction(ctx context.Context, db *gorm.DB, transactionID string) (string, error) {
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	transaction := new(entity.Transaction)
+	if err := u.TransactionRepository.LockByTransactionID(tx, transaction, transactionID); err != nil {
+		return "", err
+	}
+
+	if transaction.Status != entity.TransactionStatusPending {
+		return "", nil
+	}
+
+	status := entity.TransactionStatusExpired
+	if transaction.DebitedAt != nil {
+		status = entity.TransactionStatusFailed
+	}
+
+	before := toTransactionResponse(transaction)
+	if err := u.resolve(tx, transaction, status); err != nil {
+		return "", err
+	}
+
+	event := u.Audit.Event(ctx, entity.AuditTransactionExpired, entity.AuditTargetTransaction, transactionID, before, toTransactionResponse(transaction))
+	if err := u.Audit.Append(ctx, tx, event); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return "", err
+	}
+
+	u.Log.WithContext(ctx).Infof("Resolved stale pending transaction: %s as %s", transactionID, status)
+	return status, nil
+}
+
+// reverseOffUs asks the switch to reverse a stale off-us transaction and reports whether the
+// reversal was acknowledged
+func (u *TransactionUseCase) reverseOffUs(ctx context.Context, transaction *entity.Transaction) bool {
+	if u.Switch == nil {
+		u.Log.WithContext(ctx).Warnf("Switching is disabled, leaving off-us transaction: %s pending", transaction.TransactionID)
+		return false
+	}
+
+	response, err := u.Switch.Reversal(ctx, &model.SwitchReversalRequest{
+		Reference:  transaction.TransactionID,
+		AcquirerID: transaction.AcquirerID,
+		Amount:     merchantAmount(transaction),
+	})
+	if err != nil || response.ResponseCode != model.SwitchResponseApproved {
+		u.Log.WithContext(ctx).Warnf("Reversal not acknowledged for stale transaction: %s, error: %+v", transaction.TransactionID, err)
+		return false
+	}
+
+	u.Log.WithContext(ctx).Infof("Reversed stale off-us transaction: %s", transaction.TransactionID)
+	return true
+}
+
+// resolve moves a locked PENDING or SWITCH_APPROVED transaction to a final status, refunding
+// the debit when the transaction does not end up as SUCCESS
+func (u *TransactionUseCase) resolve(tx *gorm.DB, transaction *entity.Transaction, status string) error {
+	if status != entity.TransactionStatusSuccess && transaction.DebitedAt != nil {
+		if err := u.AccountRepository.CreditBalance(tx, transaction.AccountID, transaction.Amount); err != nil {
+			return err
+		}
+	}
+
+	if err := u.TransactionRepository.UpdateStatus(tx, transaction.TransactionID, status); err != nil {
+		return err
+	}
+	transaction.Status = status
+	return nil
+}
+
+// Get returns the full transaction record
+func (u *TransactionUseCase) Get(ctx context.Context, transactionID string) (*model.TransactionResponse, error) {
+	ctx, span := tracer.Start(ctx, "TransactionUseCase.Get")
+	defer span.End()
+
+	transaction := new(entity.Transaction)
+	if err := u.TransactionRepository.FindByTransactionID(u.DB.WithContext(ctx), transaction, transactionID); err != nil {
+		u.Log.WithContext(ctx).Warnf("Transaction not found: %s, error: %+v", transactionID, err)
+		return nil, model.NewError(model.ErrCodeTransactionNotFound)
+	}
+
+	return toTransactionResponse(transaction), nil
+}
+
+// ForceTransition lets an operator resolve a stuck PENDING transaction. SUCCESS is only allowed
+// once the balance was debited; EXPIRED and FAILED refund a debit that already happened. A
+// SWITCH_APPROVED transaction can only be confirmed as SUCCESS, the acquirer has the money.
+func (u *TransactionUseCase) ForceTransition(ctx context.Context, request *model.ForceTransitionRequest) (*model.TransactionResponse, error) {
+	ctx, span := tracer.Start(ctx, "TransactionUseCase.ForceTransition")
+	defer span.End()
+
+	if err := u.Validate.Struct(request); err != nil {
+		u.Log.WithContext(ctx).Warnf("Invalid force transition request: %+v", err)
+		return nil, model.NewError(model.ErrCodeValidationFailed)
+	}
+
+	tx := u.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	transaction := new(entity.Transaction)
+	if err := u.TransactionRepository.LockByTransactionID(tx, transaction, request.TransactionID); err != nil {
+		u.Log.WithContext(ctx).Warnf("Transaction not found: %s, error: %+v", request.TransactionID, err)
+		return nil, model.NewError(model.ErrCodeTransactionNotFound)
+	}
+
+	switch transaction.Status {
+	case entity.TransactionStatusPending:
+	case entity.TransactionStatusSwitchApproved:
+		if request.Status != entity.TransactionStatusSuccess {
+			return nil, model.NewError(model.ErrCodeInvalidTransition)
+		}
+	default:
+		return nil, model.NewError(model.ErrCodeTransactionNotPending)
+	}
+
+	if request.Status == entity.TransactionStatusSuccess && transaction.DebitedAt == nil {
+		return nil, model.NewError(model.ErrCodeInvalidTransition)
+	}
+
+	before := toTransactionResponse(transaction)
+	if err := u.resolve(tx, transaction, request.Status); err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to transition transaction: %s, error: %+v", request.TransactionID, err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	response := toTransactionResponse(transaction)
+	event := u.Audit.Event(ctx, entity.AuditTransactionForced, entity.AuditTargetTransaction, request.TransactionID, before, response)
+	if err := u.Audit.Append(ctx, tx, event); err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to audit transition: %s, error: %+v", request.TransactionID, err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		u.Log.WithContext(ctx).Errorf("Failed to commit transition: %s, error: %+v", request.TransactionID, err)
+		return nil, model.NewError(model.ErrCodeInternal)
+	}
+
+	u.Log.WithContext(ctx).Infof("Forced transaction: %s to %s", request.TransactionID, request.Status)
+	return response, nil
+}
+
+func toTransactionResponse(transaction *entity.Transaction) *model.TransactionResponse {
+	response := &model.TransactionResponse{
+		TransactionID:    transaction.TransactionID,
+		TraceID:          transaction.TraceID,
+		AccountID:        transaction.AccountID,
+		MerchantID:       transaction.MerchantID,
+		Amount:           transaction.Amount,
+		Currency:         transaction.Currency,
+		OriginalAmount:   transaction.OriginalAmount,
+		OriginalCurrency: transaction.OriginalCurrency,
+		FxRate:           transaction.FxRate,
+		Status:           transaction.Status,
+		CreatedAt:        transaction.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:        transaction.UpdatedAt.Format(time.RFC3339),
+	}
+	if transaction.DebitedAt != nil {
+		response.DebitedAt = transaction.DebitedAt.Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"golang-clean-architecture/internal/entity"
+	"golang-clean-architecture/internal/model"
+	"golang-clean-architecture/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// fakeSwitch answers reversals with responseCode, or fails with err, and records them
+type fakeSwitch struct {
+	responseCode string
+	err          error
+	reversals    []model.SwitchReversalRequest
+}
+
+func (s *fakeSwitch) Inquiry(ctx context.Context, request *model.SwitchInquiryRequest) (*model.SwitchInquiryResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *fakeSwitch) Payment(ctx context.Context, request *model.SwitchPaymentRequest) (*model.SwitchPaymentResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *fakeSwitch) Reversal(ctx context.Context, request *model.SwitchReversalRequest) (*model.SwitchReversalResponse, error) {
+	s.reversals = append(s.reversals, *request)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &model.SwitchReversalResponse{ResponseCode: s.responseCode}, nil
+}
+
+// ledger records the balance credits and status updates a dry run database was asked for
+type ledger struct {
+	credits  []float64
+	statuses []string
+}
+
+// newTransactionUseCase runs on a dry run database in which transaction is the only PENDING
+// transaction, and reads back as locked when the row lock is taken
+func newTransactionUseCase(t *testing.T, transaction entity.Transaction, locked entity.Transaction, switchClient *fakeSwitch) (*TransactionUseCase, *ledger) {
+	t.Helper()
+	log := logrus.New()
+	log.SetOutput(testWriter{t})
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: &dryRunPool{}}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry run database: %v", err)
+	}
+	db.Callback().Query().Replace("gorm:query", func(db *gorm.DB) {
+		switch dest := db.Statement.Dest.(type) {
+		case *[]entity.Transaction:
+			*dest = []entity.Transaction{transaction}
+			db.RowsAffected = 1
+		case *entity.Transaction:
+			*dest = locked
+			db.RowsAffected = 1
+		}
+	})
+	recorded := new(ledger)
+	db.Callback().Update().Replace("gorm:update", func(db *gorm.DB) {
+		updates, _ := db.Statement.Dest.(map[string]interface{})
+		switch db.Statement.Table {
+		case "accounts":
+			recorded.credits = append(recorded.credits, updates["balance"].(clause.Expr).Vars[0].(float64))
+		case "transactions":
+			recorded.statuses = append(recorded.statuses, updates["status"].(string))
+		}
+		db.RowsAffected = 1
+	})
+
+	u := NewTransactionUseCase(
+		db,
+		log,
+		validator.New(),
+		repository.NewTransactionRepository(log),
+		repository.NewAccountRepository(log),
+		nil,
+		NewAuditUseCase(db, log, nil, repository.NewAuditEventRepository(log), 1),
+	)
+	// A nil *fakeSwitch would still be a non-nil switching.Client
+	if switchClient != nil {
+		u.Switch = switchClient
+	}
+	return u, recorded
+}
+
+func TestExpirePending(t *testing.T) {
+	debitedAt := time.Now().Add(-time.Hour)
+	onUs := entity.Transaction{TransactionID: "txn_1", AccountID: "user_123", Amount: 25000, Status: entity.TransactionStatusPending}
+	debited := onUs
+	debited.DebitedAt = &debitedAt
+	// 12.34 MYR debited as 43810.09 IDR: the acquirer is reversed in MYR, the account refunded in IDR
+	originalAmount := 12.34
+	offUs := debited
+	offUs.AcquirerID = "ACQ_001"
+	offUs.Amount = 43810.09
+	offUs.OriginalAmount = &originalAmount
+	resolved := debited
+	resolved.Status = entity.TransactionStatusSuccess
+
+	tests := []struct {
+		name      string
+		pending   entity.Transaction
+		locked    *entity.Transaction
+		switching *fakeSwitch
+		want      model.ExpirePendingResult
+		credits   []float64
+		statuses  []string
+		reversals []float64
+	}{
+		{
+			name:     "never debited",
+			pending:  onUs,
+			want:     model.ExpirePendingResult{Scanned: 1, Expired: 1},
+			statuses: []string{entity.TransactionStatusExpired},
+		},
+		{
+			name:     "debited",
+			pending:  debited,
+			want:     model.ExpirePendingResult{Scanned: 1, Failed: 1},
+			credits:  []float64{25000},
+			statuses: []string{entity.TransactionStatusFailed},
+		},
+		{
+			name:    "resolved meanwhile",
+			pending: debited,
+			locked:  &resolved,
+			want:    model.ExpirePendingResult{Scanned: 1},
+		},
+		{
+			name:      "off-us reversed",
+			pending:   offUs,
+			switching: &fakeSwitch{responseCode: model.SwitchResponseApproved},
+			want:      model.ExpirePendingResult{Scanned: 1, Failed: 1},
+			credits:   []float64{43810.09},
+			statuses:  []string{entity.TransactionStatusFailed},
+			reversals: []float64{12.34},
+		},
+		{
+			name:      "off-us reversal declined",
+			pending:   offUs,
+			switching: &fakeSwitch{responseCode: "05"},
+			want:      model.ExpirePendingResult{Scanned: 1},
+			reversals: []float64{12.34},
+		},
+		{
+			name:      "off-us reversal failed",
+			pending:   offUs,
+			switching: &fakeSwitch{err: errors.New("switch timeout")},
+			want:      model.ExpirePendingResult{Scanned: 1},
+			reversals: []float64{12.34},
+		},
+		{
+			name:    "off-us with switching disabled",
+			pending: offUs,
+			want:    model.ExpirePendingResult{Scanned: 1},
+		},
+		{
+			name:      "off-us never debited",
+			pending:   entity.Transaction{TransactionID: "txn_1", AccountID: "user_123", AcquirerID: "ACQ_001", Amount: 25000, Status: entity.TransactionStatusPending},
+			switching: &fakeSwitch{responseCode: model.SwitchResponseApproved},
+			want:      model.ExpirePendingResult{Scanned: 1, Expired: 1},
+			statuses:  []string{entity.TransactionStatusExpired},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locked := tt.pending
+			if tt.locked != nil {
+				locked = *tt.locked
+			}
+			u, recorded := newTransactionUseCase(t, tt.pending, locked, tt.switching)
+
+			result, err := u.ExpirePending(context.Background(), 15*time.Minute, 100)
+			if err != nil {
+				t.Fatalf("ExpirePending: %v", err)
+			}
+			if *result != tt.want {
+				t.Fatalf("result %+v, want %+v", *result, tt.want)
+			}
+			if !slices.Equal(recorded.credits, tt.credits) {
+				t.Fatalf("credited %v, want %v", recorded.credits, tt.credits)
+			}
+			if !slices.Equal(recorded.statuses, tt.statuses) {
+				t.Fatalf("set status %v, want %v", recorded.statuses, tt.statuses)
+			}
+
+			var reversals []float64
+			if tt.switching != nil {
+				for _, reversal := range tt.switching.reversals {
+					if reversal.Reference != tt.pending.TransactionID || reversal.AcquirerID != tt.pending.AcquirerID {
+						t.Fatalf("reversed %+v, want %s at %s", reversal, tt.pending.TransactionID, tt.pending.AcquirerID)
+					}
+					reversals = append(reversals, reversal.Amount)
+				}
+			}
+			if !slices.Equal(reversals, tt.reversals) {
+				t.Fatalf("reversed %v, want %v", reversals, tt.reversals)
+			}
+		})
+	}
+}
+
+func TestForceTransition(t *testing.T) {
+	const transactionID = "0b8f6a52-5c1e-4d8e-9a57-2f4e7c1d3b90"
+	debitedAt := time.Now().Add(-time.Hour)
+	pending := entity.Transaction{TransactionID: transactionID, AccountID: "user_123", Amount: 25000, Status: entity.TransactionStatusPending}
+	debited := pending
+	debited.DebitedAt = &debitedAt
+	approved := debited
+	approved.Status = entity.TransactionStatusSwitchApproved
+	succeeded := debited
+	succeeded.Status = entity.TransactionStatusSuccess
+
+	tests := []struct {
+		name     string
+		locked   entity.Transaction
+		status   string
+		err      model.ErrorCode
+		credits  []float64
+		statuses []string
+	}{
+		{name: "debited to success", locked: debited, status: entity.TransactionStatusSuccess, statuses: []string{entity.TransactionStatusSuccess}},
+		{name: "debited to failed", locked: debited, status: entity.TransactionStatusFailed, credits: []float64{25000}, statuses: []string{entity.TransactionStatusFailed}},
+		{name: "never debited to expired", locked: pending, status: entity.TransactionStatusExpired, statuses: []string{entity.TransactionStatusExpired}},
+		{name: "never debited to success", locked: pending, status: entity.TransactionStatusSuccess, err: model.ErrCodeInvalidTransition},
+		{name: "switch approved to success", locked: approved, status: entity.TransactionStatusSuccess, statuses: []string{entity.TransactionStatusSuccess}},
+		{name: "switch approved to failed", locked: approved, status: entity.TransactionStatusFailed, err: model.ErrCodeInvalidTransition},
+		{name: "already final", locked: succeeded, status: entity.TransactionStatusFailed, err: model.ErrCodeTransactionNotPending},
+		{name: "unknown status", locked: debited, status: entity.TransactionStatusPending, err: model.ErrCodeValidationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, recorded := newTransactionUseCase(t, tt.locked, tt.locked, nil)
+
+			response, err := u.ForceTransition(context.Background(), &model.ForceTransitionRequest{TransactionID: transactionID, Status: tt.status})
+			if code := errorCode(err); code != tt.err {
+				t.Fatalf("ForceTransition error = %v, want %q", err, tt.err)
+			}
+			if err == nil && response.Status != tt.status {
+				t.Fatalf("response status %s, want %s", response.Status, tt.status)
+			}
+			if !slices.Equal(recorded.credits, tt.credits) {
+				t.Fatalf("credited %v, want %v", recorded.credits, tt.credits)
+			}
+			if !slices.Equal(recorded.statuses, tt.statuses) {
+				t.Fatalf("set status %v, want %v", recorded.statuses, tt.statuses)
+			}
+		})
+	}
+}
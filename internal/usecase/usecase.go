@@ -0,0 +1,7 @@
+package usecase
+
+import "go.opentelemetry.io/otel"
+
+// tracer starts a span for every use case method so request latency can be broken down
+// alongside the HTTP, GORM and Redis spans
+var tracer = otel.Tracer("golang-clean-architecture/internal/usecase")
@@ -0,0 +1,86 @@
+import http from 'k6/http';
+import { check } from 'k6';
+import { Rate, Trend } from 'k6/metrics';
+import crypto from 'k6/crypto';
+
+// Compares payment.lock.strategy settings under parallel load on a single account.
+// STRATEGY only tags the results: the server reads payment.lock.strategy at startup, so
+// restart it with each strategy before its run, then compare conflict_rate and payment_duration:
+//   QRIS_PAYMENT_LOCK_STRATEGY=optimistic go run ./cmd/web   # then
+//   k6 run -e STRATEGY=optimistic k6/contention_test.js
+//   QRIS_PAYMENT_LOCK_STRATEGY=pessimistic go run ./cmd/web  # then
+//   k6 run -e STRATEGY=pessimistic k6/contention_test.js
+// BenchmarkDeductBalance in internal/usecase compares the strategies without the API in between.
+
+// Custom metrics
+const conflictRate = new Rate('conflict_rate');
+const errorRate = new Rate('error_rate');
+const paymentDuration = new Trend('payment_duration', true);
+
+// Configuration
+const BASE_URL = __ENV.BASE_URL || 'http://localhost:3000';
+const CLIENT_KEY = __ENV.CLIENT_KEY || 'MK-9921-X';
+const CLIENT_SECRET = __ENV.CLIENT_SECRET || 'super-secret-key-123';
+const STRATEGY = __ENV.STRATEGY || 'optimistic';
+const VUS = parseInt(__ENV.VUS || '100', 10);
+const QRIS_PAYLOAD = '00020101021126690021ID.CO.BANKMANDIRI.WWW01189360000801299399930211712993999340303UKE51440014ID.CO.QRIS.WWW0215ID10232756067300303UKE5204274153033605802ID5910MIvanStore6012JakartaTimur63046D97';
+
+// Every VU pays from the same account at the same time to maximize row contention
+export const options = {
+  scenarios: {
+    contention: {
+      executor: 'constant-vus',
+      vus: VUS,
+      duration: '1m',
+      tags: { strategy: STRATEGY },
+    },
+  },
+  thresholds: {
+    conflict_rate: ['rate<0.05'],                // Conflicts should be absorbed by retries/row locks
+    error_rate: ['rate<0.01'],                   // Any other failure is a bug
+  },
+};
+
+function buildHeaders(method, path, body) {
+  const timestamp = new Date().toISOString();
+  const signature = crypto.hmac('sha256', CLIENT_SECRET, method + path + timestamp + (body || ''), 'hex');
+  return {
+    'Content-Type': 'application/json',
+    'X-Client-Key': CLIENT_KEY,
+    'X-Timestamp': timestamp,
+    'X-Signature': signature,
+  };
+}
+
+export default function () {
+  const inquiryPath = `/api/qris/inquiry/${QRIS_PAYLOAD}`;
+  const inquiryRes = http.get(`${BASE_URL}${inquiryPath}`, {
+    headers: buildHeaders('GET', inquiryPath, ''),
+  });
+  if (inquiryRes.status !== 200) {
+    errorRate.add(true);
+    return;
+  }
+
+  const paymentPath = '/api/qris/payment';
+  const paymentBody = JSON.stringify({
+    inquiry_id: JSON.parse(inquiryRes.body).data.inquiry_id,
+    user_id: 'user_123',
+    amount: 1,
+    payment_method: 'balance',
+    pincode: '123456',
+  });
+
+  const paymentStart = Date.now();
+  const paymentRes = http.post(`${BASE_URL}${paymentPath}`, paymentBody, {
+    headers: buildHeaders('POST', paymentPath, paymentBody),
+  });
+  paymentDuration.add(Date.now() - paymentStart);
+
+  check(paymentRes, {
+    'payment status is 200': (r) => r.status === 200,
+  });
+
+  conflictRate.add(paymentRes.status === 409);
+  errorRate.add(paymentRes.status !== 200 && paymentRes.status !== 409);
+}
@@ -13,7 +13,7 @@ const statusDuration = new Trend('status_duration', true);
 const BASE_URL = __ENV.BASE_URL || 'http://localhost:3000';
 const CLIENT_KEY = __ENV.CLIENT_KEY || 'MK-9921-X';
 const CLIENT_SECRET = __ENV.CLIENT_SECRET || 'super-secret-key-123';
-const QRIS_PAYLOAD = '00020101021126690021ID.CO.BANKMANDIRI.WWW01189360000801299399930211712993999340303UKE51440014ID.CO.QRIS.WWW0215ID10232756067300303UKE5204274153033605802ID5912MIvanStore6015JakartaTimur';
+const QRIS_PAYLOAD = '00020101021126690021ID.CO.BANKMANDIRI.WWW01189360000801299399930211712993999340303UKE51440014ID.CO.QRIS.WWW0215ID10232756067300303UKE5204274153033605802ID5910MIvanStore6012JakartaTimur63046D97';
 
 // Test options: target 1000 RPS
 export const options = {